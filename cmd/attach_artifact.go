@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type attachArtifactOpts struct {
+	reference string
+	remoteOpts
+	ociLayout    bool
+	artifactType string
+	files        []string
+	mediaType    string
+	annotations  []string
+	attachFile   string
+}
+
+func attachArtifactCmd() *cobra.Command {
+	var opts attachArtifactOpts
+	var attachArtifactCmd = &cobra.Command{
+		Use:   "attach-artifact <reference>",
+		Short: "Attach artifacts to an SBOM already pushed to a registry",
+		Long: `Attach artifacts, such as scan results or VEX documents, to an SBOM already pushed to a registry without pushing it again.
+The SBOM manifest is resolved in the registry and the artifacts are pushed as referrers with it as the subject.
+
+Example - Attach a VEX document to an SBOM
+	obom attach-artifact localhost:5000/spdx:latest --type application/vnd.openvex+json --file vex.json
+
+Example - Attach a scan report of several files with annotations to an SBOM
+	obom attach-artifact localhost:5000/spdx:latest --type application/vnd.example.scan-report --file report.json --file report.html --annotation org.example.scanner=example-scanner
+
+Example - Attach the artifacts of an attachment file to an SBOM
+	obom attach-artifact localhost:5000/spdx:latest --attach-file attachments.yaml
+
+Example - Attach a VEX document to an SBOM of an OCI layout directory
+	obom attach-artifact --oci-layout ./layout:latest --type application/vnd.openvex+json --file vex.json
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.reference = args[0]
+			if !opts.ociLayout {
+				opts.reference = expandReference(opts.reference)
+			}
+
+			attachments, err := opts.getAttachments()
+			if err != nil {
+				fmt.Println("Error getting attachments:", err)
+				os.Exit(1)
+			}
+
+			target, ref, err := getTarget(opts.reference, opts.ociLayout, &opts.remoteOpts)
+			if err != nil {
+				fmt.Println("Error getting target:", err)
+				os.Exit(1)
+			}
+			if ref == "" {
+				ref = "latest"
+			}
+
+			subject, referrers, err := obom.AttachToSBOM(context.Background(), target, ref, attachments)
+			if err != nil {
+				fmt.Println("Error attaching artifacts:", err)
+				os.Exit(1)
+			}
+			for i, referrer := range referrers {
+				fmt.Printf("Attached %s to %s: %s\n", attachments[i].ArtifactType, subject.Digest, referrer.Digest)
+			}
+		},
+	}
+
+	opts.remoteOpts.applyFlags(attachArtifactCmd.Flags())
+	attachArtifactCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the reference as an OCI layout directory in the format of path[:tag|@digest]")
+	attachArtifactCmd.Flags().StringVar(&opts.artifactType, "type", "", "artifactType of the attached artifact")
+	attachArtifactCmd.Flags().StringArrayVar(&opts.files, "file", nil, "Path of a file of the attached artifact, can be repeated to attach several files as the layers of one artifact")
+	attachArtifactCmd.Flags().StringVar(&opts.mediaType, "media-type", "", "Media type of the layers of the files, defaults to the artifactType")
+	attachArtifactCmd.Flags().StringArrayVarP(&opts.annotations, "annotation", "a", nil, "Manifest annotations of the attached artifact")
+	attachArtifactCmd.Flags().StringVar(&opts.attachFile, "attach-file", "", "Path to a JSON or YAML file of artifacts to attach to the SBOM, with their artifactType, annotations and layers")
+
+	return attachArtifactCmd
+}
+
+// getAttachments returns the artifact of the flags followed by the artifacts of the attachment file
+func (opts *attachArtifactOpts) getAttachments() ([]obom.Attachment, error) {
+	var attachments []obom.Attachment
+	if opts.artifactType != "" || len(opts.files) > 0 {
+		if opts.artifactType == "" || len(opts.files) == 0 {
+			return nil, fmt.Errorf("--type and --file must be used together")
+		}
+		annotations, err := parseAnnotationFlags(opts.annotations)
+		if err != nil {
+			return nil, err
+		}
+		attachment := obom.Attachment{ArtifactType: opts.artifactType, Annotations: annotations}
+		for _, file := range opts.files {
+			attachment.Layers = append(attachment.Layers, obom.AttachmentLayer{Path: file, MediaType: opts.mediaType})
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if opts.attachFile != "" {
+		fileAttachments, err := obom.LoadAttachmentFile(opts.attachFile)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, fileAttachments...)
+	}
+
+	if len(attachments) == 0 {
+		return nil, fmt.Errorf("no artifacts to attach, set --type and --file or --attach-file")
+	}
+	return attachments, nil
+}
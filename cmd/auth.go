@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/pflag"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+var (
+	errPasswordStdinConflict = errors.New("`--password` and `--password-stdin` cannot be used together")
+	errTokenConflict         = errors.New("`--identity-token` and `--registry-token` cannot be used together")
+)
+
+// the environment variables of the registry credentials, used when the --username and --password flags are not set
+const (
+	envUsername = "OBOM_USERNAME"
+	envPassword = "OBOM_PASSWORD"
+)
+
+// registryConfig is the path of the registry credentials config file set by the --registry-config flag
+var registryConfig string
+
+// credentialOpts are the flags for the credentials of a registry
+type credentialOpts struct {
+	flagPrefix    string
+	username      string
+	password      string
+	passwordStdin bool
+	identityToken string
+	registryToken string
+}
+
+// applyFlags adds the credential flags to the flag set
+func (opts *credentialOpts) applyFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&opts.username, "username", "u", "", "Username for the registry, defaults to the OBOM_USERNAME environment variable")
+	fs.StringVarP(&opts.password, "password", "p", "", "Password for the registry, defaults to the OBOM_PASSWORD environment variable")
+	fs.BoolVar(&opts.passwordStdin, "password-stdin", false, "Read the password for the registry from stdin")
+	fs.StringVar(&opts.identityToken, "identity-token", "", "Identity token (refresh token) for the registry")
+	fs.StringVar(&opts.registryToken, "registry-token", "", "Registry token (access token) for the registry")
+}
+
+// applyFlagsWithPrefix adds the credential flags to the flag set with the prefix, such as --from-username,
+// for commands working with two registries. The environment variables are not used for prefixed flags.
+func (opts *credentialOpts) applyFlagsWithPrefix(fs *pflag.FlagSet, prefix string, description string) {
+	opts.flagPrefix = prefix
+	fs.StringVar(&opts.username, prefix+"-username", "", "Username for the "+description)
+	fs.StringVar(&opts.password, prefix+"-password", "", "Password for the "+description)
+	fs.BoolVar(&opts.passwordStdin, prefix+"-password-stdin", false, "Read the password for the "+description+" from stdin")
+	fs.StringVar(&opts.identityToken, prefix+"-identity-token", "", "Identity token (refresh token) for the "+description)
+	fs.StringVar(&opts.registryToken, prefix+"-registry-token", "", "Registry token (access token) for the "+description)
+}
+
+// credential returns the credential from the flags, the password read from stdin or the OBOM_USERNAME and
+// OBOM_PASSWORD environment variables. The password is read from stdin only once.
+func (opts *credentialOpts) credential() (auth.Credential, error) {
+	if opts.passwordStdin {
+		if opts.password != "" {
+			return auth.EmptyCredential, errPasswordStdinConflict
+		}
+		password, err := readLine(os.Stdin)
+		if err != nil {
+			return auth.EmptyCredential, fmt.Errorf("error reading password from stdin: %w", err)
+		}
+		opts.password = password
+		opts.passwordStdin = false
+	}
+	if opts.identityToken != "" && opts.registryToken != "" {
+		return auth.EmptyCredential, errTokenConflict
+	}
+
+	// the variables are read directly rather than through viper so that the USERNAME and PASSWORD variables of the
+	// shell are never used, whatever the environment settings of the config
+	username, password := opts.username, opts.password
+	if opts.flagPrefix == "" {
+		if username == "" {
+			username = os.Getenv(envUsername)
+		}
+		if password == "" {
+			password = os.Getenv(envPassword)
+		}
+	}
+
+	return auth.Credential{
+		Username:     username,
+		Password:     password,
+		RefreshToken: opts.identityToken,
+		AccessToken:  opts.registryToken,
+	}, nil
+}
+
+// credentialsResolver returns the credentials resolver for the registry. The credential from the flags or
+// the environment is used when set, then the credential of the registry in the config file, otherwise the
+// credentials are looked up in the registry credentials config.
+func (opts *credentialOpts) credentialsResolver(registry string) (obom.CredentialsResolver, error) {
+	cred, err := opts.credential()
+	if err != nil {
+		return nil, err
+	}
+	if isCredentialSet(cred) {
+		return auth.StaticCredential(registry, cred), nil
+	}
+
+	entry, err := getRegistryConfig(registry)
+	if err != nil {
+		return nil, err
+	}
+	cred = auth.Credential{
+		Username:     entry.Username,
+		Password:     entry.Password,
+		RefreshToken: entry.IdentityToken,
+		AccessToken:  entry.RegistryToken,
+	}
+	if isCredentialSet(cred) {
+		return auth.StaticCredential(registry, cred), nil
+	}
+
+	store, err := getCredentialsStore(false)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.Credential(store), nil
+}
+
+// isCredentialSet reports whether the credential has a token or both a username and a password
+func isCredentialSet(cred auth.Credential) bool {
+	return cred.AccessToken != "" || cred.RefreshToken != "" || (cred.Username != "" && cred.Password != "")
+}
+
+// getCredentialsStore returns the credentials store of the --registry-config file, or of the Docker config
+// when it is not set. Saving plaintext credentials is allowed when no native store is available.
+func getCredentialsStore(allowPlaintextPut bool) (credentials.Store, error) {
+	storeOpts := credentials.StoreOptions{
+		AllowPlaintextPut:        allowPlaintextPut,
+		DetectDefaultNativeStore: allowPlaintextPut,
+	}
+	if registryConfig != "" {
+		return credentials.NewStore(registryConfig, storeOpts)
+	}
+	return credentials.NewStoreFromDocker(storeOpts)
+}
+
+// readLine reads a single line from the reader without the trailing newline
+func readLine(reader io.Reader) (string, error) {
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
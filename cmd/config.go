@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The keys of the config file, which can also be set with OBOM_ prefixed environment variables such as OBOM_STRICT.
+// A profile selected with --profile or the profile key overrides the keys with the values of profiles.<name>:
+//
+//	registry: registry.example.com
+//	repository-template: sboms/{{ .Name }}:{{ .File }}
+//	strict: true
+//	format: text
+//	catalog: ~/.cache/obom/catalog.db
+//	policy-files:
+//	  annotation-file: /etc/obom/annotations.yaml
+//	  suppliers: /etc/obom/suppliers.yaml
+//	annotations:
+//	  org.example.team: platform
+//	registries:
+//	  registry.example.com:
+//	    username: user
+//	    ca-file: /etc/ssl/internal-ca.pem
+//	profiles:
+//	  local:
+//	    registry: localhost:5000
+const (
+	configKeyProfile            = "profile"
+	configKeyProfiles           = "profiles"
+	configKeyRegistry           = "registry"
+	configKeyRepositoryTemplate = "repository-template"
+	configKeyRegistries         = "registries"
+	configKeyAnnotations        = "annotations"
+	configKeyStrict             = "strict"
+	configKeyFormat             = "format"
+	configKeyCatalog            = "catalog"
+	configKeyPolicyFiles        = "policy-files"
+)
+
+// policyFileFlags are the flags of the policy files that can be set in the policy-files section of the config
+var policyFileFlags = []string{"annotation-file", "attach-file", "suppliers"}
+
+var invalidRepositoryChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// registryConfigEntry are the settings of a registry in the registries section of the config file
+type registryConfigEntry struct {
+	registrySettings `mapstructure:",squash"`
+	Username         string `mapstructure:"username"`
+	Password         string `mapstructure:"password"`
+	IdentityToken    string `mapstructure:"identity-token"`
+	RegistryToken    string `mapstructure:"registry-token"`
+}
+
+// repositoryTemplateData are the fields available to the repository template
+type repositoryTemplateData struct {
+	// Name is the SPDX document name, lower cased with the characters invalid in a repository replaced by dashes
+	Name string
+	// File is the SBOM file name without its extensions
+	File string
+}
+
+// applyProfile overrides the config with the profile selected by --profile, OBOM_PROFILE or the profile key
+func applyProfile() error {
+	name := viper.GetString(configKeyProfile)
+	if name == "" {
+		return nil
+	}
+	profiles := viper.GetStringMap(configKeyProfiles)
+	profile, ok := profiles[strings.ToLower(name)].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("profile %q is not defined in the config file", name)
+	}
+	return viper.MergeConfigMap(profile)
+}
+
+// applyConfigDefaults sets the flags of the command that were not set on the command line from the config file and
+// the environment, so that flags take precedence over environment variables, which take precedence over the config
+func applyConfigDefaults(cmd *cobra.Command) error {
+	defaults := make(map[string]string)
+	if viper.IsSet(configKeyStrict) {
+		strict := viper.GetBool(configKeyStrict)
+		defaults["strict"] = strconv.FormatBool(strict)
+		defaults["disable-strict"] = strconv.FormatBool(!strict)
+	}
+	if viper.IsSet(configKeyFormat) {
+		defaults["format"] = viper.GetString(configKeyFormat)
+	}
+	for name, path := range viper.GetStringMapString(configKeyPolicyFiles) {
+		if !slices.Contains(policyFileFlags, name) {
+			return fmt.Errorf("unknown policy file %q in the config, expected one of %s", name, strings.Join(policyFileFlags, ", "))
+		}
+		defaults[name] = path
+	}
+
+	for name, value := range defaults {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid config value %q for %s: %w", value, name, err)
+		}
+	}
+	return nil
+}
+
+// getConfigAnnotations returns the default manifest annotations of the config
+func getConfigAnnotations() map[string]string {
+	return viper.GetStringMapString(configKeyAnnotations)
+}
+
+// getRegistryConfig returns the settings of the registry in the config
+func getRegistryConfig(registryName string) (registryConfigEntry, error) {
+	var entry registryConfigEntry
+	configured := make(map[string]registryConfigEntry)
+	if err := viper.UnmarshalKey(configKeyRegistries, &configured); err != nil {
+		return entry, fmt.Errorf("error reading the registries config: %w", err)
+	}
+	// registry host names are case insensitive, and viper lower cases the keys
+	if e, ok := configured[strings.ToLower(registryName)]; ok {
+		entry = e
+	}
+	return entry, nil
+}
+
+// isRegistryConfigSet reports whether the config of the registry sets the key, to tell a false value from a missing one
+func isRegistryConfigSet(registryName string, key string) bool {
+	entry, ok := viper.GetStringMap(configKeyRegistries)[strings.ToLower(registryName)].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = entry[key]
+	return ok
+}
+
+// expandReference prefixes the reference with the default registry of the config when it has no registry,
+// such as sboms/app:v1
+func expandReference(reference string) string {
+	defaultRegistry := viper.GetString(configKeyRegistry)
+	if defaultRegistry == "" {
+		return reference
+	}
+	first, _, found := strings.Cut(reference, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return reference
+	}
+	return strings.TrimSuffix(defaultRegistry, "/") + "/" + reference
+}
+
+// renderRepositoryTemplate renders the repository template of the config for the SBOM into a reference
+func renderRepositoryTemplate(sbom *obom.SPDXDocument, filename string) (string, error) {
+	text := viper.GetString(configKeyRepositoryTemplate)
+	if text == "" {
+		return "", fmt.Errorf("no reference given and no %s in the config", configKeyRepositoryTemplate)
+	}
+	tmpl, err := template.New(configKeyRepositoryTemplate).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s: %w", configKeyRepositoryTemplate, err)
+	}
+
+	data := repositoryTemplateData{
+		Name: strings.Trim(invalidRepositoryChars.ReplaceAllString(strings.ToLower(sbom.Document.DocumentName), "-"), "-._"),
+		File: fileBaseName(filename),
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("error rendering %s: %w", configKeyRepositoryTemplate, err)
+	}
+	return expandReference(rendered.String()), nil
+}
+
+// openCatalog opens the catalog at the path, or at the catalog path of the config, or else in the user cache directory.
+// A leading ~ of the path is expanded to the home directory of the user.
+func openCatalog(path string) (*obom.Catalog, error) {
+	if path == "" {
+		path = viper.GetString(configKeyCatalog)
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error expanding catalog path: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if path == "" {
+		defaultPath, err := obom.DefaultCatalogPath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+	return obom.OpenCatalog(path)
+}
+
+// fileBaseName returns the base name of the file without its extensions
+func fileBaseName(filename string) string {
+	name := filepath.Base(filename)
+	if idx := strings.Index(name, "."); idx > 0 {
+		name = name[:idx]
+	}
+	return name
+}
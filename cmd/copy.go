@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+type copyOpts struct {
+	source        string
+	destination   string
+	from          remoteOpts
+	to            remoteOpts
+	fromOCILayout bool
+	toOCILayout   bool
+	recursive     bool
+	artifactTypes []string
+}
+
+func copyCmd() *cobra.Command {
+	var opts copyOpts
+	var copyCmd = &cobra.Command{
+		Use:   "copy",
+		Short: "Copy an SBOM between registries",
+		Long: `Copy an SBOM artifact from one registry or OCI layout to another
+
+Example - Copy an SBOM between registries
+	obom copy localhost:5000/spdx:latest localhost:6000/spdx:latest
+
+Example - Copy an SBOM with all of its referrers (signatures, VEX documents, summaries)
+	obom copy --recursive localhost:5000/spdx:latest localhost:6000/spdx:latest
+
+Example - Copy an SBOM with only the referrers of the given artifact types
+	obom copy --recursive --artifact-type application/vnd.cncf.notary.signature localhost:5000/spdx:latest localhost:6000/spdx:latest
+
+Example - Copy an SBOM with separate credentials for the source and destination registries
+	obom copy localhost:5000/spdx:latest localhost:6000/spdx:latest --from-username user1 --from-password pass1 --to-username user2 --to-password pass2
+
+Example - Copy an SBOM with the source registry password read from stdin
+	echo $PASSWORD | obom copy localhost:5000/spdx:latest localhost:6000/spdx:latest --from-username user1 --from-password-stdin
+
+Example - Copy an SBOM from a registry into an OCI layout directory
+	obom copy --recursive --to-oci-layout localhost:5000/spdx:latest ./layout:latest
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// get the source and destination references from the arguments
+			opts.source = args[0]
+			opts.destination = args[1]
+			if !opts.fromOCILayout {
+				opts.source = expandReference(opts.source)
+			}
+			if !opts.toOCILayout {
+				opts.destination = expandReference(opts.destination)
+			}
+
+			if opts.from.passwordStdin && opts.to.passwordStdin {
+				fmt.Println("Error: --from-password-stdin and --to-password-stdin cannot be used together")
+				os.Exit(1)
+			}
+
+			src, srcRef, err := getTarget(opts.source, opts.fromOCILayout, &opts.from)
+			if err != nil {
+				fmt.Println("Error getting source:", err)
+				os.Exit(1)
+			}
+			if srcRef == "" {
+				fmt.Println("Error getting source: missing tag or digest in reference", opts.source)
+				os.Exit(1)
+			}
+
+			dst, dstRef, err := getTarget(opts.destination, opts.toOCILayout, &opts.to)
+			if err != nil {
+				fmt.Println("Error getting destination:", err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("Copying SBOM from %s to %s...\n", opts.source, opts.destination)
+			progressFunc, progressDone := newProgressFunc("Copied")
+			desc, err := obom.CopySBOMWithOptions(context.Background(), src, srcRef, dst, dstRef, obom.CopyOptions{
+				Recursive:     opts.recursive,
+				ArtifactTypes: opts.artifactTypes,
+				Progress:      progressFunc,
+			})
+			progressDone()
+			if err != nil {
+				fmt.Println("Error copying SBOM:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("SBOM copied to %s@%s\n", opts.destination, desc.Digest)
+		},
+	}
+
+	opts.from.applyFlagsWithPrefix(copyCmd.Flags(), "from", "source registry")
+	opts.to.applyFlagsWithPrefix(copyCmd.Flags(), "to", "destination registry")
+	copyCmd.Flags().BoolVar(&opts.fromOCILayout, "from-oci-layout", false, "Set the source as an OCI layout directory in the format of path[:tag|@digest]")
+	copyCmd.Flags().BoolVar(&opts.toOCILayout, "to-oci-layout", false, "Set the destination as an OCI layout directory in the format of path[:tag|@digest]")
+	copyCmd.Flags().BoolVarP(&opts.recursive, "recursive", "R", false, "Copy the SBOM along with its referrers")
+	copyCmd.Flags().StringArrayVar(&opts.artifactTypes, "artifact-type", nil, "Only copy the referrers with the given artifact type, can be repeated. Used with --recursive")
+
+	// Add positional arguments for the source and destination references
+	copyCmd.Args = cobra.ExactArgs(2)
+
+	return copyCmd
+}
+
+// getTarget returns the target and the tag or digest for the given reference,
+// which is either a remote registry reference or an OCI layout path when ociLayout is set
+func getTarget(reference string, ociLayout bool, remote *remoteOpts) (oras.GraphTarget, string, error) {
+	if ociLayout {
+		path, ref := parseOCILayoutReference(reference)
+		store, err := oci.New(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("error opening OCI layout %s: %w", path, err)
+		}
+		return store, ref, nil
+	}
+
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, "", fmt.Errorf("error parsing reference: %w", err)
+	}
+
+	repo, err := remote.getRemoteRepoTarget(reference)
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting remote repository: %w", err)
+	}
+	return repo, ref.Reference, nil
+}
+
+// parseOCILayoutReference splits an OCI layout reference in the format of path[:tag|@digest]
+// into the layout path and the tag or digest
+func parseOCILayoutReference(reference string) (string, string) {
+	if path, digest, found := strings.Cut(reference, "@"); found {
+		return path, digest
+	}
+	// only consider a colon after the last path separator so that paths like C:\layout are kept intact
+	sep := strings.LastIndexAny(reference, `/\`)
+	if idx := strings.LastIndex(reference, ":"); idx > sep {
+		return reference[:idx], reference[idx+1:]
+	}
+	return reference, ""
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	obom "github.com/Azure/obom/pkg"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+)
+
+type deleteOpts struct {
+	reference string
+	remoteOpts
+	ociLayout bool
+	cascade   bool
+	dryRun    bool
+}
+
+func deleteCmd() *cobra.Command {
+	var opts deleteOpts
+	var deleteCmd = &cobra.Command{
+		Use:   "delete <reference>",
+		Short: "Delete an SBOM from a registry",
+		Long: `Delete the SBOM manifest of the reference from a registry supporting the delete API or from an OCI layout, along with all of its tags.
+With --cascade, every referrer attached to the SBOM (summaries, attestations, signatures, ...) is deleted first.
+
+Example - Delete an SBOM
+	obom delete localhost:5000/spdx:v1
+
+Example - Delete an SBOM with all of its referrers
+	obom delete localhost:5000/spdx:v1 --cascade
+
+Example - Report the manifests that would be deleted without deleting them
+	obom delete localhost:5000/spdx:v1 --cascade --dry-run
+
+Example - Delete an SBOM from an OCI layout directory
+	obom delete --oci-layout ./layout:v1 --cascade
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.reference = args[0]
+			if !opts.ociLayout {
+				opts.reference = expandReference(opts.reference)
+			}
+
+			target, ref, err := getDeleteTarget(opts.reference, opts.ociLayout, &opts.remoteOpts)
+			if err != nil {
+				fmt.Println("Error getting target:", err)
+				os.Exit(1)
+			}
+			if ref == "" {
+				fmt.Println("Error: missing tag or digest in reference", opts.reference)
+				os.Exit(1)
+			}
+
+			deleted, err := obom.DeleteSBOM(context.Background(), target, ref, obom.DeleteOptions{
+				Cascade: opts.cascade,
+				DryRun:  opts.dryRun,
+			})
+			printDeleted(deleted, opts.dryRun)
+			if err != nil {
+				fmt.Println("Error deleting SBOM:", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	opts.remoteOpts.applyFlags(deleteCmd.Flags())
+	deleteCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the reference as an OCI layout directory in the format of path[:tag|@digest]")
+	deleteCmd.Flags().BoolVar(&opts.cascade, "cascade", false, "Delete the referrers of the SBOM along with it")
+	deleteCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Report the manifests that would be deleted without deleting them")
+
+	return deleteCmd
+}
+
+// getDeleteTarget returns the target deleting manifests and the tag or digest of the reference
+func getDeleteTarget(reference string, ociLayout bool, remote *remoteOpts) (obom.PruneTarget, string, error) {
+	target, ref, err := getTarget(reference, ociLayout, remote)
+	if err != nil {
+		return nil, "", err
+	}
+	deleteTarget, ok := target.(obom.PruneTarget)
+	if !ok {
+		return nil, "", fmt.Errorf("%s does not support deleting manifests", reference)
+	}
+	return deleteTarget, ref, nil
+}
+
+// printDeleted prints the deleted manifests, or the manifests that would be deleted in a dry run
+func printDeleted(deleted []ocispec.Descriptor, dryRun bool) {
+	action := "Deleted"
+	if dryRun {
+		action = "Would delete"
+	}
+	for _, desc := range deleted {
+		kind := desc.ArtifactType
+		if kind == "" {
+			kind = desc.MediaType
+		}
+		fmt.Printf("%s %s %s\n", action, desc.Digest, kind)
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type enrichOptions struct {
+	filename     string
+	output       string
+	supplierFile string
+	dryRun       bool
+}
+
+func enrichCmd() *cobra.Command {
+	var opts enrichOptions
+	var enrichCmd = &cobra.Command{
+		Use:   "enrich",
+		Short: "Fill the missing PURLs, CPEs, suppliers and SPDX license identifiers of the packages of the SBOM",
+		Long: `Fill the data missing from the packages of the SBOM and write the enriched SBOM:
+  - package URLs inferred from the download location of the packages (npm, PyPI, Go, Maven, RubyGems, crates.io,
+    NuGet and GitHub) or from their name when it is a Go module path
+  - CPE 2.3 names generated from the supplier, the name and the version of the packages
+  - suppliers filled from the rules of a supplier mapping file
+  - license strings normalized to SPDX license identifiers and checksums normalized to lowercase
+Every change is recorded as an SPDX annotation of the package, so that the enrichment can be audited.
+
+Example - Enrich an SBOM
+	obom enrich -f spdx.json -o spdx.enriched.json
+
+Example - Enrich an SBOM with the suppliers of a supplier mapping file
+	obom enrich -f spdx.json -o spdx.enriched.json --suppliers suppliers.yaml
+
+Example - Report the changes without writing the enriched SBOM
+	obom enrich -f spdx.json --dry-run
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.output == "" && !opts.dryRun {
+				fmt.Println("Error: --output is required unless --dry-run is set")
+				os.Exit(1)
+			}
+
+			sbom, err := obom.LoadSBOM(opts.filename, true)
+			if err != nil {
+				fmt.Println("Error loading SBOM:", err)
+				os.Exit(1)
+			}
+
+			var enrichOptions obom.EnrichOptions
+			if opts.supplierFile != "" {
+				enrichOptions.Suppliers, err = obom.LoadSupplierFile(opts.supplierFile)
+				if err != nil {
+					fmt.Println("Error loading suppliers:", err)
+					os.Exit(1)
+				}
+			}
+
+			enrichments := obom.EnrichSBOM(sbom.Document, enrichOptions)
+			for _, enrichment := range enrichments {
+				if enrichment.Previous != "" {
+					fmt.Printf("%s %s: %s -> %s (%s)\n", enrichment.SPDXID, enrichment.Field, enrichment.Previous, enrichment.Value, enrichment.Reason)
+				} else {
+					fmt.Printf("%s %s: %s (%s)\n", enrichment.SPDXID, enrichment.Field, enrichment.Value, enrichment.Reason)
+				}
+			}
+			if opts.dryRun {
+				fmt.Printf("%d changes\n", len(enrichments))
+				return
+			}
+
+			file, err := os.Create(opts.output)
+			if err != nil {
+				fmt.Println("Error creating output file:", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			if err := obom.WriteSBOM(file, sbom.Document); err != nil {
+				fmt.Println("Error writing SBOM:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Enriched SBOM written to %s with %d changes\n", opts.output, len(enrichments))
+		},
+	}
+
+	enrichCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
+	enrichCmd.MarkFlagRequired("file")
+	enrichCmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the enriched SPDX SBOM file")
+	enrichCmd.Flags().StringVar(&opts.supplierFile, "suppliers", "", "Path to a JSON or YAML supplier mapping file, with the supplier of the packages matching a PURL prefix or a name pattern")
+	enrichCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Report the changes without writing the enriched SBOM")
+
+	return enrichCmd
+}
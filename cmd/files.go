@@ -22,7 +22,7 @@ func filesCmd() *cobra.Command {
 Example:
 	obom files -f ./examples/SPDXJSONExample-v2.3.spdx.json`,
 		Run: func(cmd *cobra.Command, args []string) {
-			sbom, _, _, err := obom.LoadSBOMFromFile(opts.filename, true)
+			sbom, err := obom.LoadSBOM(opts.filename, true)
 			if err != nil {
 				fmt.Println("Error loading SBOM:", err)
 				os.Exit(1)
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/obom/internal/version"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"github.com/spf13/cobra"
+)
+
+type generateGoOpts struct {
+	modFile   string
+	sumFile   string
+	binary    string
+	output    string
+	name      string
+	namespace string
+	version   string
+}
+
+func generateCmd() *cobra.Command {
+	var generateCmd = &cobra.Command{
+		Use:   "generate",
+		Short: "Generate SBOMs",
+		Long: `Generate minimal SPDX SBOMs that can be pushed with obom push
+`,
+	}
+
+	generateCmd.AddCommand(generateGoCmd())
+
+	return generateCmd
+}
+
+func generateGoCmd() *cobra.Command {
+	var opts generateGoOpts
+	var generateGoCmd = &cobra.Command{
+		Use:   "go",
+		Short: "Generate an SBOM of a Go module or a Go binary",
+		Long: `Generate an SPDX 2.3 SBOM of a Go module from its go.mod and go.sum files, or of a Go binary from its embedded build info.
+The SBOM describes the main module, which depends on its required modules, or on the modules linked into the binary and
+the Go standard library. The packages have their Go package URL, their version and the h1: hash of go.sum or of the
+build info, a hash of the file tree of the module recorded verbatim as an OTHER go-module-hash external reference rather
+than as a checksum. Modules replaced by a local directory have no version. The SBOM is written to the standard output
+unless --output is set.
+
+Example - Generate an SBOM of the Go module of the current directory
+	obom generate go --version v1.0.0 -o spdx.json
+
+Example - Generate an SBOM of a Go module and push it
+	obom generate go --mod ./app/go.mod --version v1.0.0 -o spdx.json
+	obom push -f spdx.json localhost:5000/app-sbom:v1.0.0
+
+Example - Generate an SBOM of a Go binary
+	obom generate go --binary ./bin/app -o spdx.json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			generateOptions := obom.GenerateOptions{
+				Name:      opts.name,
+				Namespace: opts.namespace,
+				Version:   opts.version,
+				Tool:      obom.GENERATE_TOOL,
+			}
+			if version.Version != "" && version.Version != "(devel)" {
+				generateOptions.Tool += "-" + version.Version
+			}
+
+			doc, err := opts.generate(generateOptions)
+			if err != nil {
+				fmt.Println("Error generating SBOM:", err)
+				os.Exit(1)
+			}
+
+			var writer io.Writer = os.Stdout
+			if opts.output != "" {
+				file, err := os.Create(opts.output)
+				if err != nil {
+					fmt.Println("Error creating output file:", err)
+					os.Exit(1)
+				}
+				defer file.Close()
+				writer = file
+			}
+			if err := obom.WriteSBOM(writer, doc); err != nil {
+				fmt.Println("Error writing SBOM:", err)
+				os.Exit(1)
+			}
+			if opts.output != "" {
+				fmt.Printf("SBOM of %d packages written to %s\n", len(doc.Packages), opts.output)
+			}
+		},
+	}
+
+	generateGoCmd.Flags().StringVar(&opts.modFile, "mod", "go.mod", "Path to the go.mod file of the module")
+	generateGoCmd.Flags().StringVar(&opts.sumFile, "sum", "", "Path to the go.sum file of the module, defaults to the go.sum file next to the go.mod file")
+	generateGoCmd.Flags().StringVar(&opts.binary, "binary", "", "Path to a Go binary, whose build info is read instead of the go.mod file")
+	generateGoCmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the generated SPDX SBOM file, defaults to the standard output")
+	generateGoCmd.Flags().StringVar(&opts.name, "name", "", "Name of the SBOM, defaults to the path and the version of the main module")
+	generateGoCmd.Flags().StringVar(&opts.namespace, "namespace", "", "Namespace of the SBOM, defaults to a unique URI of the name")
+	generateGoCmd.Flags().StringVar(&opts.version, "version", "", "Version of the main module of the go.mod file")
+	generateGoCmd.MarkFlagsMutuallyExclusive("binary", "mod")
+	generateGoCmd.MarkFlagsMutuallyExclusive("binary", "sum")
+	generateGoCmd.MarkFlagsMutuallyExclusive("binary", "version")
+
+	return generateGoCmd
+}
+
+// generate generates the SBOM of the binary when set, otherwise of the go.mod and go.sum files
+func (opts *generateGoOpts) generate(generateOptions obom.GenerateOptions) (*v2_3.Document, error) {
+	if opts.binary != "" {
+		file, err := os.Open(opts.binary)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return obom.GenerateGoBinarySBOM(file, generateOptions)
+	}
+
+	goMod, err := os.ReadFile(opts.modFile)
+	if err != nil {
+		return nil, err
+	}
+	sumFile := opts.sumFile
+	if sumFile == "" {
+		sumFile = filepath.Join(filepath.Dir(opts.modFile), "go.sum")
+	}
+	goSum, err := os.ReadFile(sumFile)
+	if err != nil {
+		// modules without dependencies have no go.sum file
+		if opts.sumFile != "" || !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return obom.GenerateGoModSBOM(goMod, goSum, generateOptions)
+}
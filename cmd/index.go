@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+type indexOpts struct {
+	catalog string
+}
+
+type indexAddOpts struct {
+	*indexOpts
+	remoteOpts
+	allTags       bool
+	ociLayout     bool
+	disableStrict bool
+}
+
+type indexListOpts struct {
+	*indexOpts
+	format string
+}
+
+func indexCmd() *cobra.Command {
+	var opts indexOpts
+	var indexCmd = &cobra.Command{
+		Use:   "index",
+		Short: "Manage the local catalog of SBOMs searched by obom search",
+		Long: `Manage the local catalog of the packages of SBOMs, searched with obom search
+
+The catalog is stored in the user cache directory, or in the catalog path of the config file or of --catalog.
+`,
+	}
+
+	indexCmd.PersistentFlags().StringVar(&opts.catalog, "catalog", "", "Path of the catalog, defaults to the catalog of the config file or catalog.db in the obom user cache directory")
+
+	indexCmd.AddCommand(indexAddCmd(&opts), indexRemoveCmd(&opts), indexListCmd(&opts))
+
+	return indexCmd
+}
+
+func indexAddCmd(parent *indexOpts) *cobra.Command {
+	opts := indexAddOpts{indexOpts: parent}
+	var indexAddCmd = &cobra.Command{
+		Use:   "add <reference|file>...",
+		Short: "Index SBOMs into the local catalog",
+		Long: `Index the packages of SBOMs pushed to a registry or of SBOM files into the local catalog.
+Indexing a reference again replaces its entry, so that the catalog can be refreshed.
+
+Example - Index an SBOM pushed to a registry
+	obom index add localhost:5000/spdx:latest
+
+Example - Index SBOM files
+	obom index add spdx.json other.spdx.json
+
+Example - Index the SBOMs of all the tags of a repository, removing the entries of the tags that no longer exist
+	obom index add --all-tags localhost:5000/spdx
+
+Example - Index an SBOM from an OCI layout directory
+	obom index add --oci-layout ./layout:latest
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := openCatalog(opts.catalog)
+			if err != nil {
+				fmt.Println("Error opening catalog:", err)
+				os.Exit(1)
+			}
+			defer catalog.Close()
+
+			failed := false
+			for _, arg := range args {
+				if err := opts.add(context.Background(), catalog, arg); err != nil {
+					fmt.Printf("Error indexing %s: %v\n", arg, err)
+					failed = true
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	opts.remoteOpts.applyFlags(indexAddCmd.Flags())
+	indexAddCmd.Flags().BoolVar(&opts.allTags, "all-tags", false, "Index the SBOMs of all the tags of the repository and remove the entries of its tags that no longer exist")
+	indexAddCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the reference as an OCI layout directory in the format of path[:tag|@digest]")
+	indexAddCmd.Flags().BoolVarP(&opts.disableStrict, "disable-strict", "r", false, "Disable strict SPDX parsing as per the SPDX specification. When disabled, obom will fall back to a simple JSON parsing strategy")
+
+	return indexAddCmd
+}
+
+// add indexes the SBOM file, the SBOM of the reference or the SBOMs of the tags of the repository into the catalog
+func (opts *indexAddOpts) add(ctx context.Context, catalog *obom.Catalog, arg string) error {
+	strict := !opts.disableStrict
+
+	if opts.allTags {
+		return opts.crawl(ctx, catalog, arg)
+	}
+
+	// a regular file is an SBOM file, anything else a reference
+	if !opts.ociLayout {
+		if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+			entry, err := catalog.AddFile(arg, strict)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Indexed %d packages of %s\n", len(entry.Packages), entry.Reference)
+			return nil
+		}
+	}
+
+	reference := arg
+	if !opts.ociLayout {
+		reference = expandReference(reference)
+	}
+	src, ref, err := getTarget(reference, opts.ociLayout, &opts.remoteOpts)
+	if err != nil {
+		return err
+	}
+	if ref == "" {
+		ref = "latest"
+		reference += ":" + ref
+	}
+
+	entry, err := catalog.AddReference(ctx, src, ref, reference, strict)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Indexed %d packages of %s@%s\n", len(entry.Packages), entry.Reference, entry.Digest)
+	return nil
+}
+
+// crawl indexes the SBOMs of all the tags of the repository into the catalog
+func (opts *indexAddOpts) crawl(ctx context.Context, catalog *obom.Catalog, repository string) error {
+	var repo obom.CatalogRepository
+	if opts.ociLayout {
+		store, err := oci.New(repository)
+		if err != nil {
+			return fmt.Errorf("error opening OCI layout %s: %w", repository, err)
+		}
+		repo = store
+	} else {
+		ref, err := registry.ParseReference(expandReference(repository))
+		if err != nil {
+			return fmt.Errorf("error parsing repository: %w", err)
+		}
+		if ref.Reference != "" {
+			return fmt.Errorf("--all-tags expects a repository without a tag or digest, got: %s", repository)
+		}
+		repository = ref.Registry + "/" + ref.Repository
+		remoteRepo, err := opts.remoteOpts.getRemoteRepoTarget(repository)
+		if err != nil {
+			return fmt.Errorf("error getting remote repository: %w", err)
+		}
+		repo = remoteRepo
+	}
+
+	result, err := catalog.CrawlRepository(ctx, repo, repository, !opts.disableStrict)
+	if err != nil {
+		return err
+	}
+
+	for _, reference := range result.Indexed {
+		fmt.Printf("Indexed %s\n", reference)
+	}
+	for _, reference := range result.Removed {
+		fmt.Printf("Removed %s\n", reference)
+	}
+	failed := make([]string, 0, len(result.Errors))
+	for reference := range result.Errors {
+		failed = append(failed, reference)
+	}
+	sort.Strings(failed)
+	for _, reference := range failed {
+		fmt.Printf("Error indexing %s: %v\n", reference, result.Errors[reference])
+	}
+	fmt.Printf("Indexed %d SBOMs of %s, skipped %d tags that are not SBOMs, removed %d entries\n", len(result.Indexed), repository, len(result.Skipped), len(result.Removed))
+	if len(failed) > 0 {
+		return fmt.Errorf("%d tags could not be indexed", len(failed))
+	}
+	return nil
+}
+
+func indexRemoveCmd(opts *indexOpts) *cobra.Command {
+	var indexRemoveCmd = &cobra.Command{
+		Use:   "remove <reference|file>...",
+		Short: "Remove SBOMs from the local catalog",
+		Long: `Remove SBOMs from the local catalog by the reference or the absolute file path listed by obom index list
+
+Example - Remove an SBOM from the catalog
+	obom index remove localhost:5000/spdx:latest
+`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := openCatalog(opts.catalog)
+			if err != nil {
+				fmt.Println("Error opening catalog:", err)
+				os.Exit(1)
+			}
+			defer catalog.Close()
+
+			for _, reference := range args {
+				if err := catalog.Remove(reference); err != nil {
+					fmt.Println("Error removing from catalog:", err)
+					os.Exit(1)
+				}
+				fmt.Printf("Removed %s\n", reference)
+			}
+		},
+	}
+
+	return indexRemoveCmd
+}
+
+func indexListCmd(parent *indexOpts) *cobra.Command {
+	opts := indexListOpts{indexOpts: parent}
+	var indexListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the SBOMs of the local catalog",
+		Long: `List the SBOMs of the local catalog with their digest and number of packages
+
+Example - List the SBOMs of the catalog
+	obom index list
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := openCatalog(opts.catalog)
+			if err != nil {
+				fmt.Println("Error opening catalog:", err)
+				os.Exit(1)
+			}
+			defer catalog.Close()
+
+			entries, err := catalog.List()
+			if err != nil {
+				fmt.Println("Error listing catalog:", err)
+				os.Exit(1)
+			}
+
+			switch opts.format {
+			case "text":
+				print.PrintCatalogEntries(entries)
+			case "json":
+				if err := print.PrintJSON(entries); err != nil {
+					fmt.Println("Error printing catalog:", err)
+					os.Exit(1)
+				}
+			default:
+				fmt.Printf("Error: unknown format %q, expected text or json\n", opts.format)
+				os.Exit(1)
+			}
+		},
+	}
+
+	indexListCmd.Flags().StringVar(&opts.format, "format", "text", "Output format, text or json")
+
+	return indexListCmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// logOpts are the global flags of the log level
+type logOpts struct {
+	verbose bool
+	debug   bool
+}
+
+var logging logOpts
+
+func (opts *logOpts) applyFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&opts.verbose, "verbose", false, "Log the progress of the operations to stderr")
+	fs.BoolVar(&opts.debug, "debug", false, "Log debug information to stderr, including the HTTP requests to the registries")
+}
+
+// setupLogging sets the default logger to log to stderr at the level of the flags, warnings only by default
+func (opts *logOpts) setupLogging() {
+	level := slog.LevelWarn
+	if opts.verbose {
+		level = slog.LevelInfo
+	}
+	if opts.debug {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
+
+// traceTransport logs the HTTP requests and responses at the debug level, without the credentials
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+// newTraceTransport wraps the transport to log the requests when --debug is set
+func newTraceTransport(base http.RoundTripper) http.RoundTripper {
+	if !logging.debug {
+		return base
+	}
+	return &traceTransport{base: base}
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	slog.Debug("http request", "method", req.Method, "url", req.URL.Redacted(), "header", redactHeader(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		slog.Debug("http request failed", "method", req.Method, "url", req.URL.Redacted(), "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+
+	slog.Debug("http response", "method", req.Method, "url", req.URL.Redacted(), "status", resp.Status, "duration", time.Since(start), "header", redactHeader(resp.Header))
+	return resp, nil
+}
+
+// redactHeader returns a copy of the header with the values of the credential headers redacted
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range []string{"Authorization", "Cookie", "Set-Cookie"} {
+		if redacted.Get(key) != "" {
+			redacted.Set(key, "*****")
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+type loginOpts struct {
+	registry string
+	remoteOpts
+}
+
+func loginCmd() *cobra.Command {
+	var opts loginOpts
+	var loginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Log in to a registry",
+		Long: `Log in to a registry and save the credentials in the registry config, which defaults to the Docker config.
+The saved credentials are used by the other commands when no credentials are given.
+
+Example - Log in with an interactive prompt for the username and password
+	obom login localhost:5000
+
+Example - Log in with the password read from stdin
+	echo $PASSWORD | obom login localhost:5000 --username user --password-stdin
+
+Example - Log in with an identity token
+	obom login localhost:5000 --identity-token $TOKEN
+
+Example - Log in and save the credentials in a custom registry config
+	obom login localhost:5000 --username user --password-stdin --registry-config ./config.json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// get the registry as the first argument
+			opts.registry = args[0]
+
+			if opts.registryToken != "" {
+				fmt.Println("Error logging in: registry tokens are short-lived and cannot be saved, use --identity-token instead")
+				os.Exit(1)
+			}
+
+			cred, err := opts.credential()
+			if err != nil {
+				fmt.Println("Error getting credentials:", err)
+				os.Exit(1)
+			}
+			if cred.RefreshToken == "" {
+				cred, err = promptCredential(cred)
+				if err != nil {
+					fmt.Println("Error getting credentials:", err)
+					os.Exit(1)
+				}
+			}
+
+			store, err := getCredentialsStore(true)
+			if err != nil {
+				fmt.Println("Error getting credentials store:", err)
+				os.Exit(1)
+			}
+
+			reg, err := opts.remoteOpts.getRemoteRegistry(opts.registry)
+			if err != nil {
+				fmt.Println("Error getting registry:", err)
+				os.Exit(1)
+			}
+
+			if err := credentials.Login(context.Background(), store, reg, cred); err != nil {
+				fmt.Println("Error logging in:", err)
+				os.Exit(1)
+			}
+			fmt.Println("Login Succeeded")
+		},
+	}
+
+	opts.remoteOpts.applyFlags(loginCmd.Flags())
+
+	// Add positional argument called registry to loginCmd
+	loginCmd.Args = cobra.ExactArgs(1)
+
+	return loginCmd
+}
+
+// promptCredential prompts for the username and password missing from the credential when stdin is a terminal
+func promptCredential(cred auth.Credential) (auth.Credential, error) {
+	if cred.Username != "" && cred.Password != "" {
+		return cred, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return cred, fmt.Errorf("missing username or password, use --username with --password-stdin when not running in a terminal")
+	}
+
+	if cred.Username == "" {
+		fmt.Print("Username: ")
+		username, err := readLine(os.Stdin)
+		if err != nil {
+			return cred, fmt.Errorf("error reading username: %w", err)
+		}
+		cred.Username = strings.TrimSpace(username)
+	}
+	if cred.Password == "" {
+		fmt.Print("Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return cred, fmt.Errorf("error reading password: %w", err)
+		}
+		cred.Password = string(password)
+	}
+	if cred.Username == "" || cred.Password == "" {
+		return cred, fmt.Errorf("username and password are required")
+	}
+	return cred, nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+func logoutCmd() *cobra.Command {
+	var logoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Log out from a registry",
+		Long: `Log out from a registry by removing its credentials from the registry config, which defaults to the Docker config
+
+Example - Log out from a registry
+	obom logout localhost:5000
+
+Example - Log out from a registry with the credentials saved in a custom registry config
+	obom logout localhost:5000 --registry-config ./config.json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			store, err := getCredentialsStore(false)
+			if err != nil {
+				fmt.Println("Error getting credentials store:", err)
+				os.Exit(1)
+			}
+
+			if err := credentials.Logout(context.Background(), store, args[0]); err != nil {
+				fmt.Println("Error logging out:", err)
+				os.Exit(1)
+			}
+			fmt.Println("Removing login credentials for", args[0])
+		},
+	}
+
+	// Add positional argument called registry to logoutCmd
+	logoutCmd.Args = cobra.ExactArgs(1)
+
+	return logoutCmd
+}
@@ -19,7 +19,7 @@ func packagesCmd() *cobra.Command {
 		Short: "List packages the SBOM",
 		Long:  `List packages the SBOM that have external refs`,
 		Run: func(cmd *cobra.Command, args []string) {
-			sbom, _, _, err := obom.LoadSBOMFromFile(opts.filename, true)
+			sbom, err := obom.LoadSBOM(opts.filename, true)
 			if err != nil {
 				fmt.Println("Error loading SBOM:", err)
 				os.Exit(1)
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/Azure/obom/internal/progress"
+	obom "github.com/Azure/obom/pkg"
+	"golang.org/x/term"
+)
+
+// newProgressFunc returns the progress function of a push or a copy, which logs the progress events and renders them
+// as a progress bar when stderr is a terminal, along with the function clearing the progress bar once done.
+// The action, such as Pushed or Copied, prefixes the lines of the copied blobs.
+func newProgressFunc(action string) (obom.ProgressFunc, func()) {
+	var bar *progress.Bar
+	if term.IsTerminal(int(os.Stderr.Fd())) && !logging.verbose && !logging.debug {
+		bar = progress.NewBar(os.Stderr, action)
+	}
+
+	progressFunc := func(event obom.ProgressEvent) {
+		logProgressEvent(event)
+		if bar != nil {
+			bar.Update(event)
+		}
+	}
+	done := func() {
+		if bar != nil {
+			bar.Close()
+		}
+	}
+	return progressFunc, done
+}
+
+// logProgressEvent logs the copied, skipped and tagged descriptors at the info level and the started copies at the
+// debug level
+func logProgressEvent(event obom.ProgressEvent) {
+	desc := event.Descriptor
+	switch event.Type {
+	case obom.PROGRESS_BLOB_STARTED:
+		slog.Debug("copying", "digest", desc.Digest, "mediaType", desc.MediaType, "size", desc.Size)
+	case obom.PROGRESS_BLOB_DONE:
+		slog.Info("copied", "digest", desc.Digest, "mediaType", desc.MediaType, "size", desc.Size)
+	case obom.PROGRESS_BLOB_SKIPPED:
+		slog.Info("exists", "digest", desc.Digest, "mediaType", desc.MediaType, "size", desc.Size)
+	case obom.PROGRESS_MANIFEST_TAGGED:
+		slog.Info("tagged", "reference", event.Reference, "digest", desc.Digest)
+	}
+}
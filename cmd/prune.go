@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type pruneOpts struct {
+	repository string
+	remoteOpts
+	ociLayout bool
+	keepLast  int
+	olderThan string
+	dryRun    bool
+}
+
+func pruneCmd() *cobra.Command {
+	var opts pruneOpts
+	var pruneCmd = &cobra.Command{
+		Use:   "prune <repository>",
+		Short: "Delete the superseded SBOMs of a repository",
+		Long: `Delete the SBOMs of the tags of a repository along with their referrers, except the most recently created ones.
+The creation time of the SBOMs is read from the org.spdx.created annotation of their manifests. Tags that are not SBOMs
+or have no creation time are never pruned.
+
+Example - Keep the 10 most recently created SBOMs of a repository
+	obom prune localhost:5000/spdx --keep-last 10
+
+Example - Delete the SBOMs created more than 90 days ago, always keeping the 5 most recent ones
+	obom prune localhost:5000/spdx --keep-last 5 --older-than 90d
+
+Example - Report the SBOMs that would be deleted without deleting them
+	obom prune localhost:5000/spdx --older-than 90d --dry-run
+
+Example - Prune the SBOMs of an OCI layout directory
+	obom prune --oci-layout ./layout --keep-last 10
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.repository = args[0]
+			if !opts.ociLayout {
+				opts.repository = expandReference(opts.repository)
+			}
+
+			pruneOptions := obom.PruneOptions{KeepLast: opts.keepLast, DryRun: opts.dryRun}
+			if opts.olderThan != "" {
+				olderThan, err := obom.ParseAge(opts.olderThan)
+				if err != nil {
+					fmt.Println("Error parsing --older-than:", err)
+					os.Exit(1)
+				}
+				pruneOptions.OlderThan = olderThan
+			}
+			if pruneOptions.KeepLast <= 0 && pruneOptions.OlderThan <= 0 {
+				fmt.Println("Error: --keep-last or --older-than is required")
+				os.Exit(1)
+			}
+
+			target, ref, err := getDeleteTarget(opts.repository, opts.ociLayout, &opts.remoteOpts)
+			if err != nil {
+				fmt.Println("Error getting repository:", err)
+				os.Exit(1)
+			}
+			if ref != "" {
+				fmt.Println("Error: prune expects a repository without a tag or digest, got:", opts.repository)
+				os.Exit(1)
+			}
+
+			result, err := obom.PruneSBOMs(context.Background(), target, pruneOptions)
+			if result != nil {
+				printPruneResult(result, opts.dryRun)
+			}
+			if err != nil {
+				fmt.Println("Error pruning SBOMs:", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	opts.remoteOpts.applyFlags(pruneCmd.Flags())
+	pruneCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the repository as an OCI layout directory")
+	pruneCmd.Flags().IntVar(&opts.keepLast, "keep-last", 0, "Number of the most recently created SBOMs to keep")
+	pruneCmd.Flags().StringVar(&opts.olderThan, "older-than", "", "Only delete the SBOMs created longer ago than this age, such as 90d, 2w or 36h")
+	pruneCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Report the SBOMs that would be deleted without deleting them")
+
+	return pruneCmd
+}
+
+// printPruneResult prints the pruned and kept SBOMs, or the SBOMs that would be pruned in a dry run
+func printPruneResult(result *obom.PruneResult, dryRun bool) {
+	action := "Pruned"
+	if dryRun {
+		action = "Would prune"
+	}
+	for _, sbom := range result.Pruned {
+		fmt.Printf("%s %s %s created %s\n", action, strings.Join(sbom.Tags, ","), sbom.Descriptor.Digest, sbom.Created.Format(time.RFC3339))
+		printDeleted(sbom.Deleted, dryRun)
+	}
+	for _, sbom := range result.Kept {
+		fmt.Printf("Kept %s %s created %s\n", strings.Join(sbom.Tags, ","), sbom.Descriptor.Digest, sbom.Created.Format(time.RFC3339))
+	}
+	for _, tag := range result.Skipped {
+		fmt.Printf("Skipped %s, not an SBOM with a creation time\n", tag)
+	}
+	fmt.Printf("%s %d SBOMs, kept %d, skipped %d tags\n", action, len(result.Pruned), len(result.Kept), len(result.Skipped))
+}
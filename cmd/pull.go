@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type pullOpts struct {
+	reference string
+	output    string
+	remoteOpts
+	ociLayout     bool
+	disableStrict bool
+}
+
+func pullCmd() *cobra.Command {
+	var opts pullOpts
+	var pullCmd = &cobra.Command{
+		Use:   "pull",
+		Short: "Pull the SPDX SBOM from the registry",
+		Long: `Pull the SPDX SBOM from an OCI registry and write the document to a file. Compressed SBOM layers are decompressed.
+
+Example - Pull an SPDX SBOM from a registry
+	obom pull localhost:5000/spdx:latest -o spdx.json
+
+Example - Pull an SPDX SBOM from an OCI layout directory
+	obom pull --oci-layout ./layout:latest -o spdx.json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// get the reference as the first argument
+			opts.reference = args[0]
+			if !opts.ociLayout {
+				opts.reference = expandReference(opts.reference)
+			}
+
+			src, ref, err := getTarget(opts.reference, opts.ociLayout, &opts.remoteOpts)
+			if err != nil {
+				fmt.Println("Error getting source:", err)
+				os.Exit(1)
+			}
+			if ref == "" {
+				ref = "latest"
+			}
+
+			result, err := obom.FetchSBOMArtifact(context.Background(), src, ref, !opts.disableStrict)
+			if err != nil {
+				fmt.Println("Error pulling SBOM:", err)
+				os.Exit(1)
+			}
+
+			print.PrintSBOMSummary(result.SPDXDocument, &result.Descriptor)
+
+			if err := os.WriteFile(opts.output, result.Bytes, 0644); err != nil {
+				fmt.Println("Error writing SBOM:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("SBOM pulled to %s\n", opts.output)
+		},
+	}
+
+	pullCmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path to write the SPDX SBOM file to")
+	pullCmd.MarkFlagRequired("output")
+
+	opts.remoteOpts.applyFlags(pullCmd.Flags())
+	pullCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the reference as an OCI layout directory in the format of path[:tag|@digest]")
+	pullCmd.Flags().BoolVarP(&opts.disableStrict, "disable-strict", "r", false, "Disable strict SPDX parsing as per the SPDX specification. When disabled, obom will fall back to a simple JSON parsing strategy")
+
+	// Add positional argument called reference to pullCmd
+	pullCmd.Args = cobra.ExactArgs(1)
+
+	return pullCmd
+}
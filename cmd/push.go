@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,22 +10,33 @@ import (
 	"github.com/Azure/obom/internal/print"
 	obom "github.com/Azure/obom/pkg"
 	"github.com/spf13/cobra"
-	"oras.land/oras-go/v2/registry"
-	"oras.land/oras-go/v2/registry/remote"
-	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/credentials"
-	"oras.land/oras-go/v2/registry/remote/retry"
 )
 
 type pushOpts struct {
-	filename            string
-	reference           string
-	username            string
-	password            string
-	disableStrict       bool
-	pushSummary         bool
-	ManifestAnnotations []string
-	attachArtifacts     []string
+	filename  string
+	reference string
+	remoteOpts
+	disableStrict        bool
+	pushSummary          bool
+	reproducible         bool
+	preset               string
+	manifestVersion      string
+	artifactType         string
+	configMediaType      string
+	configFile           string
+	layerMediaType       string
+	compression          string
+	chunkSize            int64
+	ManifestAnnotations  []string
+	annotationTemplates  []string
+	annotationFile       string
+	noDefaultAnnotations bool
+	attachArtifacts      []string
+	attachFile           string
+	tags                 []string
+	noTag                bool
+	exportManifest       string
+	digestFile           string
 }
 
 var (
@@ -47,28 +59,95 @@ Example - Push an SPDX SBOM to a registry
 Example - Push an SPDX SBOM to a registry with annotations
 	obom push -f spdx.json localhost:5000/spdx:latest --annotation key1=value1 --annotation key2=value2
 
+Example - Push an SPDX SBOM tagged with a version and a git commit
+	obom push -f spdx.json localhost:5000/spdx:v1.2.3,3f2a1c9
+
+Example - Push an SPDX SBOM with additional tags
+	obom push -f spdx.json localhost:5000/spdx:v1.2.3 --tag latest --tag 3f2a1c9
+
+Example - Push an SPDX SBOM by digest only, without tagging it
+	obom push -f spdx.json localhost:5000/spdx --no-tag
+
+Example - Push an SPDX SBOM reproducibly to the digest it is expected to have, failing if the digest does not match
+	obom push -f spdx.json localhost:5000/spdx@sha256:9834876dcfb05cb167a5c24953eba58c4ac89b1adf57f28f2f9d09af107ee8f0 --reproducible
+
+Example - Push an SPDX SBOM and export the pushed manifest and digest for the following steps of a pipeline
+	obom push -f spdx.json localhost:5000/spdx:latest --export-manifest push.json --digest-file digest.txt
+
+Example - Push an SPDX SBOM to the default registry of the config file
+	obom push -f spdx.json sboms/spdx:latest
+
+Example - Push an SPDX SBOM to the reference rendered from the repository-template of the config file
+	obom push -f spdx.json
+
 Example - Push an SPDX SBOM to a registry with strict SPDX parsing disabled
 	obom push -f spdx.json localhost:5000/spdx:latest --disable-strict
 
 Example - Push an SPDX SBOM to a registry with annotations
 	obom push -f spdx.json localhost:5000/spdx:latest --annotation key1=value1 --annotation key2=value2
 
+Example - Push an SPDX SBOM to a registry with annotations derived from the SBOM, the environment and git
+	obom push -f spdx.json localhost:5000/spdx:latest --annotation-template 'org.opencontainers.image.version={{ .RootPackage.Version }}' --annotation-template 'org.opencontainers.image.revision={{ .Git.Commit }}'
+
+Example - Push an SPDX SBOM to a registry with the annotations of an annotation file
+	obom push -f spdx.json localhost:5000/spdx:latest --annotation-file annotations.yaml
+
+Example - Push an SPDX SBOM to a registry without the standard OCI annotations derived from the SBOM
+	obom push -f spdx.json localhost:5000/spdx:latest --no-default-annotations
+
 Example - Push an SPDX SBOM to a registry with annotations and credentials
 	obom push -f spdx.json localhost:5000/spdx:latest --annotation key1=value1 --annotation key2=value2 --username user --password pass
 
+Example - Push an SPDX SBOM to a registry with the password read from stdin
+	echo $PASSWORD | obom push -f spdx.json localhost:5000/spdx:latest --username user --password-stdin
+
+Example - Push an SPDX SBOM to a registry with a registry access token
+	obom push -f spdx.json localhost:5000/spdx:latest --registry-token $TOKEN
+
+Example - Push an SPDX SBOM to a registry with a private CA and a client certificate
+	obom push -f spdx.json registry.internal:5000/spdx:latest --ca-file ca.pem --cert-file client.pem --key-file client-key.pem
+
+Example - Push an SPDX SBOM to a registry served over plain HTTP
+	obom push -f spdx.json 127.0.0.1:5000/spdx:latest --plain-http
+
+Example - Push an SPDX SBOM reproducibly, skipping the upload if the same SBOM was already pushed
+	obom push -f spdx.json localhost:5000/spdx:latest --reproducible
+
+Example - Push an SPDX SBOM with the manifest layout expected by Harbor
+	obom push -f spdx.json localhost:5000/spdx:latest --preset harbor
+
+Example - Push an SPDX SBOM as an image manifest v1.0 with an image config for older registries
+	obom push -f spdx.json localhost:5000/spdx:latest --manifest-version 1.0 --config-media-type application/vnd.oci.image.config.v1+json
+
+Example - Push an SPDX SBOM with the text/spdx artifactType and layer media type
+	obom push -f spdx.json localhost:5000/spdx:latest --preset text-spdx
+
+Example - Push a large SPDX SBOM with a zstd compressed layer
+	obom push -f spdx.json localhost:5000/spdx:latest --compress zstd
+
+Example - Push a gzip compressed SPDX SBOM file, which is decompressed when loading
+	obom push -f spdx.json.gz localhost:5000/spdx:latest
+
+Example - Push an SPDX SBOM split into a header layer and package and file chunks of at most 10 MiB each
+	obom push -f spdx.json localhost:5000/spdx:latest --chunk-size 10485760
+
 Example - Push an SPDX SBOM to a registry with attached artifacts where the key is the artifactType and the value is the path to the artifact
 	obom push -f spdx.json localhost:5000/spdx:latest --attach vnd.example.artifactType=/path/to/artifact --attach vnd.example.artifactType=/path/to/artifact2
+
+Example - Push an SPDX SBOM to a registry with the attached artifacts of an attachment file, with several layers, media types and annotations per artifact
+	obom push -f spdx.json localhost:5000/spdx:latest --attach-file attachments.yaml
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 
-			// get the reference as the first argument
-			opts.reference = args[0]
+			// get the reference as the first argument, prefixed with the default registry of the config
+			if len(args) > 0 {
+				opts.reference = expandReference(args[0])
 
-			// validate if reference is valid
-			ref, err := registry.ParseReference(opts.reference)
-			if err != nil {
-				fmt.Println("Error parsing reference:", err)
-				os.Exit(1)
+				// validate if reference is valid
+				if _, _, err := obom.ParsePushReference(opts.reference); err != nil {
+					fmt.Println("Error parsing reference:", err)
+					os.Exit(1)
+				}
 			}
 
 			// parse the annotations from the flags
@@ -77,6 +156,11 @@ Example - Push an SPDX SBOM to a registry with attached artifacts where the key
 				fmt.Println("Error parsing annotations:", err)
 				os.Exit(1)
 			}
+			inputTemplates, err := parseAnnotationFlags(opts.annotationTemplates)
+			if err != nil {
+				fmt.Println("Error parsing annotation templates:", err)
+				os.Exit(1)
+			}
 
 			// parse the attach artifacts from the flags
 			attachArtifacts, err := parseAttachArtifactFlags(opts.attachArtifacts)
@@ -84,68 +168,216 @@ Example - Push an SPDX SBOM to a registry with attached artifacts where the key
 				fmt.Println("Error parsing attach artifacts:", err)
 				os.Exit(1)
 			}
+			var attachments []obom.Attachment
+			if opts.attachFile != "" {
+				attachments, err = obom.LoadAttachmentFile(opts.attachFile)
+				if err != nil {
+					fmt.Println("Error loading attachment file:", err)
+					os.Exit(1)
+				}
+			}
+
+			// get the manifest layout from the preset and the layout flags
+			layout, err := getManifestLayout(cmd, &opts)
+			if err != nil {
+				fmt.Println("Error getting manifest layout:", err)
+				os.Exit(1)
+			}
+
+			if opts.noTag && len(opts.tags) > 0 {
+				fmt.Println("Error: --no-tag cannot be used with --tag")
+				os.Exit(1)
+			}
 
 			// set the strict mode to the opposite of the disableStrict flag
 			strict := !opts.disableStrict
-			sbom, desc, bytes, err := obom.LoadSBOMFromFile(opts.filename, strict)
+			sbom, err := obom.LoadSBOM(opts.filename, strict)
 			if err != nil {
 				fmt.Println("Error loading SBOM:", err)
 				os.Exit(1)
 			}
 
-			print.PrintSBOMSummary(sbom, desc)
+			print.PrintSBOMSummary(sbom.SPDXDocument, &sbom.Descriptor)
+
+			// render the reference from the repository template of the config when no reference is given
+			if opts.reference == "" {
+				opts.reference, err = renderRepositoryTemplate(sbom.SPDXDocument, opts.filename)
+				if err != nil {
+					fmt.Println("Error getting reference:", err)
+					os.Exit(1)
+				}
+				if _, _, err := obom.ParsePushReference(opts.reference); err != nil {
+					fmt.Println("Error parsing reference:", err)
+					os.Exit(1)
+				}
+			}
 
-			annotations, err := obom.GetAnnotations(sbom)
+			annotations, err := obom.GetAnnotations(sbom.SPDXDocument)
 			if err != nil {
 				fmt.Println("Error getting annotations:", err)
 				os.Exit(1)
 			}
 
-			// merge the input annotations with the annotations from the SBOM
+			// add the standard OCI annotations derived from the SBOM
+			if !opts.noDefaultAnnotations {
+				for k, v := range obom.GetDefaultAnnotations(sbom.Document) {
+					annotations[k] = v
+				}
+			}
+
+			// merge the default annotations of the config, the annotation file and the annotation template flags,
+			// rendered as templates, and then the literal annotation flags with the annotations from the SBOM
+			templates := getConfigAnnotations()
+			if opts.annotationFile != "" {
+				fileAnnotations, err := obom.LoadAnnotationFile(opts.annotationFile)
+				if err != nil {
+					fmt.Println("Error loading annotation file:", err)
+					os.Exit(1)
+				}
+				for k, v := range fileAnnotations {
+					templates[k] = v
+				}
+			}
+			for k, v := range inputTemplates {
+				templates[k] = v
+			}
+			data := obom.NewAnnotationTemplateData(sbom.Document, ".", obom.UsesGitInfo(templates))
+			renderedAnnotations, err := obom.RenderAnnotations(templates, data)
+			if err != nil {
+				fmt.Println("Error rendering annotations:", err)
+				os.Exit(1)
+			}
+			for k, v := range renderedAnnotations {
+				annotations[k] = v
+			}
 			for k, v := range inputAnnotations {
 				annotations[k] = v
 			}
 
-			// get the credentials resolver
-			resolver, err := getCredentialsResolver(ref.Registry, opts.username, opts.password)
+			ref, _, err := obom.ParsePushReference(opts.reference)
 			if err != nil {
-				fmt.Println("Error getting credentials resolver:", err)
+				fmt.Println("Error parsing reference:", err)
 				os.Exit(1)
 			}
-
-			repo, err := getRemoteRepoTarget(opts.reference, resolver)
+			repo, err := opts.remoteOpts.getRemoteRepoTarget(ref.String())
 			if err != nil {
 				fmt.Println("Error getting remote repository:", err)
 				os.Exit(1)
 			}
 
-			fmt.Printf("Pushing SBOM to %s@%s...\n", opts.reference, desc.Digest)
-			subject, err := obom.PushSBOM(sbom.Document, desc, bytes, opts.reference, annotations, opts.pushSummary, attachArtifacts, repo)
+			fmt.Printf("Pushing SBOM to %s@%s...\n", opts.reference, sbom.Descriptor.Digest)
+			pushOptions := obom.PushOptions{
+				Annotations:     annotations,
+				PushSummary:     opts.pushSummary,
+				AttachArtifacts: attachArtifacts,
+				Attachments:     attachments,
+				Reproducible:    opts.reproducible,
+				Layout:          layout,
+				Compression:     opts.compression,
+				ChunkSize:       opts.chunkSize,
+				Tags:            opts.tags,
+				NoTag:           opts.noTag,
+			}
+			progressFunc, progressDone := newProgressFunc("Pushed")
+			pushOptions.Progress = progressFunc
+			result, err := obom.PushSBOMArtifact(context.Background(), sbom, opts.reference, repo, pushOptions)
+			progressDone()
 			if err != nil {
 				fmt.Println("Error pushing SBOM:", err)
 				os.Exit(1)
 			}
-			fmt.Printf("SBOM pushed to %s@%s\n", opts.reference, subject.Digest)
+			status := "pushed to"
+			if result.Unchanged {
+				status = "unchanged at"
+			}
+			repository := ref.Registry + "/" + ref.Repository
+			if opts.exportManifest != "" {
+				if err := obom.WritePushExport(opts.exportManifest, obom.NewPushExport(repository, result)); err != nil {
+					fmt.Println("Error exporting manifest:", err)
+					os.Exit(1)
+				}
+			}
+			if opts.digestFile != "" {
+				if err := obom.WriteDigestFile(opts.digestFile, result); err != nil {
+					fmt.Println("Error writing digest file:", err)
+					os.Exit(1)
+				}
+			}
+			if len(result.Tags) == 0 {
+				fmt.Printf("SBOM %s %s@%s\n", status, repository, result.Descriptor.Digest)
+			}
+			for _, tag := range result.Tags {
+				fmt.Printf("SBOM %s %s:%s@%s\n", status, repository, tag, result.Descriptor.Digest)
+			}
 		},
 	}
 
 	pushCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
 	pushCmd.MarkFlagRequired("file")
 
-	pushCmd.Flags().StringArrayVarP(&opts.ManifestAnnotations, "annotation", "a", nil, "manifest annotations")
+	pushCmd.Flags().StringArrayVarP(&opts.ManifestAnnotations, "annotation", "a", nil, "manifest annotations, the values are used as is")
+	pushCmd.Flags().StringArrayVar(&opts.annotationTemplates, "annotation-template", nil, "manifest annotations whose values are Go templates")
+	pushCmd.Flags().StringVar(&opts.annotationFile, "annotation-file", "", "Path to a JSON or YAML file of manifest annotations, the values are Go templates")
+	pushCmd.Flags().BoolVar(&opts.noDefaultAnnotations, "no-default-annotations", false, "Do not add the standard OCI annotations (created, title, version, licenses, source, revision) derived from the SBOM")
 
-	pushCmd.Flags().StringVarP(&opts.username, "username", "u", "", "Username for the registry")
-	pushCmd.Flags().StringVarP(&opts.password, "password", "p", "", "Password for the registry")
-	pushCmd.Flags().BoolVarP(&opts.pushSummary, "pushSummary", "s", false, "Push summary blob to the registry")
+	opts.remoteOpts.applyFlags(pushCmd.Flags())
+	pushCmd.Flags().BoolVarP(&opts.pushSummary, "pushSummary", "s", false, "Push the summary of the SBOM as an application/vnd.obom.summary.v1+json layer, see obom summary")
 	pushCmd.Flags().BoolVarP(&opts.disableStrict, "disable-strict", "r", false, "Disable strict SPDX parsing as per the SPDX specification. When disabled, obom will fall back to a simple JSON parsing strategy")
+	pushCmd.Flags().BoolVar(&opts.reproducible, "reproducible", false, "Derive the manifest creation time from the SBOM so that pushing the same SBOM again yields the same digest")
+	pushCmd.Flags().StringVar(&opts.preset, "preset", "default", fmt.Sprintf("Manifest layout preset for known SBOM consumers, one of %v. The layout flags override the preset. Docker Scout reads SBOM attestations rather than SPDX artifacts, so it has no preset", obom.GetManifestLayoutPresetNames()))
+	pushCmd.Flags().StringVar(&opts.manifestVersion, "manifest-version", "", "OCI image manifest version, 1.0 or 1.1")
+	pushCmd.Flags().StringVar(&opts.artifactType, "artifact-type", "", "artifactType of the SBOM manifest")
+	pushCmd.Flags().StringVar(&opts.configMediaType, "config-media-type", "", "Media type of the config blob, the empty config is used when not set")
+	pushCmd.Flags().StringVar(&opts.configFile, "config-file", "", "Path to the content of the config blob, used with --config-media-type")
+	pushCmd.Flags().StringVar(&opts.layerMediaType, "layer-media-type", "", "Media type of the SBOM layer")
+	pushCmd.Flags().StringVar(&opts.compression, "compress", "", "Compress the SBOM layer with gzip or zstd")
+	pushCmd.Flags().Int64Var(&opts.chunkSize, "chunk-size", 0, "Split the packages and files of the SBOM into layers of at most this many bytes, for registries with a blob size limit")
 	pushCmd.Flags().StringArrayVarP(&opts.attachArtifacts, "attach", "t", nil, "Attach artifacts to the SBOM")
+	pushCmd.Flags().StringVar(&opts.attachFile, "attach-file", "", "Path to a JSON or YAML file of artifacts to attach to the SBOM, with their artifactType, annotations and layers")
+	pushCmd.Flags().StringArrayVar(&opts.tags, "tag", nil, "Additional tag of the SBOM manifest, can be repeated. The reference can also list comma separated tags, such as repo:tag1,tag2")
+	pushCmd.Flags().BoolVar(&opts.noTag, "no-tag", false, "Push the SBOM manifest by digest only, without tagging it")
+	pushCmd.Flags().StringVar(&opts.exportManifest, "export-manifest", "", "Path of a JSON file to write the reference by digest, the tags, the descriptor and content of the pushed manifest and the descriptors of the attached artifacts to")
+	pushCmd.Flags().StringVar(&opts.digestFile, "digest-file", "", "Path of a file to write the digest of the pushed manifest to")
 
-	// Add positional argument called reference to pushCmd
-	pushCmd.Args = cobra.ExactArgs(1)
+	// Add positional argument called reference to pushCmd, which defaults to the repository template of the config
+	pushCmd.Args = cobra.MaximumNArgs(1)
 
 	return pushCmd
 }
 
+// getManifestLayout returns the layout of the preset, overridden by the layout flags that are set
+func getManifestLayout(cmd *cobra.Command, opts *pushOpts) (obom.ManifestLayout, error) {
+	layout, err := obom.GetManifestLayoutPreset(opts.preset)
+	if err != nil {
+		return layout, err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("manifest-version") {
+		layout.ManifestVersion = opts.manifestVersion
+	}
+	if flags.Changed("artifact-type") {
+		layout.ArtifactType = opts.artifactType
+	}
+	if flags.Changed("config-media-type") {
+		layout.ConfigMediaType = opts.configMediaType
+	}
+	if flags.Changed("layer-media-type") {
+		layout.LayerMediaType = opts.layerMediaType
+	}
+	if opts.configFile != "" {
+		if layout.ConfigMediaType == "" {
+			return layout, errors.New("--config-file requires --config-media-type")
+		}
+		layout.ConfigContent, err = os.ReadFile(opts.configFile)
+		if err != nil {
+			return layout, fmt.Errorf("error reading config file: %w", err)
+		}
+	}
+
+	return layout, nil
+}
+
 func parseAnnotationFlags(flags []string) (map[string]string, error) {
 	manifestAnnotations := make(map[string]string)
 	for _, anno := range flags {
@@ -172,56 +404,3 @@ func parseAttachArtifactFlags(flags []string) (map[string][]string, error) {
 	}
 	return attachArtifacts, nil
 }
-
-func getCredentialsResolver(registry string, username string, password string) (obom.CredentialsResolver, error) {
-	if len(username) != 0 && len(password) != 0 {
-		return auth.StaticCredential(registry, auth.Credential{
-			Username: username,
-			Password: password,
-		}), nil
-	} else {
-		storeOpts := credentials.StoreOptions{}
-		store, err := credentials.NewStoreFromDocker(storeOpts)
-		if err != nil {
-			return nil, err
-		}
-		return credentials.Credential(store), nil
-	}
-}
-
-func getRemoteRepoTarget(reference string, credsResolver obom.CredentialsResolver) (*remote.Repository, error) {
-	// Parse the reference
-	ref, err := registry.ParseReference(reference)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing reference: %w", err)
-	}
-
-	// Construct the repository string without the tag/digest
-	// This allows us to reuse this repository target for pushing the SBOM and attaching artifacts
-	repoStr := fmt.Sprintf("%s/%s", ref.Registry, ref.Repository)
-
-	// Connect to a remote repository
-	repo, err := remote.NewRepository(repoStr)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to remote repository: %w", err)
-	}
-
-	// Check if registry has is localhost or starts with localhost:
-	reg := repo.Reference.Registry
-	if strings.HasPrefix(reg, "localhost:") {
-		repo.PlainHTTP = true
-	}
-
-	// Prepare the auth client for the registry
-	client := &auth.Client{
-		Client: retry.DefaultClient,
-		Cache:  auth.DefaultCache,
-	}
-
-	client.Credential = credsResolver
-
-	client.SetUserAgent(APPLICATION_USERAGENT)
-	repo.Client = client
-
-	return repo, nil
-}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type pushBatchOpts struct {
+	from       string
+	repository string
+	remoteOpts
+	disableStrict        bool
+	pushSummary          bool
+	reproducible         bool
+	noDefaultAnnotations bool
+	concurrency          int
+	retries              int
+	report               string
+}
+
+func pushBatchCmd() *cobra.Command {
+	var opts pushBatchOpts
+	var pushBatchCmd = &cobra.Command{
+		Use:   "push-batch",
+		Short: "Push many SPDX SBOMs to the registry concurrently",
+		Long: `Push many SPDX SBOMs concurrently from a batch file or a directory and report the result of every push as JSON
+
+The batch file is a YAML or JSON file listing the SBOMs to push:
+
+	items:
+	  - file: ./component-a.spdx.json
+	    reference: localhost:5000/sboms/component-a:v1
+	    annotations:
+	      key1: value1
+	    attach:
+	      application/json:
+	        - ./component-a.scan.json
+
+Example - Push the SBOMs listed in a batch file
+	obom push-batch --from batch.yaml
+
+Example - Push every SBOM matching a glob, tagged with the file name, to a repository
+	obom push-batch --from './sboms/*.spdx.json' --repository localhost:5000/sboms
+
+Example - Push the SBOMs with 10 concurrent pushes and write the report to a file
+	obom push-batch --from batch.yaml --concurrency 10 --report report.json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var items []obom.BatchItem
+			var err error
+			ext := strings.ToLower(filepath.Ext(opts.from))
+			if ext == ".yaml" || ext == ".yml" || (ext == ".json" && opts.repository == "") {
+				items, err = obom.LoadBatchFile(opts.from)
+			} else {
+				if opts.repository == "" {
+					fmt.Println("Error loading batch: --repository is required when pushing from a directory or glob")
+					os.Exit(1)
+				}
+				items, err = obom.BatchItemsFromGlob(opts.from, expandReference(opts.repository))
+			}
+			if err != nil {
+				fmt.Println("Error loading batch:", err)
+				os.Exit(1)
+			}
+
+			// validate all the references before pushing anything
+			for i, item := range items {
+				items[i].Reference = expandReference(item.Reference)
+				if _, _, err := obom.ParsePushReference(items[i].Reference); err != nil {
+					fmt.Printf("Error parsing reference %s: %v\n", item.Reference, err)
+					os.Exit(1)
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "Pushing %d SBOMs...\n", len(items))
+			results := obom.PushBatch(context.Background(), items, newBatchTargetResolver(&opts.remoteOpts), obom.BatchOptions{
+				Concurrency:          opts.concurrency,
+				Retries:              opts.retries,
+				RetryDelay:           obom.DEFAULT_BATCH_RETRY_DELAY,
+				Strict:               !opts.disableStrict,
+				PushSummary:          opts.pushSummary,
+				Reproducible:         opts.reproducible,
+				NoDefaultAnnotations: opts.noDefaultAnnotations,
+				AnnotationTemplates:  getConfigAnnotations(),
+			})
+
+			reportBytes, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Println("Error marshaling report:", err)
+				os.Exit(1)
+			}
+
+			if opts.report != "" {
+				if err := os.WriteFile(opts.report, reportBytes, 0644); err != nil {
+					fmt.Println("Error writing report:", err)
+					os.Exit(1)
+				}
+			} else {
+				fmt.Println(string(reportBytes))
+			}
+
+			failed := 0
+			for _, result := range results {
+				if result.Error != "" {
+					failed++
+				}
+			}
+			fmt.Fprintf(os.Stderr, "Pushed %d of %d SBOMs\n", len(results)-failed, len(results))
+			if failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	pushBatchCmd.Flags().StringVar(&opts.from, "from", "", "Path to a YAML or JSON batch file, or a directory or glob of SPDX SBOM files")
+	pushBatchCmd.MarkFlagRequired("from")
+
+	pushBatchCmd.Flags().StringVar(&opts.repository, "repository", "", "Repository to push to when pushing from a directory or glob, tagged with the file name")
+	opts.remoteOpts.applyFlags(pushBatchCmd.Flags())
+	pushBatchCmd.Flags().BoolVarP(&opts.pushSummary, "pushSummary", "s", false, "Push summary blob to the registry")
+	pushBatchCmd.Flags().BoolVarP(&opts.disableStrict, "disable-strict", "r", false, "Disable strict SPDX parsing as per the SPDX specification. When disabled, obom will fall back to a simple JSON parsing strategy")
+	pushBatchCmd.Flags().BoolVar(&opts.reproducible, "reproducible", false, "Derive the manifest creation time from the SBOM so that pushing the same SBOMs again yields the same digests")
+	pushBatchCmd.Flags().BoolVar(&opts.noDefaultAnnotations, "no-default-annotations", false, "Do not add the standard OCI annotations (created, title, version, licenses, source, revision) derived from the SBOMs")
+	pushBatchCmd.Flags().IntVar(&opts.concurrency, "concurrency", obom.DEFAULT_BATCH_CONCURRENCY, "Maximum number of SBOMs pushed at the same time")
+	pushBatchCmd.Flags().IntVar(&opts.retries, "retries", obom.DEFAULT_BATCH_RETRIES, "Number of times a push failing with a 5xx or 429 registry response or a network timeout is retried. Every registry request is already retried up to 5 times by the HTTP client, so a failing registry is requested up to 6 times per push attempt")
+	pushBatchCmd.Flags().StringVar(&opts.report, "report", "", "Write the JSON report to the given file instead of stdout")
+
+	return pushBatchCmd
+}
+
+// newBatchTargetResolver returns a resolver that shares one authenticated client per registry across all the pushes
+func newBatchTargetResolver(remote *remoteOpts) obom.TargetResolver {
+	type registryClient struct {
+		client   *auth.Client
+		settings registrySettings
+	}
+
+	var mu sync.Mutex
+	clients := make(map[string]registryClient)
+
+	return func(reference string) (oras.Target, error) {
+		ref, _, err := obom.ParsePushReference(reference)
+		if err != nil {
+			return nil, err
+		}
+
+		mu.Lock()
+		regClient, ok := clients[ref.Registry]
+		if !ok {
+			client, settings, err := remote.getAuthClient(ref.Registry)
+			if err != nil {
+				mu.Unlock()
+				return nil, err
+			}
+			regClient = registryClient{client: client, settings: settings}
+			clients[ref.Registry] = regClient
+		}
+		mu.Unlock()
+
+		return getRemoteRepoTargetWithClient(ref.String(), regClient.client, regClient.settings.PlainHTTP)
+	}
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/pflag"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+var errClientCertificate = errors.New("`--cert-file` and `--key-file` must be used together")
+
+// remoteOpts are the flags for the credentials and the transport of a registry
+type remoteOpts struct {
+	credentialOpts
+	registrySettings
+}
+
+// registrySettings are the transport settings of a registry, set with flags or per registry in the config file
+type registrySettings struct {
+	PlainHTTP bool   `mapstructure:"plain-http"`
+	Insecure  bool   `mapstructure:"insecure"`
+	CAFile    string `mapstructure:"ca-file"`
+	CertFile  string `mapstructure:"cert-file"`
+	KeyFile   string `mapstructure:"key-file"`
+
+	// flags and prefix are the flag set and the prefix of the flags of the settings, to tell the flags set on the
+	// command line from their defaults
+	flags  *pflag.FlagSet
+	prefix string
+}
+
+// applyFlags adds the credential and transport flags to the flag set
+func (opts *remoteOpts) applyFlags(fs *pflag.FlagSet) {
+	opts.credentialOpts.applyFlags(fs)
+	opts.registrySettings.applyFlags(fs, "", "registry")
+}
+
+// applyFlagsWithPrefix adds the credential and transport flags to the flag set with the prefix, such as --from-ca-file,
+// for commands working with two registries
+func (opts *remoteOpts) applyFlagsWithPrefix(fs *pflag.FlagSet, prefix string, description string) {
+	opts.credentialOpts.applyFlagsWithPrefix(fs, prefix, description)
+	opts.registrySettings.applyFlags(fs, prefix+"-", description)
+}
+
+func (s *registrySettings) applyFlags(fs *pflag.FlagSet, prefix string, description string) {
+	s.flags = fs
+	s.prefix = prefix
+	fs.BoolVar(&s.PlainHTTP, prefix+"plain-http", false, "Use plain HTTP instead of HTTPS for the "+description)
+	fs.BoolVar(&s.Insecure, prefix+"insecure", false, "Skip the TLS certificate verification of the "+description)
+	fs.StringVar(&s.CAFile, prefix+"ca-file", "", "Path of the CA certificates file to verify the "+description+" with, in addition to the system CAs")
+	fs.StringVar(&s.CertFile, prefix+"cert-file", "", "Path of the client certificate file for mutual TLS with the "+description)
+	fs.StringVar(&s.KeyFile, prefix+"key-file", "", "Path of the client private key file for mutual TLS with the "+description)
+}
+
+// flagChanged reports whether the flag of the settings with the name was set on the command line
+func (s *registrySettings) flagChanged(name string) bool {
+	return s.flags != nil && s.flags.Changed(s.prefix+name)
+}
+
+// getRegistrySettings returns the transport settings of the registry from the config file, overridden by the flags
+func (opts *remoteOpts) getRegistrySettings(registryName string) (registrySettings, error) {
+	entry, err := getRegistryConfig(registryName)
+	if err != nil {
+		return registrySettings{}, err
+	}
+	settings := entry.registrySettings
+
+	// localhost registries default to plain HTTP, unless the config of the registry sets it
+	if strings.HasPrefix(registryName, "localhost:") && !isRegistryConfigSet(registryName, "plain-http") {
+		settings.PlainHTTP = true
+	}
+
+	// the boolean flags override the config only when set, so that --plain-http=false disables plain HTTP
+	if opts.flagChanged("plain-http") {
+		settings.PlainHTTP = opts.PlainHTTP
+	}
+	if opts.flagChanged("insecure") {
+		settings.Insecure = opts.Insecure
+	}
+	if opts.CAFile != "" {
+		settings.CAFile = opts.CAFile
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		settings.CertFile = opts.CertFile
+		settings.KeyFile = opts.KeyFile
+	}
+
+	return settings, nil
+}
+
+// newHTTPClient returns the HTTP client with the TLS settings of the registry, retrying failed requests and tracing
+// them with --debug
+func newHTTPClient(settings registrySettings) (*http.Client, error) {
+	if !settings.Insecure && settings.CAFile == "" && settings.CertFile == "" && settings.KeyFile == "" {
+		if logging.debug {
+			return &http.Client{Transport: retry.NewTransport(newTraceTransport(http.DefaultTransport))}, nil
+		}
+		return retry.DefaultClient, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: settings.Insecure,
+	}
+
+	if settings.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		caBytes, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("error reading CA file %s: no PEM certificates found", settings.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		if settings.CertFile == "" || settings.KeyFile == "" {
+			return nil, errClientCertificate
+		}
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: retry.NewTransport(newTraceTransport(transport))}, nil
+}
+
+// getAuthClient returns the auth client for the registry with the credentials and the transport settings
+// of the registry, along with the settings
+func (opts *remoteOpts) getAuthClient(registryName string) (*auth.Client, registrySettings, error) {
+	settings, err := opts.getRegistrySettings(registryName)
+	if err != nil {
+		return nil, settings, err
+	}
+
+	resolver, err := opts.credentialsResolver(registryName)
+	if err != nil {
+		return nil, settings, fmt.Errorf("error getting credentials resolver: %w", err)
+	}
+
+	httpClient, err := newHTTPClient(settings)
+	if err != nil {
+		return nil, settings, err
+	}
+
+	return newAuthClient(resolver, httpClient), settings, nil
+}
+
+// getRemoteRepoTarget returns the remote repository of the reference with the credentials and transport
+// settings of its registry
+func (opts *remoteOpts) getRemoteRepoTarget(reference string) (*remote.Repository, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing reference: %w", err)
+	}
+
+	client, settings, err := opts.getAuthClient(ref.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	return getRemoteRepoTargetWithClient(reference, client, settings.PlainHTTP)
+}
+
+// getRemoteRegistry returns the remote registry for the registry host name with its transport settings
+func (opts *remoteOpts) getRemoteRegistry(registryName string) (*remote.Registry, error) {
+	reg, err := remote.NewRegistry(registryName)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing registry %s: %w", registryName, err)
+	}
+
+	settings, err := opts.getRegistrySettings(reg.Reference.Registry)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := newHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	reg.PlainHTTP = settings.PlainHTTP
+	reg.Client = newAuthClient(nil, httpClient)
+
+	return reg, nil
+}
+
+func getRemoteRepoTargetWithClient(reference string, client remote.Client, plainHTTP bool) (*remote.Repository, error) {
+	// Parse the reference
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing reference: %w", err)
+	}
+
+	// Construct the repository string without the tag/digest
+	// This allows us to reuse this repository target for pushing the SBOM and attaching artifacts
+	repoStr := fmt.Sprintf("%s/%s", ref.Registry, ref.Repository)
+
+	// Connect to a remote repository
+	repo, err := remote.NewRepository(repoStr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to remote repository: %w", err)
+	}
+
+	repo.PlainHTTP = plainHTTP
+	repo.Client = client
+
+	return repo, nil
+}
+
+// newAuthClient prepares the auth client for a registry.
+// The client can be shared by all the repositories of the registry.
+func newAuthClient(credsResolver obom.CredentialsResolver, httpClient *http.Client) *auth.Client {
+	client := &auth.Client{
+		Client: httpClient,
+		Cache:  auth.DefaultCache,
+	}
+
+	client.Credential = credsResolver
+
+	client.SetUserAgent(APPLICATION_USERAGENT)
+
+	return client
+}
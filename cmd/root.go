@@ -24,6 +24,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -49,9 +50,11 @@ You can use it to inspect packages or upload an SBOM file as an OCI artifact.
 Example:
 	obom push -f ./examples/SPDXJSONExample-v2.3.spdx.json localhost:5001/spdx:example
 `,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+	// set up the logging and apply the config file and environment defaults to the flags of every command
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logging.setupLogging()
+		return applyConfigDefaults(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -71,15 +74,30 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.obom.yaml)")
-
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().String("profile", "", "profile of the config file to use, overriding the top-level config")
+	rootCmd.PersistentFlags().StringVar(&registryConfig, "registry-config", "", "path of the registry credentials config file (default is the Docker config)")
+	logging.applyFlags(rootCmd.PersistentFlags())
+	cobra.CheckErr(viper.BindPFlag(configKeyProfile, rootCmd.PersistentFlags().Lookup("profile")))
 
 	rootCmd.AddCommand(showCmd(),
+		summaryCmd(),
 		pushCmd(),
+		pushBatchCmd(),
+		pullCmd(),
+		copyCmd(),
+		attachArtifactCmd(),
+		deleteCmd(),
+		pruneCmd(),
+		indexCmd(),
+		searchCmd(),
+		loginCmd(),
+		logoutCmd(),
 		packagesCmd(),
+		enrichCmd(),
+		generateCmd(),
 		filesCmd(),
+		verifyFilesCmd(),
+		verifyImageCmd(),
 		versionCmd())
 }
 
@@ -99,10 +117,18 @@ func initConfig() {
 		viper.SetConfigName(".obom")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match the config keys with the OBOM_ prefix, such as OBOM_STRICT or
+	// OBOM_PROFILE. The registry credentials are read from OBOM_USERNAME and OBOM_PASSWORD by credentialOpts.
+	viper.SetEnvPrefix("obom")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	} else if cfgFile != "" {
+		cobra.CheckErr(fmt.Errorf("error reading config file: %w", err))
 	}
+
+	cobra.CheckErr(applyProfile())
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type searchOpts struct {
+	catalog string
+	query   obom.CatalogQuery
+	format  string
+}
+
+func searchCmd() *cobra.Command {
+	var opts searchOpts
+	var searchCmd = &cobra.Command{
+		Use:   "search",
+		Short: "Search the packages of the SBOMs indexed into the local catalog",
+		Long: `Search the packages of the SBOMs indexed with obom index add, and print the reference and the digest of the SBOMs
+containing each matching package. A package matches when it matches all the given criteria.
+
+Example - Search the SBOMs containing log4j-core older than 2.17
+	obom search --name log4j-core --version '<2.17'
+
+Example - Search the SBOMs containing any version of a package URL
+	obom search --purl pkg:maven/org.apache.logging.log4j/log4j-core
+
+Example - Search the packages with a GPL license as JSON
+	obom search --license GPL --format json
+
+Example - Search the packages of a supplier
+	obom search --supplier 'Example Inc'
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			catalog, err := openCatalog(opts.catalog)
+			if err != nil {
+				fmt.Println("Error opening catalog:", err)
+				os.Exit(1)
+			}
+			defer catalog.Close()
+
+			matches, err := catalog.Search(opts.query)
+			if err != nil {
+				fmt.Println("Error searching catalog:", err)
+				os.Exit(1)
+			}
+
+			switch opts.format {
+			case "text":
+				print.PrintCatalogMatches(matches)
+			case "json":
+				if err := print.PrintJSON(matches); err != nil {
+					fmt.Println("Error printing matches:", err)
+					os.Exit(1)
+				}
+			default:
+				fmt.Printf("Error: unknown format %q, expected text or json\n", opts.format)
+				os.Exit(1)
+			}
+		},
+	}
+
+	searchCmd.Flags().StringVar(&opts.catalog, "catalog", "", "Path of the catalog, defaults to the catalog of the config file or catalog.db in the obom user cache directory")
+	searchCmd.Flags().StringVar(&opts.query.PURL, "purl", "", "Package URL to search, matching any version unless the package URL has one")
+	searchCmd.Flags().StringVar(&opts.query.Name, "name", "", "Package name to search, case insensitive")
+	searchCmd.Flags().StringVar(&opts.query.VersionRange, "version", "", "Comma separated version constraints of the packages, such as '>=2.0,<2.17'")
+	searchCmd.Flags().StringVar(&opts.query.License, "license", "", "License the license expression of the packages contains, case insensitive")
+	searchCmd.Flags().StringVar(&opts.query.Supplier, "supplier", "", "Supplier the supplier of the packages contains, case insensitive")
+	searchCmd.Flags().StringVar(&opts.format, "format", "text", "Output format, text or json")
+
+	return searchCmd
+}
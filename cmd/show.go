@@ -12,6 +12,7 @@ import (
 type showOptions struct {
 	filename string
 	strict   bool
+	format   string
 }
 
 func showCmd() *cobra.Command {
@@ -21,19 +22,31 @@ func showCmd() *cobra.Command {
 		Short: "Show summay of the spdx",
 		Long:  `Show the SPDX summary fields`,
 		Run: func(cmd *cobra.Command, args []string) {
-			sbom, desc, _, err := obom.LoadSBOMFromFile(opts.filename, opts.strict)
+			sbom, err := obom.LoadSBOM(opts.filename, opts.strict)
 			if err != nil {
 				fmt.Println("Error loading SBOM:", err)
 				os.Exit(1)
 			}
 
-			print.PrintSBOMSummary(sbom, desc)
+			switch opts.format {
+			case "text":
+				print.PrintSBOMSummary(sbom.SPDXDocument, &sbom.Descriptor)
+			case "json":
+				if err := print.PrintSBOMSummaryJSON(sbom.SPDXDocument, &sbom.Descriptor); err != nil {
+					fmt.Println("Error printing summary:", err)
+					os.Exit(1)
+				}
+			default:
+				fmt.Printf("Error: unknown format %q, expected text or json\n", opts.format)
+				os.Exit(1)
+			}
 		},
 	}
 
 	showCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
 	showCmd.MarkFlagRequired("file")
 
+	showCmd.Flags().StringVar(&opts.format, "format", "text", "Output format, text or json")
 	showCmd.Flags().BoolVarP(&opts.strict, "strict", "r", true, "Enable strict SPDX parsing as per the SPDX specification. Set --strict=false to fallback to simple JSON parsing strategy")
 
 	return showCmd
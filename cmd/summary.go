@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type summaryOptions struct {
+	filename string
+	strict   bool
+}
+
+func summaryCmd() *cobra.Command {
+	var opts summaryOptions
+	var summaryCmd = &cobra.Command{
+		Use:   "summary",
+		Short: "Print the summary pushed with --pushSummary",
+		Long: `Print the SBOM summary as JSON, the same object that is pushed as the application/vnd.obom.summary.v1+json layer with --pushSummary
+
+Example - Print the summary of an SPDX SBOM
+	obom summary -f spdx.json
+
+Example - Print the license expression totals of an SPDX SBOM
+	obom summary -f spdx.json | jq .licenses
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sbom, err := obom.LoadSBOM(opts.filename, opts.strict)
+			if err != nil {
+				fmt.Println("Error loading SBOM:", err)
+				os.Exit(1)
+			}
+
+			summary, err := obom.GetSBOMSummary(sbom.Document)
+			if err != nil {
+				fmt.Println("Error getting summary:", err)
+				os.Exit(1)
+			}
+
+			if err := print.PrintJSON(summary); err != nil {
+				fmt.Println("Error printing summary:", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	summaryCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
+	summaryCmd.MarkFlagRequired("file")
+
+	summaryCmd.Flags().BoolVarP(&opts.strict, "strict", "r", true, "Enable strict SPDX parsing as per the SPDX specification. Set --strict=false to fallback to simple JSON parsing strategy")
+
+	return summaryCmd
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type verifyFilesOptions struct {
+	filename    string
+	root        string
+	format      string
+	ignoreExtra bool
+}
+
+func verifyFilesCmd() *cobra.Command {
+	var opts verifyFilesOptions
+	var verifyFilesCmd = &cobra.Command{
+		Use:   "verify-files",
+		Short: "Verify the files of the SBOM against a directory",
+		Long: `Verify the files of the SBOM against a directory tree, such as a build output or an unpacked image root file system.
+The files of the SBOM are compared with the files of the directory by their name relative to the root: the missing and
+extra files and the SHA1 and SHA256 checksums that do not match are reported, and the verification code of the packages
+is recomputed from their files. The command exits with an error when there are discrepancies.
+
+Example - Verify the files of an SBOM against a build output
+	obom verify-files -f spdx.json --root ./dist
+
+Example - Verify the files of an SBOM against an unpacked image, ignoring the files that are not in the SBOM
+	obom verify-files -f spdx.json --root ./rootfs --ignore-extra
+
+Example - Print the verification report as JSON
+	obom verify-files -f spdx.json --root ./dist --format json
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sbom, err := obom.LoadSBOM(opts.filename, true)
+			if err != nil {
+				fmt.Println("Error loading SBOM:", err)
+				os.Exit(1)
+			}
+
+			report, err := obom.VerifyFiles(sbom.Document, opts.root)
+			if err != nil {
+				fmt.Println("Error verifying files:", err)
+				os.Exit(1)
+			}
+			if opts.ignoreExtra {
+				report.Extra = []string{}
+			}
+
+			switch opts.format {
+			case "text":
+				print.PrintFileVerificationReport(report)
+			case "json":
+				if err := print.PrintJSON(report); err != nil {
+					fmt.Println("Error printing report:", err)
+					os.Exit(1)
+				}
+			default:
+				fmt.Printf("Error: unknown format %q, expected text or json\n", opts.format)
+				os.Exit(1)
+			}
+
+			if !report.OK() {
+				os.Exit(1)
+			}
+		},
+	}
+
+	verifyFilesCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
+	verifyFilesCmd.MarkFlagRequired("file")
+	verifyFilesCmd.Flags().StringVar(&opts.root, "root", "", "Path to the directory the file names of the SBOM are relative to")
+	verifyFilesCmd.MarkFlagRequired("root")
+	verifyFilesCmd.Flags().StringVar(&opts.format, "format", "text", "Output format, text or json")
+	verifyFilesCmd.Flags().BoolVar(&opts.ignoreExtra, "ignore-extra", false, "Ignore the files of the directory that are not files of the SBOM")
+
+	return verifyFilesCmd
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Azure/obom/internal/print"
+	obom "github.com/Azure/obom/pkg"
+	"github.com/spf13/cobra"
+)
+
+type verifyImageOptions struct {
+	filename  string
+	reference string
+	remoteOpts
+	ociLayout   bool
+	platform    string
+	format      string
+	ignoreExtra bool
+	listFiles   bool
+}
+
+func verifyImageCmd() *cobra.Command {
+	var opts verifyImageOptions
+	var verifyImageCmd = &cobra.Command{
+		Use:   "verify-image <reference>",
+		Short: "Verify the SBOM against the contents of an image",
+		Long: `Verify the SBOM against the contents of an image of a registry or an OCI layout directory.
+The layers of the image are read to assemble its file system, applying the whiteouts of the upper layers. The files of the
+SBOM are verified against the file system as verify-files does, and the packages installed according to the dpkg, apk and
+rpm sqlite databases and to the build info of the Go binaries of the image are compared with the packages of the SBOM.
+The coverage of the installed packages by the SBOM, the version mismatches, the packages of the SBOM that are not installed
+and the installed packages missing from the SBOM are reported. rpm Berkeley DB databases are reported as unsupported, and
+malformed package databases with their parse error.
+The command exits with an error when there are discrepancies.
+
+Example - Verify an SBOM against an image
+	obom verify-image -f spdx.json localhost:5000/app:v1
+
+Example - Verify an SBOM against the linux/arm64 image of a multi-platform image
+	obom verify-image -f spdx.json localhost:5000/app:v1 --platform linux/arm64
+
+Example - Verify an SBOM listing the packages only, ignoring the files that are not in the SBOM
+	obom verify-image -f spdx.json localhost:5000/app:v1 --ignore-extra
+
+Example - Verify an SBOM against an image of an OCI layout directory and print the report as JSON
+	obom verify-image -f spdx.json --oci-layout ./layout:v1 --format json
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.reference = args[0]
+			if !opts.ociLayout {
+				opts.reference = expandReference(opts.reference)
+			}
+
+			verifyOpts := obom.VerifyImageOptions{}
+			if opts.platform != "" {
+				platform, err := obom.ParsePlatform(opts.platform)
+				if err != nil {
+					fmt.Println("Error parsing platform:", err)
+					os.Exit(1)
+				}
+				verifyOpts.Platform = platform
+			}
+
+			sbom, err := obom.LoadSBOM(opts.filename, true)
+			if err != nil {
+				fmt.Println("Error loading SBOM:", err)
+				os.Exit(1)
+			}
+
+			target, ref, err := getTarget(opts.reference, opts.ociLayout, &opts.remoteOpts)
+			if err != nil {
+				fmt.Println("Error getting target:", err)
+				os.Exit(1)
+			}
+			if ref == "" {
+				ref = "latest"
+			}
+
+			report, err := obom.VerifyImage(context.Background(), sbom.Document, target, ref, verifyOpts)
+			if err != nil {
+				fmt.Println("Error verifying image:", err)
+				os.Exit(1)
+			}
+			report.Reference = opts.reference
+			report.Files.Root = opts.reference
+			if opts.ignoreExtra {
+				report.Files.Extra = []string{}
+			}
+			if !opts.listFiles {
+				for i := range report.Layers {
+					report.Layers[i].Files = nil
+					report.Layers[i].Deleted = nil
+				}
+			}
+
+			switch opts.format {
+			case "text":
+				print.PrintImageVerificationReport(report)
+			case "json":
+				if err := print.PrintJSON(report); err != nil {
+					fmt.Println("Error printing report:", err)
+					os.Exit(1)
+				}
+			default:
+				fmt.Printf("Error: unknown format %q, expected text or json\n", opts.format)
+				os.Exit(1)
+			}
+
+			if !report.OK() {
+				os.Exit(1)
+			}
+		},
+	}
+
+	verifyImageCmd.Flags().StringVarP(&opts.filename, "file", "f", "", "Path to the SPDX SBOM file")
+	verifyImageCmd.MarkFlagRequired("file")
+	opts.remoteOpts.applyFlags(verifyImageCmd.Flags())
+	verifyImageCmd.Flags().BoolVar(&opts.ociLayout, "oci-layout", false, "Set the reference as an OCI layout directory in the format of path[:tag|@digest]")
+	verifyImageCmd.Flags().StringVar(&opts.platform, "platform", "", "Platform of a multi-platform image in the format of os/arch[/variant], defaults to linux and the architecture of the host")
+	verifyImageCmd.Flags().StringVar(&opts.format, "format", "text", "Output format, text or json")
+	verifyImageCmd.Flags().BoolVar(&opts.ignoreExtra, "ignore-extra", false, "Ignore the files of the image that are not files of the SBOM")
+	verifyImageCmd.Flags().BoolVar(&opts.listFiles, "list-files", false, "List the files added and deleted by each layer of the image")
+
+	return verifyImageCmd
+}
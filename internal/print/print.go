@@ -1,8 +1,12 @@
 package print
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	obom "github.com/Azure/obom/pkg"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -43,3 +47,135 @@ func PrintSBOMSummary(sbomDoc *obom.SPDXDocument, desc *ocispec.Descriptor) {
 	fmt.Printf("Digest:                %s\n", desc.Digest)
 	fmt.Println(strings.Repeat("=", 80))
 }
+
+// sbomSummaryJSON is the JSON form of the SPDX summary
+type sbomSummaryJSON struct {
+	DocumentName      string   `json:"documentName"`
+	DocumentNamespace string   `json:"documentNamespace"`
+	SPDXVersion       string   `json:"spdxVersion"`
+	Created           string   `json:"created,omitempty"`
+	Creators          []string `json:"creators,omitempty"`
+	Packages          int      `json:"packages"`
+	Files             int      `json:"files"`
+	Digest            string   `json:"digest"`
+}
+
+// PrintSBOMSummaryJSON prints the SPDX summary from the SBOM as JSON
+func PrintSBOMSummaryJSON(sbomDoc *obom.SPDXDocument, desc *ocispec.Descriptor) error {
+	doc := sbomDoc.Document
+	summary := sbomSummaryJSON{
+		DocumentName:      doc.DocumentName,
+		DocumentNamespace: doc.DocumentNamespace,
+		SPDXVersion:       sbomDoc.Version,
+		Packages:          len(doc.Packages),
+		Files:             len(doc.Files),
+		Digest:            desc.Digest.String(),
+	}
+	if doc.CreationInfo != nil {
+		summary.Created = doc.CreationInfo.Created
+		for _, creator := range doc.CreationInfo.Creators {
+			summary.Creators = append(summary.Creators, creator.Creator)
+		}
+	}
+
+	summaryBytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(summaryBytes))
+	return nil
+}
+
+// PrintCatalogMatches prints the catalog matches as a table
+func PrintCatalogMatches(matches []obom.CatalogMatch) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REFERENCE\tDIGEST\tNAME\tVERSION\tLICENSE\tPURL")
+	for _, match := range matches {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", match.Reference, match.Digest, match.Package.Name, match.Package.Version, match.Package.License, match.Package.PURL)
+	}
+	writer.Flush()
+}
+
+// PrintCatalogEntries prints the SBOMs of the catalog as a table
+func PrintCatalogEntries(entries []obom.CatalogEntry) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "REFERENCE\tDIGEST\tDOCUMENT\tPACKAGES\tINDEXED")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\n", entry.Reference, entry.Digest, entry.DocumentName, len(entry.Packages), entry.IndexedAt.Format(time.RFC3339))
+	}
+	writer.Flush()
+}
+
+// PrintJSON prints the value as indented JSON
+func PrintJSON(v any) error {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
+// PrintFileVerificationReport prints the discrepancies between the files of the SBOM and the files found, and the
+// recomputed verification codes of the packages
+func PrintFileVerificationReport(report *obom.FileVerificationReport) {
+	for _, file := range report.Missing {
+		fmt.Printf("Missing %s\n", file)
+	}
+	for _, file := range report.Extra {
+		fmt.Printf("Extra %s\n", file)
+	}
+	for _, mismatch := range report.Mismatches {
+		fmt.Printf("Mismatch %s %s expected %s, got %s\n", mismatch.File, mismatch.Algorithm, mismatch.Expected, mismatch.Actual)
+	}
+	for _, pkg := range report.Packages {
+		switch {
+		case pkg.Verified:
+			fmt.Printf("Verified package %s %s verification code %s of %d files\n", pkg.SPDXID, pkg.Name, pkg.Actual, pkg.Files)
+		case len(pkg.Missing) > 0:
+			fmt.Printf("Unverified package %s %s, %d files missing\n", pkg.SPDXID, pkg.Name, len(pkg.Missing))
+		case pkg.Actual == "":
+			fmt.Printf("Unverified package %s %s, no files of the package in the SBOM\n", pkg.SPDXID, pkg.Name)
+		default:
+			fmt.Printf("Mismatch package %s %s verification code expected %s, got %s\n", pkg.SPDXID, pkg.Name, pkg.Expected, pkg.Actual)
+		}
+	}
+	fmt.Printf("%d files matched, %d missing, %d extra, %d checksum mismatches\n", report.Matched, len(report.Missing), len(report.Extra), len(report.Mismatches))
+}
+
+func PrintImageVerificationReport(report *obom.ImageVerificationReport) {
+	fmt.Printf("Image %s %s\n", report.Reference, report.Digest)
+	for _, layer := range report.Layers {
+		fmt.Printf("Layer %s %s\n", layer.Digest, layer.MediaType)
+		for _, file := range layer.Files {
+			fmt.Printf("  %s\n", file)
+		}
+		for _, file := range layer.Deleted {
+			fmt.Printf("  deleted %s\n", file)
+		}
+	}
+	for _, source := range report.Sources {
+		fmt.Printf("Packages read from %s\n", source)
+	}
+	for _, database := range report.Unsupported {
+		fmt.Printf("Unsupported package database %s\n", database)
+	}
+	for _, parseError := range report.ParseErrors {
+		fmt.Printf("Unreadable package database %s: %s\n", parseError.File, parseError.Error)
+	}
+
+	PrintFileVerificationReport(report.Files)
+
+	packages := report.Packages
+	for _, pkg := range packages.VersionMismatches {
+		fmt.Printf("Version mismatch %s %s %s expected %s, installed %s\n", pkg.SPDXID, pkg.Type, pkg.Name, pkg.Version, strings.Join(pkg.Installed, ", "))
+	}
+	for _, pkg := range packages.NotInstalled {
+		fmt.Printf("Not installed %s %s %s %s\n", pkg.SPDXID, pkg.Type, pkg.Name, pkg.Version)
+	}
+	for _, pkg := range packages.Unlisted {
+		fmt.Printf("Unlisted %s %s %s from %s\n", pkg.Type, pkg.Name, pkg.Version, pkg.Source)
+	}
+	fmt.Printf("%d packages installed, %.1f%% covered, %d matched, %d version mismatches, %d not installed, %d unlisted, %d unchecked\n",
+		packages.Installed, packages.Coverage, len(packages.Matched), len(packages.VersionMismatches), len(packages.NotInstalled), len(packages.Unlisted), packages.Unchecked)
+}
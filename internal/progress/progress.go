@@ -0,0 +1,133 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	obom "github.com/Azure/obom/pkg"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	barWidth = 30
+	// refreshInterval throttles the redraws of the status line on transferred bytes
+	refreshInterval = 100 * time.Millisecond
+)
+
+// Bar renders the progress events of a push or a copy on a terminal, as a line per copied, skipped or tagged
+// descriptor above a status line with the bytes transferred of the descriptors in progress
+type Bar struct {
+	mu       sync.Mutex
+	out      io.Writer
+	action   string
+	active   map[digest.Digest]*obom.ProgressEvent
+	rendered bool
+	redrawn  time.Time
+}
+
+// NewBar returns the bar rendering to the terminal, with the action, such as Pushed or Copied, prefixing the lines of
+// the copied descriptors
+func NewBar(out io.Writer, action string) *Bar {
+	return &Bar{
+		out:    out,
+		action: action,
+		active: make(map[digest.Digest]*obom.ProgressEvent),
+	}
+}
+
+// Update renders the progress event, it is safe for concurrent use as an obom.ProgressFunc
+func (b *Bar) Update(event obom.ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	desc := event.Descriptor
+	switch event.Type {
+	case obom.PROGRESS_BLOB_STARTED:
+		b.active[desc.Digest] = &event
+	case obom.PROGRESS_BLOB_TRANSFERRED:
+		active, ok := b.active[desc.Digest]
+		if !ok {
+			return
+		}
+		active.BytesTransferred = event.BytesTransferred
+		if time.Since(b.redrawn) < refreshInterval {
+			return
+		}
+	case obom.PROGRESS_BLOB_DONE:
+		delete(b.active, desc.Digest)
+		b.printLine(fmt.Sprintf("%-7s %s %s %s", b.action, shortDigest(desc.Digest), formatBytes(desc.Size), desc.MediaType))
+	case obom.PROGRESS_BLOB_SKIPPED:
+		b.printLine(fmt.Sprintf("%-7s %s %s %s", "Exists", shortDigest(desc.Digest), formatBytes(desc.Size), desc.MediaType))
+	case obom.PROGRESS_MANIFEST_TAGGED:
+		b.printLine(fmt.Sprintf("%-7s %s %s", "Tagged", event.Reference, desc.Digest))
+	}
+	b.render()
+}
+
+// Close clears the status line
+func (b *Bar) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clear()
+}
+
+// printLine prints the line above the status line
+func (b *Bar) printLine(line string) {
+	b.clear()
+	fmt.Fprintln(b.out, line)
+}
+
+// render redraws the status line with the bytes transferred of the descriptors in progress
+func (b *Bar) render() {
+	b.clear()
+	b.redrawn = time.Now()
+	if len(b.active) == 0 {
+		return
+	}
+
+	var transferred, total int64
+	for _, event := range b.active {
+		transferred += event.BytesTransferred
+		total += event.Descriptor.Size
+	}
+	filled := barWidth
+	if total > 0 {
+		filled = int(transferred * barWidth / total)
+	}
+	fmt.Fprintf(b.out, "[%s%s] %s/%s, %d in progress", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), formatBytes(transferred), formatBytes(total), len(b.active))
+	b.rendered = true
+}
+
+// clear erases the status line
+func (b *Bar) clear() {
+	if b.rendered {
+		fmt.Fprint(b.out, "\r\033[K")
+		b.rendered = false
+	}
+}
+
+// shortDigest returns the algorithm and the first 12 characters of the encoded digest
+func shortDigest(d digest.Digest) string {
+	encoded := d.Encoded()
+	if len(encoded) > 12 {
+		encoded = encoded[:12]
+	}
+	return d.Algorithm().String() + ":" + encoded
+}
+
+// formatBytes formats the size in bytes with a binary unit
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
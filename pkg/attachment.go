@@ -0,0 +1,189 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// Attachment is an artifact attached to an SBOM as a referrer manifest with one or more layers
+type Attachment struct {
+	// ArtifactType is the artifactType of the referrer manifest
+	ArtifactType string `json:"artifactType" yaml:"artifactType"`
+	// Annotations are the annotations of the referrer manifest
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Layers are the files of the artifact
+	Layers []AttachmentLayer `json:"layers" yaml:"layers"`
+}
+
+// AttachmentLayer is a file of an Attachment
+type AttachmentLayer struct {
+	// Path is the path of the file
+	Path string `json:"path" yaml:"path"`
+	// MediaType is the media type of the layer, defaulting to the artifactType of the attachment
+	MediaType string `json:"mediaType,omitempty" yaml:"mediaType,omitempty"`
+	// Annotations are the annotations of the layer, next to the title annotation with the file name
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// AttachmentFile is the format of the attachment files of obom push --attach-file
+type AttachmentFile struct {
+	Attachments []Attachment `json:"attachments" yaml:"attachments"`
+}
+
+// LoadAttachmentFile loads the attachments of a JSON or YAML attachment file. Relative paths of the layers are
+// resolved against the directory of the attachment file.
+func LoadAttachmentFile(filename string) ([]Attachment, error) {
+	fileBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading attachment file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML
+	var file AttachmentFile
+	if err := yaml.Unmarshal(fileBytes, &file); err != nil {
+		return nil, fmt.Errorf("error parsing attachment file %s: %w", filename, err)
+	}
+
+	baseDir := filepath.Dir(filename)
+	for i := range file.Attachments {
+		attachment := &file.Attachments[i]
+		if err := attachment.validate(); err != nil {
+			return nil, fmt.Errorf("error in attachment %d of %s: %w", i, filename, err)
+		}
+		for j := range attachment.Layers {
+			attachment.Layers[j].Path = resolveBatchPath(baseDir, attachment.Layers[j].Path)
+		}
+	}
+	return file.Attachments, nil
+}
+
+// validate checks that the attachment has an artifactType and layers with paths
+func (a Attachment) validate() error {
+	if a.ArtifactType == "" {
+		return fmt.Errorf("missing artifactType")
+	}
+	if len(a.Layers) == 0 {
+		return fmt.Errorf("no layers for artifactType %s", a.ArtifactType)
+	}
+	for i, layer := range a.Layers {
+		if layer.Path == "" {
+			return fmt.Errorf("missing path of layer %d of artifactType %s", i, a.ArtifactType)
+		}
+	}
+	return nil
+}
+
+// getAttachments returns the attachments of the options, with an attachment per path of AttachArtifacts in the
+// order of their artifactType followed by Attachments
+func (opts PushOptions) getAttachments() []Attachment {
+	artifactTypes := make([]string, 0, len(opts.AttachArtifacts))
+	for artifactType := range opts.AttachArtifacts {
+		artifactTypes = append(artifactTypes, artifactType)
+	}
+	sort.Strings(artifactTypes)
+
+	var attachments []Attachment
+	for _, artifactType := range artifactTypes {
+		for _, path := range opts.AttachArtifacts[artifactType] {
+			attachments = append(attachments, Attachment{
+				ArtifactType: artifactType,
+				Layers:       []AttachmentLayer{{Path: path}},
+			})
+		}
+	}
+	return append(attachments, opts.Attachments...)
+}
+
+// AttachToSBOM attaches the artifacts to the SBOM manifest tagged or identified by reference in the target, such as
+// an SBOM published earlier, without pushing the SBOM again. It returns the descriptor of the SBOM manifest and the
+// descriptors of the referrer manifests.
+func AttachToSBOM(ctx context.Context, target oras.Target, reference string, attachments []Attachment) (*v1.Descriptor, []v1.Descriptor, error) {
+	manifestDesc, manifest, err := FetchManifest(ctx, target, reference)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !HasSBOMLayer(manifest) {
+		return nil, nil, fmt.Errorf("%s is not an SBOM manifest", reference)
+	}
+
+	// the subject only identifies the manifest, without the annotations a target may resolve it with
+	subject := v1.Descriptor{
+		MediaType: manifestDesc.MediaType,
+		Digest:    manifestDesc.Digest,
+		Size:      manifestDesc.Size,
+	}
+
+	referrers := make([]v1.Descriptor, 0, len(attachments))
+	for _, attachment := range attachments {
+		referrer, err := attach(ctx, &subject, attachment, "", target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error attaching artifact: %w", err)
+		}
+		referrers = append(referrers, referrer)
+	}
+	return &subject, referrers, nil
+}
+
+// attach pushes the layers of the attachment to the target and packs its referrer manifest with the subject,
+// pinning the creation time of the manifest to created when set
+func attach(ctx context.Context, subject *v1.Descriptor, attachment Attachment, created string, target content.Pusher) (v1.Descriptor, error) {
+	if err := attachment.validate(); err != nil {
+		return v1.Descriptor{}, err
+	}
+
+	layers := make([]v1.Descriptor, 0, len(attachment.Layers))
+	for _, layer := range attachment.Layers {
+		mediaType := layer.MediaType
+		if mediaType == "" {
+			mediaType = attachment.ArtifactType
+		}
+		layerDesc, layerBytes, err := LoadArtifactFromFile(layer.Path, mediaType)
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		for k, v := range layer.Annotations {
+			if layerDesc.Annotations == nil {
+				layerDesc.Annotations = make(map[string]string)
+			}
+			layerDesc.Annotations[k] = v
+		}
+		if err := pushIfMissing(ctx, target, *layerDesc, layerBytes); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("error pushing artifact %s: %w", layer.Path, err)
+		}
+		layers = append(layers, *layerDesc)
+	}
+
+	var annotations map[string]string
+	if created != "" || len(attachment.Annotations) > 0 {
+		annotations = make(map[string]string)
+		if created != "" {
+			annotations[v1.AnnotationCreated] = created
+		}
+		for k, v := range attachment.Annotations {
+			annotations[k] = v
+		}
+	}
+
+	return packReferrer(ctx, target, subject, attachment.ArtifactType, layers, annotations)
+}
+
+// packReferrer packs the referrer manifest of the layers with the subject and pushes it to the target
+func packReferrer(ctx context.Context, target content.Pusher, subject *v1.Descriptor, artifactType string, layers []v1.Descriptor, annotations map[string]string) (v1.Descriptor, error) {
+	referrer, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Subject:             subject,
+		Layers:              layers,
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("error packing artifact manifest: %w", err)
+	}
+	return referrer, nil
+}
@@ -0,0 +1,144 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestLoadAttachmentFile(t *testing.T) {
+	attachments, err := LoadAttachmentFile("../examples/attachments.example.yaml")
+	if err != nil {
+		t.Fatalf("expected no error from LoadAttachmentFile, got: %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got: %d", len(attachments))
+	}
+
+	attachment := attachments[0]
+	if attachment.ArtifactType != "application/vnd.example.scan-report" {
+		t.Errorf("expected artifactType application/vnd.example.scan-report, got: %s", attachment.ArtifactType)
+	}
+	if len(attachment.Layers) != 2 {
+		t.Fatalf("expected 2 layers, got: %d", len(attachment.Layers))
+	}
+	expectedPath := filepath.Join("../examples", "artifact.example.json")
+	if attachment.Layers[0].Path != expectedPath {
+		t.Errorf("expected the layer path to be resolved to %s, got: %s", expectedPath, attachment.Layers[0].Path)
+	}
+}
+
+func TestLoadAttachmentFile_MissingArtifactType(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "attachments.yaml")
+	if err := os.WriteFile(filename, []byte("attachments:\n  - layers:\n      - path: LICENSE\n"), 0644); err != nil {
+		t.Fatalf("error writing attachment file: %v", err)
+	}
+
+	if _, err := LoadAttachmentFile(filename); err == nil {
+		t.Fatalf("expected error for an attachment without artifactType, got no error")
+	}
+}
+
+func TestPushSBOMWithOptions_Attachments(t *testing.T) {
+	ctx := context.Background()
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	attachments, err := LoadAttachmentFile("../examples/attachments.example.yaml")
+	if err != nil {
+		t.Fatalf("expected no error from LoadAttachmentFile, got: %v", err)
+	}
+
+	dest := memory.New()
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", dest, PushOptions{Attachments: attachments})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	referrers, err := registry.Referrers(ctx, dest, result.Descriptor, "application/vnd.example.scan-report")
+	if err != nil {
+		t.Fatalf("error getting referrers: %v", err)
+	}
+	if len(referrers) != 1 {
+		t.Fatalf("expected 1 referrer, got: %d", len(referrers))
+	}
+
+	manifestBytes, err := content.FetchAll(ctx, dest, referrers[0])
+	if err != nil {
+		t.Fatalf("error fetching referrer manifest: %v", err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		t.Fatalf("error unmarshaling referrer manifest: %v", err)
+	}
+
+	if manifest.Annotations["org.example.scanner"] != "example-scanner" {
+		t.Errorf("expected manifest annotation org.example.scanner to be example-scanner, got: %v", manifest.Annotations)
+	}
+	if len(manifest.Layers) != 2 {
+		t.Fatalf("expected 2 layers, got: %d", len(manifest.Layers))
+	}
+	if manifest.Layers[0].MediaType != "application/json" || manifest.Layers[1].MediaType != "application/yaml" {
+		t.Errorf("expected layer media types application/json and application/yaml, got: %s and %s", manifest.Layers[0].MediaType, manifest.Layers[1].MediaType)
+	}
+	if manifest.Layers[0].Annotations["org.example.format"] != "json" {
+		t.Errorf("expected layer annotation org.example.format to be json, got: %v", manifest.Layers[0].Annotations)
+	}
+	if manifest.Layers[0].Annotations[v1.AnnotationTitle] == "" {
+		t.Errorf("expected layer to keep its title annotation")
+	}
+}
+
+func TestAttachToSBOM(t *testing.T) {
+	ctx := context.Background()
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+
+	dest := memory.New()
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", dest, PushOptions{})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	attachments := []Attachment{{
+		ArtifactType: "application/vnd.openvex+json",
+		Layers:       []AttachmentLayer{{Path: "../examples/artifact.example.json"}},
+	}}
+	subject, referrers, err := AttachToSBOM(ctx, dest, "v1", attachments)
+	if err != nil {
+		t.Fatalf("expected no error from AttachToSBOM, got: %v", err)
+	}
+	if subject.Digest != result.Descriptor.Digest {
+		t.Errorf("expected subject %s, got: %s", result.Descriptor.Digest, subject.Digest)
+	}
+	if len(referrers) != 1 {
+		t.Fatalf("expected 1 referrer, got: %d", len(referrers))
+	}
+
+	found, err := registry.Referrers(ctx, dest, result.Descriptor, "application/vnd.openvex+json")
+	if err != nil {
+		t.Fatalf("error getting referrers: %v", err)
+	}
+	if len(found) != 1 || found[0].Digest != referrers[0].Digest {
+		t.Errorf("expected the attached artifact to be a referrer of the SBOM, got: %v", found)
+	}
+
+	// the manifest of the attached artifact is not an SBOM
+	if err := dest.Tag(ctx, referrers[0], "vex"); err != nil {
+		t.Fatalf("error tagging referrer: %v", err)
+	}
+	if _, _, err := AttachToSBOM(ctx, dest, "vex", attachments); err == nil {
+		t.Errorf("expected error attaching to a manifest that is not an SBOM, got no error")
+	}
+}
@@ -0,0 +1,319 @@
+package obom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+const (
+	DEFAULT_BATCH_CONCURRENCY = 5
+	DEFAULT_BATCH_RETRIES     = 3
+	DEFAULT_BATCH_RETRY_DELAY = time.Second
+)
+
+// BatchFile is the format of the batch file consumed by PushBatch, in YAML or JSON
+type BatchFile struct {
+	Items []BatchItem `json:"items" yaml:"items"`
+}
+
+// BatchItem describes a single SBOM to push as part of a batch
+type BatchItem struct {
+	// File is the path to the SPDX SBOM file
+	File string `json:"file" yaml:"file"`
+	// Reference is the registry reference to push the SBOM to
+	Reference string `json:"reference" yaml:"reference"`
+	// Annotations are additional manifest annotations, merged over the SBOM annotations
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Attach maps an artifactType to the paths of the artifacts to attach to the SBOM
+	Attach map[string][]string `json:"attach,omitempty" yaml:"attach,omitempty"`
+}
+
+// BatchResult is the outcome of pushing a single BatchItem
+type BatchResult struct {
+	File      string `json:"file"`
+	Reference string `json:"reference"`
+	Digest    string `json:"digest,omitempty"`
+	Unchanged bool   `json:"unchanged,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Attempts  int    `json:"attempts"`
+}
+
+// BatchOptions controls how PushBatch pushes the items
+type BatchOptions struct {
+	// Concurrency is the maximum number of SBOMs pushed at the same time
+	Concurrency int
+	// Retries is the number of times a failed push is retried
+	Retries int
+	// RetryDelay is the delay before the first retry, doubled on every further retry
+	RetryDelay time.Duration
+	// Strict enables strict SPDX parsing
+	Strict bool
+	// PushSummary pushes the summary blob along with every SBOM
+	PushSummary bool
+	// Reproducible derives the manifest creation time from the SBOM, see PushOptions
+	Reproducible bool
+	// NoDefaultAnnotations leaves out the standard OCI annotations derived from the SBOM, see GetDefaultAnnotations
+	NoDefaultAnnotations bool
+	// AnnotationTemplates are annotations rendered for every SBOM, see RenderAnnotations. They are overridden by the
+	// annotations of the items.
+	AnnotationTemplates map[string]string
+}
+
+// TargetResolver returns the target to push the given reference to
+type TargetResolver func(reference string) (oras.Target, error)
+
+var tagInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// sbomExtensions are the extensions of SBOM files removed from the file names to derive their tags
+var sbomExtensions = []string{".spdx.json", ".spdx", ".json"}
+
+// LoadBatchFile reads the batch items from a YAML or JSON batch file.
+// Relative file and attachment paths are resolved against the directory of the batch file.
+func LoadBatchFile(filename string) ([]BatchItem, error) {
+	batchBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading batch file: %w", err)
+	}
+
+	// YAML is a superset of JSON so both formats are handled by the YAML decoder
+	var batch BatchFile
+	if err := yaml.Unmarshal(batchBytes, &batch); err != nil {
+		return nil, fmt.Errorf("error parsing batch file: %w", err)
+	}
+
+	baseDir := filepath.Dir(filename)
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.File == "" || item.Reference == "" {
+			return nil, fmt.Errorf("batch item %d is missing the file or reference field", i)
+		}
+		item.File = resolveBatchPath(baseDir, item.File)
+		for artifactType, paths := range item.Attach {
+			for j, path := range paths {
+				item.Attach[artifactType][j] = resolveBatchPath(baseDir, path)
+			}
+		}
+	}
+
+	if err := checkDuplicateReferences(batch.Items); err != nil {
+		return nil, err
+	}
+	return batch.Items, nil
+}
+
+// BatchItemsFromGlob returns a batch item for every file matching the glob pattern, or every file in the directory
+// if pattern is a directory. Each SBOM is pushed to the repository with a tag derived from its file name without its
+// SBOM and compression extensions. It fails when the tags of two files are the same.
+func BatchItemsFromGlob(pattern string, repository string) ([]BatchItem, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*")
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error matching files: %w", err)
+	}
+
+	var items []BatchItem
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		items = append(items, BatchItem{
+			File:      match,
+			Reference: fmt.Sprintf("%s:%s", repository, tagFromFilename(match)),
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no files found matching %s", pattern)
+	}
+	if err := checkDuplicateReferences(items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// checkDuplicateReferences fails when two batch items are pushed to the same reference, or share one of the tags of
+// a multi-tag reference, as one would overwrite the other
+func checkDuplicateReferences(items []BatchItem) error {
+	indexes := make(map[string]int)
+	for i, item := range items {
+		references := []string{item.Reference}
+		if ref, tags, err := ParsePushReference(item.Reference); err == nil && len(tags) > 0 {
+			references = references[:0]
+			for _, tag := range tags {
+				references = append(references, ref.Registry+"/"+ref.Repository+":"+tag)
+			}
+		}
+		for _, reference := range references {
+			if j, ok := indexes[reference]; ok && j != i {
+				return fmt.Errorf("%s and %s are both pushed to %s", items[j].File, item.File, reference)
+			}
+			indexes[reference] = i
+		}
+	}
+	return nil
+}
+
+// PushBatch pushes every batch item to the target returned by the resolver, with at most opts.Concurrency pushes
+// in flight. Failed pushes are retried with exponential backoff. It returns one result per item in the order of the items.
+func PushBatch(ctx context.Context, items []BatchItem, resolve TargetResolver, opts BatchOptions) []BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DEFAULT_BATCH_CONCURRENCY
+	}
+
+	// the git information is the same for every SBOM, so it is read once
+	var gitInfo GitInfo
+	if UsesGitInfo(opts.AnnotationTemplates) {
+		gitInfo = GetGitInfo(".")
+	}
+
+	results := make([]BatchResult, len(items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = pushBatchItem(ctx, items[i], resolve, opts, gitInfo)
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func pushBatchItem(ctx context.Context, item BatchItem, resolve TargetResolver, opts BatchOptions, gitInfo GitInfo) BatchResult {
+	result := BatchResult{File: item.File, Reference: item.Reference}
+
+	sbom, err := LoadSBOM(item.File, opts.Strict)
+	if err != nil {
+		result.Error = fmt.Sprintf("error loading SBOM: %v", err)
+		return result
+	}
+
+	annotations, err := GetAnnotations(sbom.SPDXDocument)
+	if err != nil {
+		result.Error = fmt.Sprintf("error getting annotations: %v", err)
+		return result
+	}
+	if !opts.NoDefaultAnnotations {
+		for k, v := range GetDefaultAnnotations(sbom.Document) {
+			annotations[k] = v
+		}
+	}
+	if len(opts.AnnotationTemplates) > 0 {
+		data := NewAnnotationTemplateData(sbom.Document, ".", false)
+		data.Git = gitInfo
+		rendered, err := RenderAnnotations(opts.AnnotationTemplates, data)
+		if err != nil {
+			result.Error = fmt.Sprintf("error rendering annotations: %v", err)
+			return result
+		}
+		for k, v := range rendered {
+			annotations[k] = v
+		}
+	}
+	for k, v := range item.Annotations {
+		annotations[k] = v
+	}
+
+	target, err := resolve(item.Reference)
+	if err != nil {
+		result.Error = fmt.Sprintf("error getting target: %v", err)
+		return result
+	}
+
+	delay := opts.RetryDelay
+	for {
+		result.Attempts++
+		pushResult, err := PushSBOMArtifact(ctx, sbom, item.Reference, target, PushOptions{
+			Annotations:     annotations,
+			PushSummary:     opts.PushSummary,
+			AttachArtifacts: item.Attach,
+			Reproducible:    opts.Reproducible,
+		})
+		if err == nil {
+			result.Digest = pushResult.Descriptor.Digest.String()
+			result.Unchanged = pushResult.Unchanged
+			result.Error = ""
+			return result
+		}
+		result.Error = fmt.Sprintf("error pushing SBOM: %v", err)
+
+		if result.Attempts > opts.Retries || !isTransientError(err) {
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			result.Error = fmt.Sprintf("error pushing SBOM: %v", ctx.Err())
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isTransientError reports whether a failed push may succeed when retried: registry errors with a 5xx or 429 status,
+// network timeouts and expired deadlines. Other errors, such as 401, 403 and 404 responses, invalid references and
+// digest mismatches, fail the push right away.
+func isTransientError(err error) bool {
+	var errResp *errcode.ErrorResponse
+	if errors.As(err, &errResp) {
+		return errResp.StatusCode == http.StatusTooManyRequests || errResp.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func resolveBatchPath(baseDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// tagFromFilename derives a valid OCI tag from the base name of the file without its SBOM and compression extensions
+func tagFromFilename(filename string) string {
+	name := trimCompressionExtension(filepath.Base(filename))
+	for _, ext := range sbomExtensions {
+		if trimmed, found := strings.CutSuffix(name, ext); found {
+			name = trimmed
+			break
+		}
+	}
+	tag := strings.TrimLeft(tagInvalidChars.ReplaceAllString(name, "-"), ".-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	return tag
+}
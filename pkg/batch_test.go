@@ -0,0 +1,304 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// flakyTarget fails the first failures tag operations with the status code, 503 by default, to simulate registry errors
+type flakyTarget struct {
+	*memory.Store
+	mu         sync.Mutex
+	failures   int
+	statusCode int
+}
+
+func (f *flakyTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		statusCode := f.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusServiceUnavailable
+		}
+		return &errcode.ErrorResponse{Method: http.MethodPut, StatusCode: statusCode}
+	}
+	return f.Store.Tag(ctx, desc, reference)
+}
+
+func TestLoadBatchFile(t *testing.T) {
+	dir := t.TempDir()
+	batch := `items:
+  - file: sbom.spdx.json
+    reference: localhost:5000/spdx:v1
+    annotations:
+      key1: value1
+    attach:
+      application/json:
+        - artifact.json
+  - file: /abs/sbom.spdx.json
+    reference: localhost:5000/spdx:v2
+`
+	batchFile := filepath.Join(dir, "batch.yaml")
+	if err := os.WriteFile(batchFile, []byte(batch), 0644); err != nil {
+		t.Fatalf("error writing batch file: %v", err)
+	}
+
+	items, err := LoadBatchFile(batchFile)
+	if err != nil {
+		t.Fatalf("expected no error from LoadBatchFile, got: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got: %d", len(items))
+	}
+	if items[0].File != filepath.Join(dir, "sbom.spdx.json") {
+		t.Errorf("expected relative file to be resolved against the batch file directory, got: %s", items[0].File)
+	}
+	if items[0].Attach["application/json"][0] != filepath.Join(dir, "artifact.json") {
+		t.Errorf("expected relative attachment to be resolved against the batch file directory, got: %s", items[0].Attach["application/json"][0])
+	}
+	if items[0].Annotations["key1"] != "value1" {
+		t.Errorf("expected annotation key1 to be 'value1', got: %s", items[0].Annotations["key1"])
+	}
+	if items[1].File != "/abs/sbom.spdx.json" {
+		t.Errorf("expected absolute file to be kept, got: %s", items[1].File)
+	}
+}
+
+func TestLoadBatchFile_MissingReference(t *testing.T) {
+	batchFile := filepath.Join(t.TempDir(), "batch.json")
+	if err := os.WriteFile(batchFile, []byte(`{"items": [{"file": "sbom.spdx.json"}]}`), 0644); err != nil {
+		t.Fatalf("error writing batch file: %v", err)
+	}
+
+	if _, err := LoadBatchFile(batchFile); err == nil {
+		t.Fatalf("expected error for batch item without reference, got no error")
+	}
+}
+
+func TestBatchItemsFromGlob(t *testing.T) {
+	items, err := BatchItemsFromGlob("../examples/*.spdx.json", "localhost:5000/sboms")
+	if err != nil {
+		t.Fatalf("expected no error from BatchItemsFromGlob, got: %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got: %d", len(items))
+	}
+	if items[0].Reference != "localhost:5000/sboms:SPDXJSONExample-v2.3" {
+		t.Errorf("expected reference to be derived from the file name, got: %s", items[0].Reference)
+	}
+
+	// the tags of app.spdx.json and app.json are the same
+	dir := t.TempDir()
+	for _, name := range []string{"app.spdx.json", "app.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("error writing SBOM: %v", err)
+		}
+	}
+	if _, err := BatchItemsFromGlob(dir, "localhost:5000/sboms"); err == nil {
+		t.Errorf("expected an error from BatchItemsFromGlob for two files with the same tag")
+	}
+}
+
+func TestTagFromFilename(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"app-1.2.3.spdx.json":    "app-1.2.3",
+		"app-1.2.3.spdx.json.gz": "app-1.2.3",
+		"app.v2.json.zst":        "app.v2",
+		"app.spdx":               "app",
+		"app+linux.spdx.json":    "app-linux",
+		".spdx.json":             "latest",
+	} {
+		if tag := tagFromFilename(filename); tag != expected {
+			t.Errorf("expected the tag of %s to be %s, got: %s", filename, expected, tag)
+		}
+	}
+}
+
+func TestPushBatch(t *testing.T) {
+	ctx := context.Background()
+	dest := memory.New()
+	items := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v1", Annotations: map[string]string{"key1": "value1"}},
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v2", Attach: map[string][]string{"application/json": {"../examples/artifact.example.json"}}},
+		{File: "../examples/missing.spdx.json", Reference: "localhost:5000/spdx:v3"},
+	}
+
+	results := PushBatch(ctx, items, func(reference string) (oras.Target, error) {
+		return dest, nil
+	}, BatchOptions{Concurrency: 2, Strict: true})
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got: %d", len(results))
+	}
+
+	for i, tag := range []string{"v1", "v2"} {
+		if results[i].Error != "" {
+			t.Errorf("expected no error for item %d, got: %s", i, results[i].Error)
+		}
+		desc, err := dest.Resolve(ctx, tag)
+		if err != nil {
+			t.Fatalf("expected %s to be pushed, got: %v", tag, err)
+		}
+		if desc.Digest.String() != results[i].Digest {
+			t.Errorf("expected result digest to be %s, got: %s", desc.Digest, results[i].Digest)
+		}
+	}
+
+	if results[2].Error == "" || results[2].Digest != "" {
+		t.Errorf("expected an error and no digest for the missing file, got: %+v", results[2])
+	}
+}
+
+func TestPushBatch_AnnotationTemplates(t *testing.T) {
+	ctx := context.Background()
+	dest := memory.New()
+	items := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v1", Annotations: map[string]string{"key1": "{{ literal }}"}},
+	}
+
+	results := PushBatch(ctx, items, func(reference string) (oras.Target, error) {
+		return dest, nil
+	}, BatchOptions{Strict: true, AnnotationTemplates: map[string]string{
+		"org.example.package": "{{ .RootPackage.Name }}-{{ .RootPackage.Version }}",
+		"key1":                "{{ .RootPackage.Name }}",
+	}})
+	if results[0].Error != "" {
+		t.Fatalf("expected no error, got: %s", results[0].Error)
+	}
+
+	desc, err := dest.Resolve(ctx, "v1")
+	if err != nil {
+		t.Fatalf("expected v1 to be pushed, got: %v", err)
+	}
+	manifestBytes, err := content.FetchAll(ctx, dest, desc)
+	if err != nil {
+		t.Fatalf("expected no error fetching the manifest, got: %v", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		t.Fatalf("expected no error unmarshaling the manifest, got: %v", err)
+	}
+	if manifest.Annotations["org.example.package"] != "glibc-2.11.1" {
+		t.Errorf("expected the rendered template annotation 'glibc-2.11.1', got: '%s'", manifest.Annotations["org.example.package"])
+	}
+	// the annotations of the item are used as is and override the templates
+	if manifest.Annotations["key1"] != "{{ literal }}" {
+		t.Errorf("expected the literal item annotation '{{ literal }}', got: '%s'", manifest.Annotations["key1"])
+	}
+}
+
+func TestPushBatch_RetriesTransientFailures(t *testing.T) {
+	ctx := context.Background()
+	dest := &flakyTarget{Store: memory.New(), failures: 2}
+	items := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v1"},
+	}
+	resolve := func(reference string) (oras.Target, error) {
+		return dest, nil
+	}
+
+	results := PushBatch(ctx, items, resolve, BatchOptions{Retries: 2, Strict: true})
+	if results[0].Error != "" {
+		t.Fatalf("expected push to succeed after retries, got: %s", results[0].Error)
+	}
+	if results[0].Attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", results[0].Attempts)
+	}
+
+	dest = &flakyTarget{Store: memory.New(), failures: 2}
+	results = PushBatch(ctx, items, resolve, BatchOptions{Retries: 1, Strict: true})
+	if results[0].Error == "" {
+		t.Errorf("expected push to fail when retries are exhausted, got no error")
+	}
+	if results[0].Attempts != 2 {
+		t.Errorf("expected 2 attempts, got: %d", results[0].Attempts)
+	}
+}
+
+func TestPushBatch_FailsFastOnPermanentFailures(t *testing.T) {
+	ctx := context.Background()
+	items := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v1"},
+	}
+
+	for _, statusCode := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		dest := &flakyTarget{Store: memory.New(), failures: 1, statusCode: statusCode}
+		results := PushBatch(ctx, items, func(reference string) (oras.Target, error) {
+			return dest, nil
+		}, BatchOptions{Retries: 2, Strict: true})
+		if results[0].Error == "" || results[0].Attempts != 1 {
+			t.Errorf("expected a single failed attempt for status %d, got: %+v", statusCode, results[0])
+		}
+	}
+
+	// a digest mismatch is not retried either
+	digestItems := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+	}
+	results := PushBatch(ctx, digestItems, func(reference string) (oras.Target, error) {
+		return memory.New(), nil
+	}, BatchOptions{Retries: 2, Strict: true})
+	if results[0].Error == "" || results[0].Attempts != 1 {
+		t.Errorf("expected a single failed attempt for a digest mismatch, got: %+v", results[0])
+	}
+}
+
+func TestLoadBatchFile_DuplicateReference(t *testing.T) {
+	batchFile := filepath.Join(t.TempDir(), "batch.json")
+	batch := `{"items": [{"file": "a.spdx.json", "reference": "localhost:5000/spdx:v1"}, {"file": "b.spdx.json", "reference": "localhost:5000/spdx:v1"}]}`
+	if err := os.WriteFile(batchFile, []byte(batch), 0644); err != nil {
+		t.Fatalf("error writing batch file: %v", err)
+	}
+
+	if _, err := LoadBatchFile(batchFile); err == nil {
+		t.Fatalf("expected error for two items with the same reference, got no error")
+	}
+
+	// the tags of a multi-tag reference are checked one by one
+	batch = `{"items": [{"file": "a.spdx.json", "reference": "localhost:5000/spdx:v1,latest"}, {"file": "b.spdx.json", "reference": "localhost:5000/spdx:latest"}]}`
+	if err := os.WriteFile(batchFile, []byte(batch), 0644); err != nil {
+		t.Fatalf("error writing batch file: %v", err)
+	}
+	if _, err := LoadBatchFile(batchFile); err == nil {
+		t.Fatalf("expected error for two items sharing a tag, got no error")
+	}
+}
+
+func TestPushBatch_MultiTagReference(t *testing.T) {
+	ctx := context.Background()
+	dest := memory.New()
+	items := []BatchItem{
+		{File: "../examples/SPDXJSONExample-v2.3.spdx.json", Reference: "localhost:5000/spdx:v1,latest"},
+	}
+
+	results := PushBatch(ctx, items, func(reference string) (oras.Target, error) {
+		return dest, nil
+	}, BatchOptions{Strict: true})
+	if results[0].Error != "" {
+		t.Fatalf("expected no error, got: %s", results[0].Error)
+	}
+	for _, tag := range []string{"v1", "latest"} {
+		desc, err := dest.Resolve(ctx, tag)
+		if err != nil {
+			t.Fatalf("expected %s to be pushed, got: %v", tag, err)
+		}
+		if desc.Digest.String() != results[0].Digest {
+			t.Errorf("expected %s to be %s, got: %s", tag, results[0].Digest, desc.Digest)
+		}
+	}
+}
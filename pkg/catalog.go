@@ -0,0 +1,458 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	purl "github.com/package-url/packageurl-go"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	bolt "go.etcd.io/bbolt"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// CATALOG_FILENAME is the file name of the catalog in the obom cache directory
+	CATALOG_FILENAME = "catalog.db"
+	// catalogBucket is the bucket of the catalog entries keyed by reference
+	catalogBucket = "sboms"
+)
+
+// Catalog is a local searchable catalog of the packages of indexed SBOMs, stored in a bbolt database
+type Catalog struct {
+	db *bolt.DB
+}
+
+// CatalogEntry is an SBOM indexed in the catalog
+type CatalogEntry struct {
+	// Reference is the reference the SBOM was indexed from, or the absolute path of the SBOM file
+	Reference string `json:"reference"`
+	// Digest is the digest of the SBOM manifest, or of the SBOM file when indexed from a file
+	Digest       digest.Digest    `json:"digest"`
+	DocumentName string           `json:"documentName"`
+	IndexedAt    time.Time        `json:"indexedAt"`
+	Packages     []PackageSummary `json:"packages"`
+}
+
+// CatalogQuery are the criteria of a catalog search, a package matches when it matches all the criteria that are set
+type CatalogQuery struct {
+	// PURL matches the type, namespace and name of the package URL, and the version when the query has one
+	PURL string
+	// Name matches the package name, case insensitive
+	Name string
+	// VersionRange matches the package version against comma separated constraints such as >=2.0,<2.17
+	VersionRange string
+	// License matches the packages whose license expression contains the license, case insensitive
+	License string
+	// Supplier matches the packages whose supplier contains the supplier, case insensitive
+	Supplier string
+}
+
+// CatalogMatch is a package matching a catalog search along with the SBOM that contains it
+type CatalogMatch struct {
+	Reference string         `json:"reference"`
+	Digest    digest.Digest  `json:"digest"`
+	Package   PackageSummary `json:"package"`
+}
+
+// CatalogRepository is a repository whose tags can be crawled, such as a remote repository or an OCI layout
+type CatalogRepository interface {
+	oras.ReadOnlyTarget
+	registry.TagLister
+}
+
+// CatalogCrawlResult is the result of indexing the tags of a repository
+type CatalogCrawlResult struct {
+	// Indexed are the references of the indexed SBOMs
+	Indexed []string
+	// Skipped are the references of the tags that are not SBOMs
+	Skipped []string
+	// Removed are the references of the catalog entries whose tags no longer exist
+	Removed []string
+	// Errors are the errors of the tags that could not be indexed, keyed by reference
+	Errors map[string]error
+}
+
+// DefaultCatalogPath returns the path of the catalog in the user cache directory
+func DefaultCatalogPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting the user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "obom", CATALOG_FILENAME), nil
+}
+
+// OpenCatalog opens the catalog at the path, creating it and its directory if they do not exist
+func OpenCatalog(path string) (*Catalog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating catalog directory: %w", err)
+	}
+	// fail rather than wait forever when another obom process holds the catalog
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening catalog %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(catalogBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing catalog %s: %w", path, err)
+	}
+	return &Catalog{db: db}, nil
+}
+
+// Close closes the catalog
+func (c *Catalog) Close() error {
+	return c.db.Close()
+}
+
+// NewCatalogEntry returns the catalog entry of the SPDX document with its package summaries
+func NewCatalogEntry(reference string, dgst digest.Digest, sbom *v2_3.Document) (*CatalogEntry, error) {
+	packages, err := GetPackageSummaries(sbom)
+	if err != nil {
+		return nil, fmt.Errorf("error getting package summaries: %w", err)
+	}
+	return &CatalogEntry{
+		Reference:    reference,
+		Digest:       dgst,
+		DocumentName: sbom.DocumentName,
+		IndexedAt:    time.Now().UTC(),
+		Packages:     packages,
+	}, nil
+}
+
+// Add adds the entry to the catalog, replacing the entry previously indexed from the same reference
+func (c *Catalog) Add(entry *CatalogEntry) error {
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshaling catalog entry: %w", err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(catalogBucket)).Put([]byte(entry.Reference), entryBytes)
+	})
+}
+
+// AddFile indexes the SBOM file into the catalog under its absolute path
+func (c *Catalog) AddFile(filename string, strict bool) (*CatalogEntry, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path of %s: %w", filename, err)
+	}
+	sbom, err := LoadSBOM(path, strict)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := NewCatalogEntry(path, sbom.Descriptor.Digest, sbom.Document)
+	if err != nil {
+		return nil, err
+	}
+	return entry, c.Add(entry)
+}
+
+// AddReference fetches the SBOM tagged or identified by tagOrDigest from the source and indexes it into the catalog
+// under the full reference
+func (c *Catalog) AddReference(ctx context.Context, src oras.ReadOnlyTarget, tagOrDigest string, reference string, strict bool) (*CatalogEntry, error) {
+	result, err := FetchSBOMArtifact(ctx, src, tagOrDigest, strict)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := NewCatalogEntry(reference, result.Manifest.Digest, result.Document)
+	if err != nil {
+		return nil, err
+	}
+	return entry, c.Add(entry)
+}
+
+// CrawlRepository indexes the SBOMs of all the tags of the repository named repository, such as
+// registry.example.com/sboms/app, skipping the tags that are not SBOMs. The entries of the repository whose tags no
+// longer exist are removed, so that crawling again refreshes the catalog. A tag failing to be indexed does not stop
+// the crawl; its error is kept in the result.
+func (c *Catalog) CrawlRepository(ctx context.Context, repo CatalogRepository, repository string, strict bool) (*CatalogCrawlResult, error) {
+	tags, err := registry.Tags(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags of %s: %w", repository, err)
+	}
+
+	result := &CatalogCrawlResult{Errors: make(map[string]error)}
+	crawled := make(map[string]bool)
+	for _, tag := range tags {
+		reference := repository + ":" + tag
+		crawled[reference] = true
+
+		_, manifest, err := FetchManifest(ctx, repo, tag)
+		if err != nil {
+			result.Errors[reference] = err
+			continue
+		}
+		if !HasSBOMLayer(manifest) {
+			result.Skipped = append(result.Skipped, reference)
+			continue
+		}
+		if _, err := c.AddReference(ctx, repo, tag, reference, strict); err != nil {
+			result.Errors[reference] = err
+			continue
+		}
+		result.Indexed = append(result.Indexed, reference)
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Reference, repository+":") || crawled[entry.Reference] {
+			continue
+		}
+		if err := c.Remove(entry.Reference); err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, entry.Reference)
+	}
+
+	return result, nil
+}
+
+// Remove removes the entry indexed from the reference from the catalog
+func (c *Catalog) Remove(reference string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(catalogBucket))
+		if bucket.Get([]byte(reference)) == nil {
+			return fmt.Errorf("%s is not in the catalog", reference)
+		}
+		return bucket.Delete([]byte(reference))
+	})
+}
+
+// List returns the entries of the catalog ordered by reference, as bbolt keeps the keys sorted
+func (c *Catalog) List() ([]CatalogEntry, error) {
+	entries := []CatalogEntry{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(catalogBucket)).ForEach(func(key, value []byte) error {
+			var entry CatalogEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return fmt.Errorf("error unmarshaling catalog entry %s: %w", key, err)
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Search returns the packages of the catalog matching the query, ordered by the reference of their SBOM
+func (c *Catalog) Search(query CatalogQuery) ([]CatalogMatch, error) {
+	matcher, err := newPackageMatcher(query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []CatalogMatch{}
+	for _, entry := range entries {
+		for _, pkg := range entry.Packages {
+			if matcher.matches(pkg) {
+				matches = append(matches, CatalogMatch{
+					Reference: entry.Reference,
+					Digest:    entry.Digest,
+					Package:   pkg,
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// packageMatcher matches package summaries against a parsed catalog query
+type packageMatcher struct {
+	query       CatalogQuery
+	purl        *purl.PackageURL
+	constraints []versionConstraint
+}
+
+func newPackageMatcher(query CatalogQuery) (*packageMatcher, error) {
+	matcher := &packageMatcher{query: query}
+	if query.PURL != "" {
+		packageURL, err := purl.FromString(query.PURL)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing package url %s: %w", query.PURL, err)
+		}
+		matcher.purl = &packageURL
+	}
+	if query.VersionRange != "" {
+		constraints, err := parseVersionRange(query.VersionRange)
+		if err != nil {
+			return nil, err
+		}
+		matcher.constraints = constraints
+	}
+	return matcher, nil
+}
+
+func (m *packageMatcher) matches(pkg PackageSummary) bool {
+	if m.purl != nil {
+		packageURL, err := purl.FromString(pkg.PURL)
+		if err != nil || packageURL.Type != m.purl.Type || packageURL.Namespace != m.purl.Namespace || packageURL.Name != m.purl.Name {
+			return false
+		}
+		if m.purl.Version != "" && packageURL.Version != m.purl.Version {
+			return false
+		}
+	}
+	if m.query.Name != "" && !strings.EqualFold(pkg.Name, m.query.Name) {
+		return false
+	}
+	if m.query.License != "" && !strings.Contains(strings.ToLower(pkg.License), strings.ToLower(m.query.License)) {
+		return false
+	}
+	if m.query.Supplier != "" && !strings.Contains(strings.ToLower(pkg.Supplier), strings.ToLower(m.query.Supplier)) {
+		return false
+	}
+	for _, constraint := range m.constraints {
+		if !constraint.matches(pkg.Version) {
+			return false
+		}
+	}
+	return true
+}
+
+// versionConstraint is a comparison of a version range, such as <2.17
+type versionConstraint struct {
+	operator string
+	version  string
+}
+
+// versionOperators are the operators of the version constraints, the two character ones first so that they are
+// matched before their one character prefixes
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// parseVersionRange parses comma separated version constraints, such as >=2.0,<2.17. A version without an operator
+// matches that version only.
+func parseVersionRange(versionRange string) ([]versionConstraint, error) {
+	var constraints []versionConstraint
+	for _, part := range strings.Split(versionRange, ",") {
+		part = strings.TrimSpace(part)
+		constraint := versionConstraint{operator: "=", version: part}
+		for _, operator := range versionOperators {
+			if strings.HasPrefix(part, operator) {
+				constraint = versionConstraint{operator: operator, version: strings.TrimSpace(strings.TrimPrefix(part, operator))}
+				break
+			}
+		}
+		if constraint.version == "" {
+			return nil, fmt.Errorf("invalid version range %q: missing version in %q", versionRange, part)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, nil
+}
+
+func (c versionConstraint) matches(version string) bool {
+	if version == "" {
+		return false
+	}
+	cmp := compareVersions(version, c.version)
+	switch c.operator {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareVersions compares two versions segment by segment, numerically when both segments are numbers and
+// lexically otherwise. Missing segments are 0, so 2.17 == 2.17.0, and a segment starting with a letter is a
+// pre-release ranking below its release, so 2.0-beta9 < 2.0 < 2.0.1. A leading v is ignored.
+func compareVersions(a, b string) int {
+	segmentsA := splitVersion(a)
+	segmentsB := splitVersion(b)
+	for i := 0; i < len(segmentsA) || i < len(segmentsB); i++ {
+		if cmp := compareVersionSegments(versionSegment(segmentsA, i), versionSegment(segmentsB, i)); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareVersionSegments compares two segments of a version, where a number is higher than a pre-release
+func compareVersionSegments(a, b string) int {
+	numA, errA := strconv.ParseUint(a, 10, 64)
+	numB, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if numA < numB {
+			return -1
+		}
+		if numA > numB {
+			return 1
+		}
+		return 0
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// versionSegment returns the segment of the version at the index, or 0 when the version has fewer segments
+func versionSegment(segments []string, i int) string {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return "0"
+}
+
+// releaseQualifiers are the qualifiers of releases, such as 1.0.Final, which are not pre-releases and are ignored
+var releaseQualifiers = map[string]bool{"final": true, "ga": true, "release": true}
+
+// splitVersion splits the lower cased version into its segments, splitting the letters from the digits so that
+// rc10 is split into rc and 10
+func splitVersion(version string) []string {
+	version = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V"))
+	var segments []string
+	for _, field := range strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '-' || r == '+' || r == '_'
+	}) {
+		if releaseQualifiers[field] {
+			continue
+		}
+		start := 0
+		for i := 1; i < len(field); i++ {
+			if isDigit(field[i]) != isDigit(field[i-1]) {
+				segments = append(segments, field[start:i])
+				start = i
+			}
+		}
+		segments = append(segments, field[start:])
+	}
+	return segments
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
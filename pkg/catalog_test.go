@@ -0,0 +1,156 @@
+package obom
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"oras.land/oras-go/v2/content/oci"
+)
+
+func openTestCatalog(t *testing.T) *Catalog {
+	catalog, err := OpenCatalog(filepath.Join(t.TempDir(), "catalog", CATALOG_FILENAME))
+	if err != nil {
+		t.Fatalf("expected no error from OpenCatalog, got: %v", err)
+	}
+	t.Cleanup(func() { catalog.Close() })
+	return catalog
+}
+
+func TestCatalog_AddFileAndSearch(t *testing.T) {
+	catalog := openTestCatalog(t)
+
+	entry, err := catalog.AddFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from AddFile, got: %v", err)
+	}
+	if !filepath.IsAbs(entry.Reference) || entry.Digest == "" {
+		t.Errorf("expected the entry to have the absolute path and the digest of the file, got: %+v", entry)
+	}
+
+	tests := []struct {
+		name     string
+		query    CatalogQuery
+		expected []string
+	}{
+		{"purl without version", CatalogQuery{PURL: "pkg:maven/org.apache.jena/apache-jena"}, []string{"Jena"}},
+		{"purl with other version", CatalogQuery{PURL: "pkg:maven/org.apache.jena/apache-jena@3.11.0"}, nil},
+		{"name", CatalogQuery{Name: "GLIBC"}, []string{"glibc"}},
+		{"version range", CatalogQuery{VersionRange: ">=3.0,<3.12.1"}, []string{"Jena"}},
+		{"exact version", CatalogQuery{VersionRange: "2.11.1"}, []string{"glibc"}},
+		{"license", CatalogQuery{License: "mpl-1.0"}, []string{"Saxon"}},
+		{"supplier", CatalogQuery{Supplier: "jane.doe"}, []string{"glibc"}},
+		{"name and version range", CatalogQuery{Name: "glibc", VersionRange: "<2.11"}, nil},
+		{"version range with trailing zeros", CatalogQuery{Name: "glibc", VersionRange: "<2.11.1.0"}, nil},
+	}
+	for _, test := range tests {
+		matches, err := catalog.Search(test.query)
+		if err != nil {
+			t.Fatalf("%s: expected no error from Search, got: %v", test.name, err)
+		}
+		var names []string
+		for _, match := range matches {
+			if match.Reference != entry.Reference || match.Digest != entry.Digest {
+				t.Errorf("%s: expected the match to be in %s, got: %+v", test.name, entry.Reference, match)
+			}
+			names = append(names, match.Package.Name)
+		}
+		if len(names) != len(test.expected) {
+			t.Errorf("%s: expected matches %v, got: %v", test.name, test.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.expected[i] {
+				t.Errorf("%s: expected matches %v, got: %v", test.name, test.expected, names)
+			}
+		}
+	}
+
+	if _, err := catalog.Search(CatalogQuery{VersionRange: "<"}); err == nil {
+		t.Errorf("expected error for an invalid version range, got no error")
+	}
+}
+
+func TestCatalog_CrawlRepository(t *testing.T) {
+	ctx := context.Background()
+	sbom, desc, sbomBytes, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+	layoutPath := t.TempDir()
+	store, err := oci.New(layoutPath)
+	if err != nil {
+		t.Fatalf("expected no error from oci.New, got: %v", err)
+	}
+	for _, reference := range []string{"example.com/sboms:v1", "example.com/sboms:v2"} {
+		if _, err := PushSBOMWithOptions(ctx, sbom.Document, desc, sbomBytes, reference, store, PushOptions{}); err != nil {
+			t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+		}
+	}
+
+	catalog := openTestCatalog(t)
+	stale := &CatalogEntry{Reference: "example.com/sboms:v0"}
+	if err := catalog.Add(stale); err != nil {
+		t.Fatalf("expected no error from Add, got: %v", err)
+	}
+	other := &CatalogEntry{Reference: "example.com/sboms-other:v0"}
+	if err := catalog.Add(other); err != nil {
+		t.Fatalf("expected no error from Add, got: %v", err)
+	}
+
+	result, err := catalog.CrawlRepository(ctx, store, "example.com/sboms", true)
+	if err != nil {
+		t.Fatalf("expected no error from CrawlRepository, got: %v", err)
+	}
+	if len(result.Indexed) != 2 || len(result.Errors) != 0 {
+		t.Errorf("expected 2 indexed SBOMs and no errors, got: %+v", result)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != stale.Reference {
+		t.Errorf("expected the stale entry to be removed, got: %v", result.Removed)
+	}
+
+	entries, err := catalog.List()
+	if err != nil {
+		t.Fatalf("expected no error from List, got: %v", err)
+	}
+	expected := []string{"example.com/sboms-other:v0", "example.com/sboms:v1", "example.com/sboms:v2"}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected entries %v, got: %+v", expected, entries)
+	}
+	for i, entry := range entries {
+		if entry.Reference != expected[i] {
+			t.Errorf("expected entry %d to be %s, got: %s", i, expected[i], entry.Reference)
+		}
+	}
+	if entries[1].Digest == desc.Digest || entries[1].Digest == "" {
+		t.Errorf("expected the entry digest to be the manifest digest, got: %s", entries[1].Digest)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.17", "2.17", 0},
+		{"2.9", "2.17", -1},
+		{"2.17.1", "2.17", 1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+		{"1.0.0-rc10", "1.0.0-rc9", 1},
+		{"2.17", "2.17.0", 0},
+		{"2.17.0", "2.17", 0},
+		{"2.0-beta9", "2.0", -1},
+		{"2.0-beta9", "2.0.1", -1},
+		{"2.0-alpha1", "2.0-beta9", -1},
+		{"2.0.RC2", "2.0", -1},
+		{"2.0.Final", "2.0", 0},
+		{"5.1.8-6.el9", "5.1.8", 1},
+	}
+	for _, test := range tests {
+		cmp := compareVersions(test.a, test.b)
+		if (cmp < 0 && test.expected >= 0) || (cmp > 0 && test.expected <= 0) || (cmp == 0 && test.expected != 0) {
+			t.Errorf("expected compareVersions(%s, %s) to be %d, got: %d", test.a, test.b, test.expected, cmp)
+		}
+	}
+}
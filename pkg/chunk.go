@@ -0,0 +1,243 @@
+package obom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	MEDIATYPE_SPDX_HEADER         = "application/vnd.obom.spdx.header.v1+json"
+	MEDIATYPE_SPDX_CHUNK          = "application/vnd.obom.spdx.chunk.v1"
+	OCI_ANNOTATION_CHUNKED_DIGEST = "org.obom.chunked.digest"
+	OCI_ANNOTATION_CHUNKED_SIZE   = "org.obom.chunked.size"
+	OCI_ANNOTATION_CHUNK_INDEX    = "org.obom.chunk.index"
+	OCI_ANNOTATION_CHUNK_FIELD    = "org.obom.chunk.field"
+	OCI_ANNOTATION_CHUNK_OFFSET   = "org.obom.chunk.offset"
+)
+
+// chunkedFields are the SPDX document arrays split into chunks
+var chunkedFields = map[string]bool{
+	"packages": true,
+	"files":    true,
+}
+
+// SBOMChunk is a run of consecutive elements of the packages or files array of an SPDX document
+type SBOMChunk struct {
+	// Field is the name of the array the elements belong to, either packages or files
+	Field string
+	// Offset is the position in the header where the chunk is inserted back
+	Offset int64
+	// Bytes are the raw bytes of the elements, including the separators between them
+	Bytes []byte
+}
+
+// SplitSBOM splits the SPDX JSON document into a header and chunks of its packages and files.
+// The header is the document with the packages and files arrays emptied, and each chunk holds the raw bytes of
+// consecutive array elements, so that JoinSBOM restores the exact original bytes. A chunk holds as many elements
+// as fit in chunkSize bytes, and at least one element even if it is larger.
+func SplitSBOM(sbomBytes []byte, chunkSize int64) ([]byte, []SBOMChunk, error) {
+	if chunkSize <= 0 {
+		return nil, nil, fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(sbomBytes))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding SBOM: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("error decoding SBOM: expected a JSON object")
+	}
+
+	var header []byte
+	var chunks []SBOMChunk
+	// copied is the position in sbomBytes up to which the bytes are in the header or in chunks
+	var copied int64
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decoding SBOM: %w", err)
+		}
+		key, _ := token.(string)
+
+		if !chunkedFields[key] {
+			if err := skipJSONValue(decoder); err != nil {
+				return nil, nil, fmt.Errorf("error decoding SBOM field %s: %w", key, err)
+			}
+			continue
+		}
+
+		fieldChunks, start, end, err := splitJSONArray(decoder, key, chunkSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decoding SBOM field %s: %w", key, err)
+		}
+		if len(fieldChunks) == 0 {
+			continue
+		}
+
+		// keep the bytes up to the array content in the header and insert the chunks there
+		header = append(header, sbomBytes[copied:start]...)
+		for _, chunk := range fieldChunks {
+			chunks = append(chunks, SBOMChunk{
+				Field:  key,
+				Offset: int64(len(header)),
+				Bytes:  sbomBytes[chunk[0]:chunk[1]],
+			})
+		}
+		copied = end
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, fmt.Errorf("error decoding SBOM: %w", err)
+	}
+
+	header = append(header, sbomBytes[copied:]...)
+	return header, chunks, nil
+}
+
+// splitJSONArray splits the content of the next JSON array of the decoder into ranges of whole elements of about
+// chunkSize bytes and returns them with the range of the whole array content. A value other than an array is skipped.
+func splitJSONArray(decoder *json.Decoder, field string, chunkSize int64) ([][2]int64, int64, int64, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		if ok && delim == '{' {
+			return nil, 0, 0, fmt.Errorf("expected an array")
+		}
+		return nil, 0, 0, nil
+	}
+
+	start := decoder.InputOffset()
+	chunkStart, lastEnd := start, start
+	var chunks [][2]int64
+	for decoder.More() {
+		if err := skipJSONValue(decoder); err != nil {
+			return nil, 0, 0, err
+		}
+		elementEnd := decoder.InputOffset()
+		// start a new chunk before the element that would not fit in the current one
+		if elementEnd-chunkStart > chunkSize && lastEnd > chunkStart {
+			chunks = append(chunks, [2]int64{chunkStart, lastEnd})
+			chunkStart = lastEnd
+		}
+		lastEnd = elementEnd
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, 0, 0, err
+	}
+	// the closing bracket was just read
+	end := decoder.InputOffset() - 1
+
+	if lastEnd == start {
+		// the array has no elements
+		return nil, 0, 0, nil
+	}
+	// the last chunk runs up to the closing bracket to keep the trailing whitespace
+	chunks = append(chunks, [2]int64{chunkStart, end})
+	return chunks, start, end, nil
+}
+
+// JoinSBOM inserts the chunks back into the header returned by SplitSBOM to restore the original SPDX document.
+// The chunks must be in their original order.
+func JoinSBOM(header []byte, chunks []SBOMChunk) ([]byte, error) {
+	var joined bytes.Buffer
+	var copied int64
+	for i, chunk := range chunks {
+		if chunk.Offset < copied || chunk.Offset > int64(len(header)) {
+			return nil, fmt.Errorf("chunk %d has an invalid offset %d", i, chunk.Offset)
+		}
+		joined.Write(header[copied:chunk.Offset])
+		joined.Write(chunk.Bytes)
+		copied = chunk.Offset
+	}
+	joined.Write(header[copied:])
+	return joined.Bytes(), nil
+}
+
+// chunkLayerDescriptors returns the descriptors of the header and chunk layers of the SBOM split by SplitSBOM.
+// The header layer keeps the annotations of the SBOM descriptor along with its digest and size, which identify
+// the reassembled document.
+func chunkLayerDescriptors(sbomDescriptor *v1.Descriptor, header []byte, chunks []SBOMChunk) (v1.Descriptor, []v1.Descriptor) {
+	headerLayer := v1.Descriptor{
+		MediaType:   MEDIATYPE_SPDX_HEADER,
+		Digest:      digest.FromBytes(header),
+		Size:        int64(len(header)),
+		Annotations: make(map[string]string),
+	}
+	for k, v := range sbomDescriptor.Annotations {
+		headerLayer.Annotations[k] = v
+	}
+	headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_DIGEST] = sbomDescriptor.Digest.String()
+	headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_SIZE] = strconv.FormatInt(sbomDescriptor.Size, 10)
+
+	chunkLayers := make([]v1.Descriptor, len(chunks))
+	for i, chunk := range chunks {
+		chunkLayers[i] = v1.Descriptor{
+			MediaType: MEDIATYPE_SPDX_CHUNK,
+			Digest:    digest.FromBytes(chunk.Bytes),
+			Size:      int64(len(chunk.Bytes)),
+			Annotations: map[string]string{
+				OCI_ANNOTATION_CHUNK_INDEX:  strconv.Itoa(i),
+				OCI_ANNOTATION_CHUNK_FIELD:  chunk.Field,
+				OCI_ANNOTATION_CHUNK_OFFSET: strconv.FormatInt(chunk.Offset, 10),
+			},
+		}
+	}
+	return headerLayer, chunkLayers
+}
+
+// isChunked reports whether the SBOM layer is the header layer of a chunked SBOM
+func isChunked(layer v1.Descriptor) bool {
+	mediaType, _ := UncompressedMediaType(layer.MediaType)
+	return mediaType == MEDIATYPE_SPDX_HEADER
+}
+
+// getChunkLayers returns the chunk layers of the manifest sorted by their index
+func getChunkLayers(manifest *v1.Manifest) ([]v1.Descriptor, []int64, error) {
+	type indexedLayer struct {
+		index  int
+		offset int64
+		layer  v1.Descriptor
+	}
+
+	var indexed []indexedLayer
+	for _, layer := range manifest.Layers {
+		mediaType, _ := UncompressedMediaType(layer.MediaType)
+		if mediaType != MEDIATYPE_SPDX_CHUNK {
+			continue
+		}
+		index, err := strconv.Atoi(layer.Annotations[OCI_ANNOTATION_CHUNK_INDEX])
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing chunk index of layer %s: %w", layer.Digest, err)
+		}
+		offset, err := strconv.ParseInt(layer.Annotations[OCI_ANNOTATION_CHUNK_OFFSET], 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing chunk offset of layer %s: %w", layer.Digest, err)
+		}
+		indexed = append(indexed, indexedLayer{index: index, offset: offset, layer: layer})
+	}
+
+	sort.Slice(indexed, func(i, j int) bool {
+		return indexed[i].index < indexed[j].index
+	})
+
+	layers := make([]v1.Descriptor, len(indexed))
+	offsets := make([]int64, len(indexed))
+	for i, l := range indexed {
+		if l.index != i {
+			return nil, nil, fmt.Errorf("missing chunk %d of the SBOM", i)
+		}
+		layers[i] = l.layer
+		offsets[i] = l.offset
+	}
+	return layers, offsets, nil
+}
@@ -0,0 +1,191 @@
+package obom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestSplitSBOM_JoinSBOM(t *testing.T) {
+	sbomBytes, err := os.ReadFile("../examples/SPDXJSONExample-v2.3.spdx.json")
+	if err != nil {
+		t.Fatalf("error reading SBOM: %v", err)
+	}
+
+	for _, chunkSize := range []int64{1, 512, 4096, int64(len(sbomBytes))} {
+		t.Run(strconv.FormatInt(chunkSize, 10), func(t *testing.T) {
+			header, chunks, err := SplitSBOM(sbomBytes, chunkSize)
+			if err != nil {
+				t.Fatalf("expected no error from SplitSBOM, got: %v", err)
+			}
+			if len(chunks) == 0 {
+				t.Fatalf("expected chunks, got none")
+			}
+
+			var headerDoc map[string]interface{}
+			if err := json.Unmarshal(header, &headerDoc); err != nil {
+				t.Fatalf("expected the header to be a JSON document, got: %v", err)
+			}
+			for _, field := range []string{"packages", "files"} {
+				if elements, _ := headerDoc[field].([]interface{}); len(elements) != 0 {
+					t.Errorf("expected %s to be empty in the header, got %d elements", field, len(elements))
+				}
+			}
+			if headerDoc["spdxVersion"] != "SPDX-2.3" {
+				t.Errorf("expected the header to keep spdxVersion, got: %v", headerDoc["spdxVersion"])
+			}
+
+			joined, err := JoinSBOM(header, chunks)
+			if err != nil {
+				t.Fatalf("expected no error from JoinSBOM, got: %v", err)
+			}
+			if !bytes.Equal(joined, sbomBytes) {
+				t.Errorf("expected the joined SBOM to be identical to the original")
+			}
+		})
+	}
+}
+
+func TestSplitSBOM_ChunkSize(t *testing.T) {
+	sbomBytes := []byte(`{"spdxVersion": "SPDX-2.3", "packages": [{"name": "a"}, {"name": "b"}, {"name": "c"}], "files": []}`)
+
+	header, chunks, err := SplitSBOM(sbomBytes, 28)
+	if err != nil {
+		t.Fatalf("expected no error from SplitSBOM, got: %v", err)
+	}
+
+	if string(header) != `{"spdxVersion": "SPDX-2.3", "packages": [], "files": []}` {
+		t.Errorf("unexpected header: %s", header)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got: %d", len(chunks))
+	}
+	if string(chunks[0].Bytes) != `{"name": "a"}, {"name": "b"}` || string(chunks[1].Bytes) != `, {"name": "c"}` {
+		t.Errorf("unexpected chunks: %q, %q", chunks[0].Bytes, chunks[1].Bytes)
+	}
+	for _, chunk := range chunks {
+		if chunk.Field != "packages" || chunk.Offset != 41 {
+			t.Errorf("expected chunk of packages at offset 41, got: %s at %d", chunk.Field, chunk.Offset)
+		}
+	}
+}
+
+func TestSplitSBOM_InvalidChunkSize(t *testing.T) {
+	if _, _, err := SplitSBOM([]byte(`{}`), 0); err == nil {
+		t.Fatalf("expected error for a zero chunk size, got no error")
+	}
+}
+
+func TestPushSBOMWithOptions_Chunked(t *testing.T) {
+	ctx := context.Background()
+
+	for _, compression := range []string{"", COMPRESSION_GZIP} {
+		t.Run("compression="+compression, func(t *testing.T) {
+			sbom, desc, sbomBytes, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+			if err != nil {
+				t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+			}
+
+			memDest := memory.New()
+			_, err = PushSBOMWithOptions(ctx, sbom.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{
+				ChunkSize:   1024,
+				Compression: compression,
+			})
+			if err != nil {
+				t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+			}
+
+			_, manifest, err := FetchManifest(ctx, memDest, "v1")
+			if err != nil {
+				t.Fatalf("expected no error from FetchManifest, got: %v", err)
+			}
+			if len(manifest.Layers) < 3 {
+				t.Fatalf("expected a header and several chunk layers, got %d layers", len(manifest.Layers))
+			}
+			headerLayer := manifest.Layers[0]
+			if headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_DIGEST] != desc.Digest.String() {
+				t.Errorf("expected the header layer to keep the SBOM digest, got: %s", headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_DIGEST])
+			}
+			for i, layer := range manifest.Layers[1:] {
+				if layer.Annotations[OCI_ANNOTATION_CHUNK_INDEX] != strconv.Itoa(i) {
+					t.Errorf("expected chunk index %d, got: %s", i, layer.Annotations[OCI_ANNOTATION_CHUNK_INDEX])
+				}
+			}
+
+			_, fetchedDesc, fetchedBytes, err := FetchSBOM(ctx, memDest, "v1", true)
+			if err != nil {
+				t.Fatalf("expected no error from FetchSBOM, got: %v", err)
+			}
+			if !bytes.Equal(fetchedBytes, sbomBytes) || fetchedDesc.Digest != desc.Digest {
+				t.Errorf("expected the fetched SBOM to be identical to the pushed SBOM")
+			}
+			if fetchedDesc.Annotations[v1.AnnotationTitle] != "SPDXJSONExample-v2.3.spdx.json" {
+				t.Errorf("expected the title annotation to be kept, got: %s", fetchedDesc.Annotations[v1.AnnotationTitle])
+			}
+		})
+	}
+}
+
+func TestFetchSBOM_ChunkedDigestMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	sbom, desc, sbomBytes, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+
+	// push the chunks with the digest of another document
+	wrongDesc := *desc
+	wrongDesc.Digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	memDest := memory.New()
+	_, err = PushSBOMWithOptions(ctx, sbom.Document, &wrongDesc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{ChunkSize: 1024})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	if _, _, _, err := FetchSBOM(ctx, memDest, "v1", true); err == nil {
+		t.Fatalf("expected error for a reassembled SBOM with a different digest, got no error")
+	}
+}
+
+func TestFetchSBOM_TamperedChunk(t *testing.T) {
+	ctx := context.Background()
+
+	sbom, err := LoadSBOM("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOM, got: %v", err)
+	}
+	memDest := memory.New()
+	if _, err := PushSBOMArtifact(ctx, sbom, "localhost:5000/spdx:v1", memDest, PushOptions{ChunkSize: 1024}); err != nil {
+		t.Fatalf("expected no error from PushSBOMArtifact, got: %v", err)
+	}
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+
+	// tamper the first chunk layer, keeping its size
+	chunk := manifest.Layers[1]
+	if chunk.MediaType != MEDIATYPE_SPDX_CHUNK {
+		t.Fatalf("expected the second layer to be a chunk, got: %s", chunk.MediaType)
+	}
+	chunkBytes, err := content.FetchAll(ctx, memDest, chunk)
+	if err != nil {
+		t.Fatalf("expected no error fetching the chunk, got: %v", err)
+	}
+	tampered := append([]byte{}, chunkBytes...)
+	tampered[len(tampered)-1] ^= 0xff
+	store := &tamperedStore{Store: memDest, digest: chunk.Digest, content: tampered}
+	if _, err := FetchSBOMArtifact(ctx, store, "v1", true); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("expected a mismatched digest error from FetchSBOMArtifact, got: %v", err)
+	}
+}
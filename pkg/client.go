@@ -0,0 +1,232 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// Client pushes and fetches SPDX SBOMs. Every network call takes a context, so that callers can cancel them or set
+// timeouts. A Client is configured with ClientOption values and is safe for concurrent use once created.
+type Client struct {
+	target               oras.Target
+	credentials          CredentialsResolver
+	httpClient           *http.Client
+	plainHTTP            bool
+	strict               bool
+	annotations          map[string]string
+	noDefaultAnnotations bool
+	pushOptions          PushOptions
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// NewClient returns a client configured with the options. By default the client parses SBOMs strictly and pushes to
+// and fetches from the registry of each reference over HTTPS without credentials.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		strict:      true,
+		annotations: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithTarget pushes to and fetches from the target, such as an OCI layout or an in-memory store, instead of the
+// registry of each reference
+func WithTarget(target oras.Target) ClientOption {
+	return func(c *Client) {
+		c.target = target
+	}
+}
+
+// WithCredentials resolves the credentials of the registries with the resolver
+func WithCredentials(resolver CredentialsResolver) ClientOption {
+	return func(c *Client) {
+		c.credentials = resolver
+	}
+}
+
+// WithHTTPClient sends the registry requests with the HTTP client, for custom TLS settings or transports
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithPlainHTTP uses plain HTTP instead of HTTPS for the registries
+func WithPlainHTTP(plainHTTP bool) ClientOption {
+	return func(c *Client) {
+		c.plainHTTP = plainHTTP
+	}
+}
+
+// WithStrict sets whether SBOMs are parsed strictly as per the SPDX specification, see ReadSBOM
+func WithStrict(strict bool) ClientOption {
+	return func(c *Client) {
+		c.strict = strict
+	}
+}
+
+// WithAnnotations adds the manifest annotations to the pushed SBOMs, overriding the annotations derived from the SBOM
+func WithAnnotations(annotations map[string]string) ClientOption {
+	return func(c *Client) {
+		for k, v := range annotations {
+			c.annotations[k] = v
+		}
+	}
+}
+
+// WithoutDefaultAnnotations leaves out the standard OCI annotations derived from the SBOM, see GetDefaultAnnotations
+func WithoutDefaultAnnotations() ClientOption {
+	return func(c *Client) {
+		c.noDefaultAnnotations = true
+	}
+}
+
+// WithSummary pushes the summary of the SBOM as a layer along with the SBOM, see GetSBOMSummary
+func WithSummary() ClientOption {
+	return func(c *Client) {
+		c.pushOptions.PushSummary = true
+	}
+}
+
+// WithAttachments attaches the artifacts to the pushed SBOMs, mapping an artifactType to the paths of the artifacts
+func WithAttachments(attachments map[string][]string) ClientOption {
+	return func(c *Client) {
+		if c.pushOptions.AttachArtifacts == nil {
+			c.pushOptions.AttachArtifacts = make(map[string][]string)
+		}
+		for artifactType, paths := range attachments {
+			c.pushOptions.AttachArtifacts[artifactType] = append(c.pushOptions.AttachArtifacts[artifactType], paths...)
+		}
+	}
+}
+
+// WithAttachment attaches the artifact with its layers and annotations to the pushed SBOMs, after the artifacts of
+// WithAttachments
+func WithAttachment(attachment Attachment) ClientOption {
+	return func(c *Client) {
+		c.pushOptions.Attachments = append(c.pushOptions.Attachments, attachment)
+	}
+}
+
+// WithProgress reports the progress events of the pushes to the progress function
+func WithProgress(progress ProgressFunc) ClientOption {
+	return func(c *Client) {
+		c.pushOptions.Progress = progress
+	}
+}
+
+// WithPushOptions sets the reproducibility, layout, compression, chunking and tags of the pushed SBOMs from the options.
+// Their annotations, summary, attachments and progress are ignored in favor of WithAnnotations, WithSummary,
+// WithAttachments, WithAttachment and WithProgress.
+func WithPushOptions(opts PushOptions) ClientOption {
+	return func(c *Client) {
+		c.pushOptions.Reproducible = opts.Reproducible
+		c.pushOptions.Layout = opts.Layout
+		c.pushOptions.Compression = opts.Compression
+		c.pushOptions.ChunkSize = opts.ChunkSize
+		c.pushOptions.Tags = opts.Tags
+		c.pushOptions.NoTag = opts.NoTag
+	}
+}
+
+// Load loads the SBOM file with the strictness of the client, see LoadSBOM
+func (c *Client) Load(filename string) (*SBOM, error) {
+	return LoadSBOM(filename, c.strict)
+}
+
+// Push pushes the SBOM as an OCI artifact tagged with the tags of the reference, see ParsePushReference, annotated
+// with the annotations of the SBOM, the standard OCI annotations derived from it and the annotations of the client.
+// The error wraps ErrReferenceInvalid when the reference cannot be parsed.
+func (c *Client) Push(ctx context.Context, reference string, sbom *SBOM) (*PushResult, error) {
+	ref, _, err := ParsePushReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	target, _, err := c.resolveTarget(ref.String())
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := GetAnnotations(sbom.SPDXDocument)
+	if err != nil {
+		return nil, fmt.Errorf("error getting annotations: %w", err)
+	}
+	if !c.noDefaultAnnotations {
+		for k, v := range GetDefaultAnnotations(sbom.Document) {
+			annotations[k] = v
+		}
+	}
+	for k, v := range c.annotations {
+		annotations[k] = v
+	}
+
+	opts := c.pushOptions
+	opts.Annotations = annotations
+	return PushSBOMArtifact(ctx, sbom, reference, target, opts)
+}
+
+// PushFile loads the SBOM file and pushes it, see Push
+func (c *Client) PushFile(ctx context.Context, reference string, filename string) (*PushResult, error) {
+	sbom, err := c.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	return c.Push(ctx, reference, sbom)
+}
+
+// Fetch fetches the SBOM artifact tagged or identified by the reference, see FetchSBOMArtifact.
+// The error wraps ErrReferenceInvalid when the reference cannot be parsed.
+func (c *Client) Fetch(ctx context.Context, reference string) (*FetchResult, error) {
+	target, tagOrDigest, err := c.resolveTarget(reference)
+	if err != nil {
+		return nil, err
+	}
+	return FetchSBOMArtifact(ctx, target, tagOrDigest, c.strict)
+}
+
+// resolveTarget returns the target of the reference, which is the target of the client when set or else the remote
+// repository of the reference, along with the tag or digest of the reference, defaulting to latest
+func (c *Client) resolveTarget(reference string) (oras.Target, string, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: error parsing reference: %w", ErrReferenceInvalid, err)
+	}
+	tagOrDigest := ref.Reference
+	if tagOrDigest == "" {
+		tagOrDigest = "latest"
+	}
+	if c.target != nil {
+		return c.target, tagOrDigest, nil
+	}
+
+	repo, err := remote.NewRepository(ref.Registry + "/" + ref.Repository)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: error connecting to remote repository: %w", ErrReferenceInvalid, err)
+	}
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = retry.DefaultClient
+	}
+	client := &auth.Client{
+		Client:     httpClient,
+		Cache:      auth.DefaultCache,
+		Credential: c.credentials,
+	}
+	client.SetUserAgent(APPLICATION_USERAGENT)
+	repo.Client = client
+	repo.PlainHTTP = c.plainHTTP
+
+	return repo, tagOrDigest, nil
+}
@@ -0,0 +1,124 @@
+package obom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestClient_PushAndFetch(t *testing.T) {
+	ctx := context.Background()
+	memDest := memory.New()
+	client := NewClient(
+		WithTarget(memDest),
+		WithAnnotations(map[string]string{"org.example.team": "platform"}),
+		WithSummary(),
+	)
+
+	result, err := client.PushFile(ctx, "localhost:5000/spdx:v1", "../examples/SPDXJSONExample-v2.3.spdx.json")
+	if err != nil {
+		t.Fatalf("expected no error from PushFile, got: %v", err)
+	}
+
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+	expected := map[string]string{
+		OCI_ANNOTATION_DOCUMENT_NAME: "SPDX-Tools-v2.0",
+		ocispec.AnnotationVersion:    "2.11.1",
+		"org.example.team":           "platform",
+	}
+	for k, v := range expected {
+		if manifest.Annotations[k] != v {
+			t.Errorf("expected annotation %s to be '%s', got: '%s'", k, v, manifest.Annotations[k])
+		}
+	}
+	if len(manifest.Layers) != 2 || manifest.Layers[1].MediaType != MEDIATYPE_SBOM_SUMMARY {
+		t.Errorf("expected the summary layer, got: %+v", manifest.Layers)
+	}
+
+	fetched, err := client.Fetch(ctx, "localhost:5000/spdx:v1")
+	if err != nil {
+		t.Fatalf("expected no error from Fetch, got: %v", err)
+	}
+	if fetched.Manifest.Digest != result.Descriptor.Digest {
+		t.Errorf("expected the manifest digest to be %s, got: %s", result.Descriptor.Digest, fetched.Manifest.Digest)
+	}
+	if fetched.Document.DocumentName != "SPDX-Tools-v2.0" || len(fetched.Bytes) != int(fetched.Descriptor.Size) {
+		t.Errorf("unexpected fetched SBOM: %s, %d bytes", fetched.Document.DocumentName, len(fetched.Bytes))
+	}
+}
+
+func TestClient_WithoutDefaultAnnotations(t *testing.T) {
+	ctx := context.Background()
+	memDest := memory.New()
+	client := NewClient(WithTarget(memDest), WithoutDefaultAnnotations())
+
+	if _, err := client.PushFile(ctx, "localhost:5000/spdx:v1", "../examples/SPDXJSONExample-v2.3.spdx.json"); err != nil {
+		t.Fatalf("expected no error from PushFile, got: %v", err)
+	}
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+	if _, ok := manifest.Annotations[ocispec.AnnotationVersion]; ok {
+		t.Errorf("expected no default annotations, got: %v", manifest.Annotations)
+	}
+}
+
+func TestClient_ReferenceInvalid(t *testing.T) {
+	client := NewClient(WithTarget(memory.New()))
+
+	_, err := client.Fetch(context.Background(), "not a reference")
+	if !errors.Is(err, ErrReferenceInvalid) {
+		t.Fatalf("expected ErrReferenceInvalid, got: %v", err)
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no request with a canceled context, got: %s", r.URL)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(WithPlainHTTP(true))
+	_, err := client.Fetch(ctx, strings.TrimPrefix(server.URL, "http://")+"/spdx:v1")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestReadSBOM_TypedErrors(t *testing.T) {
+	_, err := ReadSBOM(strings.NewReader(`{"name": "not-spdx"}`), true)
+	if !errors.Is(err, ErrNotSPDX) {
+		t.Errorf("expected ErrNotSPDX for a document without spdxVersion, got: %v", err)
+	}
+
+	_, err = ReadSBOM(strings.NewReader(`not json`), true)
+	if !errors.Is(err, ErrNotSPDX) {
+		t.Errorf("expected ErrNotSPDX for a document that is not JSON, got: %v", err)
+	}
+
+	_, err = ReadSBOM(strings.NewReader(nonCompliantSPDXStr), true)
+	if !errors.Is(err, ErrStrictParse) {
+		t.Errorf("expected ErrStrictParse for a non compliant document, got: %v", err)
+	}
+
+	sbom, err := ReadSBOM(strings.NewReader(nonCompliantSPDXStr), false)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM without strict parsing, got: %v", err)
+	}
+	if sbom.Document.DocumentName != "SPDX-Example" {
+		t.Errorf("expected document name to be 'SPDX-Example', got: %s", sbom.Document.DocumentName)
+	}
+}
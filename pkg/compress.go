@@ -0,0 +1,141 @@
+package obom
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	COMPRESSION_GZIP                   = "gzip"
+	COMPRESSION_ZSTD                   = "zstd"
+	MEDIATYPE_SPDX_GZIP                = MEDIATYPE_SPDX + "+gzip"
+	MEDIATYPE_SPDX_ZSTD                = MEDIATYPE_SPDX + "+zstd"
+	OCI_ANNOTATION_UNCOMPRESSED_DIGEST = "org.obom.uncompressed.digest"
+	OCI_ANNOTATION_UNCOMPRESSED_SIZE   = "org.obom.uncompressed.size"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// CompressBytes compresses the bytes with the given compression, either gzip or zstd
+func CompressBytes(data []byte, compression string) ([]byte, error) {
+	reader, err := CompressReader(bytes.NewReader(data), compression)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// CompressReader returns a reader of the content of the given reader compressed with the given compression,
+// either gzip or zstd. The content is compressed as it is read, without buffering it in memory.
+// The compression is reproducible: compressing the same content again yields the same bytes.
+func CompressReader(reader io.Reader, compression string) (io.ReadCloser, error) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	var writer io.WriteCloser
+	switch compression {
+	case COMPRESSION_GZIP:
+		// the gzip header is left without a modification time so that the output is reproducible
+		writer = gzip.NewWriter(pipeWriter)
+	case COMPRESSION_ZSTD:
+		// a single encoder goroutine keeps the output independent of scheduling
+		encoder, err := zstd.NewWriter(pipeWriter, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("error compressing with zstd: %w", err)
+		}
+		writer = encoder
+	default:
+		return nil, fmt.Errorf("unsupported compression %q, expected %s or %s", compression, COMPRESSION_GZIP, COMPRESSION_ZSTD)
+	}
+
+	go func() {
+		_, err := io.Copy(writer, reader)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			err = fmt.Errorf("error compressing with %s: %w", compression, err)
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader, nil
+}
+
+// CompressedMediaType returns the media type of a layer of the given media type compressed with the given compression
+func CompressedMediaType(mediaType string, compression string) string {
+	return mediaType + "+" + compression
+}
+
+// UncompressedMediaType returns the media type without the compression suffix and the compression, if any
+func UncompressedMediaType(mediaType string) (string, string) {
+	for _, compression := range []string{COMPRESSION_GZIP, COMPRESSION_ZSTD} {
+		if trimmed, found := strings.CutSuffix(mediaType, "+"+compression); found {
+			return trimmed, compression
+		}
+	}
+	return mediaType, ""
+}
+
+// DecompressReader returns a reader of the decompressed content if the content of the reader is gzip or zstd
+// compressed, detected by its magic number, otherwise a reader of the content as is.
+// Closing the returned reader closes the given reader.
+func DecompressReader(reader io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(reader)
+	// a short read means the content is too small to be compressed
+	magic, _ := buffered.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing gzip content: %w", err)
+		}
+		return &decompressReadCloser{Reader: gzipReader, closers: []io.Closer{gzipReader, reader}}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing zstd content: %w", err)
+		}
+		zstdReader := decoder.IOReadCloser()
+		return &decompressReadCloser{Reader: zstdReader, closers: []io.Closer{zstdReader, reader}}, nil
+	default:
+		return &decompressReadCloser{Reader: buffered, closers: []io.Closer{reader}}, nil
+	}
+}
+
+// decompressReadCloser closes the decompressor along with the underlying reader
+type decompressReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressReadCloser) Close() error {
+	var firstErr error
+	for _, closer := range d.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// trimCompressionExtension removes the .gz or .zst extension from a file name
+func trimCompressionExtension(filename string) string {
+	for _, ext := range []string{".gz", ".zst"} {
+		if trimmed, found := strings.CutSuffix(filename, ext); found {
+			return trimmed
+		}
+	}
+	return filename
+}
@@ -0,0 +1,108 @@
+package obom
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCompressBytes_RoundTrip(t *testing.T) {
+	for _, compression := range []string{COMPRESSION_GZIP, COMPRESSION_ZSTD} {
+		t.Run(compression, func(t *testing.T) {
+			compressed, err := CompressBytes([]byte(spdxStr), compression)
+			if err != nil {
+				t.Fatalf("expected no error from CompressBytes, got: %v", err)
+			}
+			if bytes.Equal(compressed, []byte(spdxStr)) {
+				t.Fatalf("expected compressed bytes to differ from the input")
+			}
+
+			// compressing again yields the same bytes
+			again, err := CompressBytes([]byte(spdxStr), compression)
+			if err != nil {
+				t.Fatalf("expected no error from CompressBytes, got: %v", err)
+			}
+			if !bytes.Equal(compressed, again) {
+				t.Errorf("expected compression to be reproducible")
+			}
+
+			reader, err := DecompressReader(io.NopCloser(bytes.NewReader(compressed)))
+			if err != nil {
+				t.Fatalf("expected no error from DecompressReader, got: %v", err)
+			}
+			defer reader.Close()
+
+			decompressed, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("error reading decompressed bytes: %v", err)
+			}
+			if !bytes.Equal(decompressed, []byte(spdxStr)) {
+				t.Errorf("expected decompressed bytes to match the input")
+			}
+		})
+	}
+}
+
+func TestCompressBytes_Unsupported(t *testing.T) {
+	if _, err := CompressBytes([]byte(spdxStr), "bzip2"); err == nil {
+		t.Fatalf("expected error for unsupported compression, got no error")
+	}
+}
+
+func TestDecompressReader_Uncompressed(t *testing.T) {
+	for _, input := range []string{spdxStr, "{}", ""} {
+		reader, err := DecompressReader(io.NopCloser(bytes.NewReader([]byte(input))))
+		if err != nil {
+			t.Fatalf("expected no error from DecompressReader, got: %v", err)
+		}
+
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("error reading bytes: %v", err)
+		}
+		if string(output) != input {
+			t.Errorf("expected uncompressed content to be returned as is, got: %s", output)
+		}
+	}
+}
+
+func TestLoadSBOMFromReader_Compressed(t *testing.T) {
+	compressed, err := CompressBytes([]byte(spdxStr), COMPRESSION_ZSTD)
+	if err != nil {
+		t.Fatalf("expected no error from CompressBytes, got: %v", err)
+	}
+
+	sbomDoc, desc, sbomBytes, err := LoadSBOMFromReader(io.NopCloser(bytes.NewReader(compressed)), true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	if sbomDoc.Document.DocumentName != "SPDX-Example" {
+		t.Errorf("expected document name to be 'SPDX-Example', got: %v", sbomDoc.Document.DocumentName)
+	}
+	if !bytes.Equal(sbomBytes, []byte(spdxStr)) {
+		t.Errorf("expected sbomBytes to be the decompressed document")
+	}
+	if desc.Size != int64(len(spdxStr)) {
+		t.Errorf("expected desc.Size to be the decompressed size %d, got: %d", len(spdxStr), desc.Size)
+	}
+}
+
+func TestUncompressedMediaType(t *testing.T) {
+	testCases := []struct {
+		mediaType           string
+		expectedMediaType   string
+		expectedCompression string
+	}{
+		{MEDIATYPE_SPDX_GZIP, MEDIATYPE_SPDX, COMPRESSION_GZIP},
+		{MEDIATYPE_SPDX_ZSTD, MEDIATYPE_SPDX, COMPRESSION_ZSTD},
+		{MEDIATYPE_SPDX, MEDIATYPE_SPDX, ""},
+	}
+
+	for _, tc := range testCases {
+		mediaType, compression := UncompressedMediaType(tc.mediaType)
+		if mediaType != tc.expectedMediaType || compression != tc.expectedCompression {
+			t.Errorf("for %s, expected (%s, %s), got: (%s, %s)", tc.mediaType, tc.expectedMediaType, tc.expectedCompression, mediaType, compression)
+		}
+	}
+}
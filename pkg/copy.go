@@ -0,0 +1,74 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// CopyOptions contains the optional parameters for CopySBOMWithOptions
+type CopyOptions struct {
+	// Recursive copies the referrers of the SBOM (signatures, VEX documents, summaries, ...) along with it
+	Recursive bool
+	// ArtifactTypes optionally restricts the copied referrers to the given artifact types
+	ArtifactTypes []string
+	// Progress is called with the progress events of the copy
+	Progress ProgressFunc
+}
+
+// CopySBOM copies the SBOM artifact tagged or identified by srcRef from the source target to the destination target and tags it with dstRef.
+// If dstRef is empty, srcRef is used as the destination reference.
+// When recursive is set, the referrers of the SBOM (signatures, VEX documents, summaries, ...) are copied along with it.
+// The artifactTypes slice optionally restricts the copied referrers to the given artifact types.
+// It returns the descriptor of the copied SBOM manifest.
+func CopySBOM(ctx context.Context, src oras.ReadOnlyGraphTarget, srcRef string, dst oras.Target, dstRef string, recursive bool, artifactTypes []string) (*v1.Descriptor, error) {
+	return CopySBOMWithOptions(ctx, src, srcRef, dst, dstRef, CopyOptions{Recursive: recursive, ArtifactTypes: artifactTypes})
+}
+
+// CopySBOMWithOptions copies the SBOM artifact tagged or identified by srcRef from the source target to the
+// destination target and tags it with dstRef, see CopySBOM. It returns the descriptor of the copied SBOM manifest.
+func CopySBOMWithOptions(ctx context.Context, src oras.ReadOnlyGraphTarget, srcRef string, dst oras.Target, dstRef string, opts CopyOptions) (*v1.Descriptor, error) {
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+
+	tracker := newProgressTracker(opts.Progress)
+	src = tracker.source(src)
+
+	if !opts.Recursive {
+		copyOpts := oras.DefaultCopyOptions
+		copyOpts.CopyGraphOptions = tracker.copyGraphOptions(copyOpts.CopyGraphOptions)
+		desc, err := oras.Copy(ctx, src, srcRef, dst, dstRef, copyOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error copying SBOM: %w", err)
+		}
+		tracker.tagged(desc, dstRef)
+		return &desc, nil
+	}
+
+	copyOpts := oras.DefaultExtendedCopyOptions
+	copyOpts.CopyGraphOptions = tracker.copyGraphOptions(copyOpts.CopyGraphOptions)
+	if len(opts.ArtifactTypes) > 0 {
+		copyOpts.FilterArtifactType(artifactTypeRegexp(opts.ArtifactTypes))
+	}
+
+	desc, err := oras.ExtendedCopy(ctx, src, srcRef, dst, dstRef, copyOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error copying SBOM and referrers: %w", err)
+	}
+	tracker.tagged(desc, dstRef)
+	return &desc, nil
+}
+
+// artifactTypeRegexp returns a regular expression matching exactly one of the given artifact types
+func artifactTypeRegexp(artifactTypes []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(artifactTypes))
+	for _, artifactType := range artifactTypes {
+		quoted = append(quoted, regexp.QuoteMeta(artifactType))
+	}
+	return regexp.MustCompile("^(" + strings.Join(quoted, "|") + ")$")
+}
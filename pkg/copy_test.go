@@ -0,0 +1,106 @@
+package obom
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+)
+
+func pushTestSBOMWithArtifacts(t *testing.T, dest *memory.Store, reference string) {
+	t.Helper()
+
+	reader := io.NopCloser(strings.NewReader(spdxStr))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	attachArtifacts := map[string][]string{
+		"application/json": {"../examples/artifact.example.json"},
+		"application/yaml": {"../examples/artifact.example.yaml"},
+	}
+
+	_, err = PushSBOM(doc.Document, desc, sbomBytes, reference, nil, false, attachArtifacts, dest)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOM, got: %v", err)
+	}
+}
+
+func TestCopySBOM_NotRecursive(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	dst := memory.New()
+	pushTestSBOMWithArtifacts(t, src, "localhost:5000/spdx:v1")
+
+	desc, err := CopySBOM(ctx, src, "v1", dst, "prod", false, nil)
+	if err != nil {
+		t.Fatalf("expected no error from CopySBOM, got: %v", err)
+	}
+
+	resolved, err := dst.Resolve(ctx, "prod")
+	if err != nil {
+		t.Fatalf("expected copied SBOM to be tagged in destination, got: %v", err)
+	}
+	if resolved.Digest != desc.Digest {
+		t.Errorf("expected tagged digest to be %s, got: %s", desc.Digest, resolved.Digest)
+	}
+
+	referrers, err := registry.Referrers(ctx, dst, *desc, "")
+	if err != nil {
+		t.Fatalf("error getting referrers: %v", err)
+	}
+	if len(referrers) != 0 {
+		t.Errorf("expected no referrers to be copied, got: %d", len(referrers))
+	}
+}
+
+func TestCopySBOM_Recursive(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	dst := memory.New()
+	pushTestSBOMWithArtifacts(t, src, "localhost:5000/spdx:v1")
+
+	desc, err := CopySBOM(ctx, src, "v1", dst, "", true, nil)
+	if err != nil {
+		t.Fatalf("expected no error from CopySBOM, got: %v", err)
+	}
+
+	if _, err := dst.Resolve(ctx, "v1"); err != nil {
+		t.Errorf("expected source reference to be reused in destination, got: %v", err)
+	}
+
+	referrers, err := registry.Referrers(ctx, dst, *desc, "")
+	if err != nil {
+		t.Fatalf("error getting referrers: %v", err)
+	}
+	if len(referrers) != 2 {
+		t.Errorf("expected 2 referrers to be copied, got: %d", len(referrers))
+	}
+}
+
+func TestCopySBOM_RecursiveWithArtifactTypeFilter(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	dst := memory.New()
+	pushTestSBOMWithArtifacts(t, src, "localhost:5000/spdx:v1")
+
+	desc, err := CopySBOM(ctx, src, "v1", dst, "v1", true, []string{"application/yaml"})
+	if err != nil {
+		t.Fatalf("expected no error from CopySBOM, got: %v", err)
+	}
+
+	referrers, err := registry.Referrers(ctx, dst, *desc, "")
+	if err != nil {
+		t.Fatalf("error getting referrers: %v", err)
+	}
+	if len(referrers) != 1 {
+		t.Fatalf("expected 1 referrer to be copied, got: %d", len(referrers))
+	}
+	if referrers[0].ArtifactType != "application/yaml" {
+		t.Errorf("expected referrer artifactType to be 'application/yaml', got: %s", referrers[0].ArtifactType)
+	}
+}
@@ -0,0 +1,232 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+// DeleteTarget is a target that deletes manifests, such as a remote repository of a registry supporting the delete
+// API or an OCI layout
+type DeleteTarget interface {
+	oras.GraphTarget
+	content.Deleter
+}
+
+// PruneTarget is a target that deletes manifests and lists its tags
+type PruneTarget interface {
+	DeleteTarget
+	registry.TagLister
+}
+
+// DeleteOptions contains the optional parameters for DeleteSBOM
+type DeleteOptions struct {
+	// Cascade deletes the referrers of the SBOM (summaries, attestations, signatures, ...) and their own referrers
+	Cascade bool
+	// DryRun reports the manifests that would be deleted without deleting them
+	DryRun bool
+}
+
+// PruneOptions contains the parameters of PruneSBOMs. At least one of KeepLast and OlderThan must be set.
+type PruneOptions struct {
+	// KeepLast keeps the most recently created SBOMs
+	KeepLast int
+	// OlderThan only prunes the SBOMs created longer ago than this duration
+	OlderThan time.Duration
+	// DryRun reports the SBOMs that would be pruned without deleting them
+	DryRun bool
+	// Now is the time the age of the SBOMs is computed at, defaulting to the current time
+	Now time.Time
+}
+
+// PrunedSBOM is an SBOM of a repository considered by PruneSBOMs
+type PrunedSBOM struct {
+	// Descriptor is the descriptor of the SBOM manifest
+	Descriptor v1.Descriptor
+	// Tags are the tags of the SBOM manifest
+	Tags []string
+	// Created is the creation time of the SPDX document from the org.spdx.created annotation
+	Created time.Time
+	// Deleted are the descriptors of the deleted manifests, the referrers before the SBOM manifest
+	Deleted []v1.Descriptor
+}
+
+// PruneResult is the outcome of PruneSBOMs
+type PruneResult struct {
+	// Pruned are the deleted SBOMs, from the most to the least recently created
+	Pruned []PrunedSBOM
+	// Kept are the SBOMs that were not deleted, from the most to the least recently created
+	Kept []PrunedSBOM
+	// Skipped are the tags that are not SBOMs or that have no valid creation time, which are never pruned
+	Skipped []string
+}
+
+// DeleteSBOM deletes the SBOM manifest tagged or identified by reference from the target, along with its referrers
+// when cascading. Deleting the manifest removes all of its tags. Registries garbage collect the blobs of the deleted
+// manifests, which are left in OCI layouts. It returns the descriptors of the deleted manifests, the referrers before
+// their subject, which are the manifests that would be deleted with DryRun.
+func DeleteSBOM(ctx context.Context, target DeleteTarget, reference string, opts DeleteOptions) ([]v1.Descriptor, error) {
+	desc, manifest, err := FetchManifest(ctx, target, reference)
+	if err != nil {
+		return nil, err
+	}
+	if !HasSBOMLayer(manifest) {
+		return nil, fmt.Errorf("%s is not an SBOM manifest", reference)
+	}
+	return deleteManifest(ctx, target, *desc, opts)
+}
+
+// deleteManifest deletes the manifest, after its referrers when cascading
+func deleteManifest(ctx context.Context, target DeleteTarget, desc v1.Descriptor, opts DeleteOptions) ([]v1.Descriptor, error) {
+	manifests := []v1.Descriptor{desc}
+	if opts.Cascade {
+		referrers, err := getReferrersRecursive(ctx, target, desc)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(referrers, desc)
+	}
+	if opts.DryRun {
+		return manifests, nil
+	}
+
+	// OCI layouts also delete the referrers and the blobs left dangling by a deleted manifest, keyed by descriptor, so
+	// that a blob shared under another media type would be deleted while still in use. Delete the manifests alone as a
+	// registry does, leaving their blobs in the layout.
+	if store, ok := target.(*oci.Store); ok && store.AutoGC {
+		store.AutoGC = false
+		defer func() { store.AutoGC = true }()
+	}
+
+	deleted := make([]v1.Descriptor, 0, len(manifests))
+	for _, manifest := range manifests {
+		if err := target.Delete(ctx, manifest); err != nil {
+			return deleted, fmt.Errorf("error deleting manifest %s: %w", manifest.Digest, err)
+		}
+		deleted = append(deleted, manifest)
+	}
+	return deleted, nil
+}
+
+// getReferrersRecursive returns the referrers of the manifest and their own referrers, each referrer after its
+// referrers so that they can be deleted in order
+func getReferrersRecursive(ctx context.Context, target DeleteTarget, desc v1.Descriptor) ([]v1.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, target, desc, "")
+	if err != nil {
+		return nil, fmt.Errorf("error getting referrers of %s: %w", desc.Digest, err)
+	}
+
+	var manifests []v1.Descriptor
+	for _, referrer := range referrers {
+		nested, err := getReferrersRecursive(ctx, target, referrer)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, nested...)
+		manifests = append(manifests, referrer)
+	}
+	return manifests, nil
+}
+
+// PruneSBOMs deletes the SBOMs of the tags of the repository, with their referrers, except the KeepLast most recently
+// created ones and the ones created less than OlderThan ago. The creation time is read from the org.spdx.created
+// annotation of the SBOM manifests, and SBOMs without one are never pruned. SBOMs pushed by digest only are not listed
+// by the tags of the repository and are not pruned either.
+func PruneSBOMs(ctx context.Context, target PruneTarget, opts PruneOptions) (*PruneResult, error) {
+	if opts.KeepLast <= 0 && opts.OlderThan <= 0 {
+		return nil, fmt.Errorf("prune requires the number of SBOMs to keep or their maximum age")
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var tags []string
+	if err := target.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("error listing tags: %w", err)
+	}
+
+	result := &PruneResult{Pruned: []PrunedSBOM{}, Kept: []PrunedSBOM{}, Skipped: []string{}}
+
+	// group the tags of the same manifest
+	sboms := make(map[string]*PrunedSBOM)
+	for _, tag := range tags {
+		desc, manifest, err := FetchManifest(ctx, target, tag)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching manifest of tag %s: %w", tag, err)
+		}
+		if sbom, ok := sboms[desc.Digest.String()]; ok {
+			sbom.Tags = append(sbom.Tags, tag)
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, manifest.Annotations[OCI_ANNOTATION_CREATION_DATE])
+		if !HasSBOMLayer(manifest) || err != nil {
+			result.Skipped = append(result.Skipped, tag)
+			continue
+		}
+		sboms[desc.Digest.String()] = &PrunedSBOM{
+			Descriptor: v1.Descriptor{MediaType: desc.MediaType, ArtifactType: manifest.ArtifactType, Digest: desc.Digest, Size: desc.Size},
+			Tags:       []string{tag},
+			Created:    created,
+		}
+	}
+
+	ordered := make([]*PrunedSBOM, 0, len(sboms))
+	for _, sbom := range sboms {
+		ordered = append(ordered, sbom)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if !ordered[i].Created.Equal(ordered[j].Created) {
+			return ordered[i].Created.After(ordered[j].Created)
+		}
+		return ordered[i].Descriptor.Digest < ordered[j].Descriptor.Digest
+	})
+
+	for i, sbom := range ordered {
+		keep := i < opts.KeepLast || (opts.OlderThan > 0 && now.Sub(sbom.Created) <= opts.OlderThan)
+		if keep {
+			result.Kept = append(result.Kept, *sbom)
+			continue
+		}
+
+		deleted, err := deleteManifest(ctx, target, sbom.Descriptor, DeleteOptions{Cascade: true, DryRun: opts.DryRun})
+		sbom.Deleted = deleted
+		if err != nil {
+			return result, fmt.Errorf("error pruning %s: %w", strings.Join(sbom.Tags, ", "), err)
+		}
+		result.Pruned = append(result.Pruned, *sbom)
+	}
+	return result, nil
+}
+
+// ParseAge parses a duration such as 90d, 2w or 36h, with the days and weeks units added to the units of
+// time.ParseDuration
+func ParseAge(age string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if value, found := strings.CutSuffix(age, suffix); found {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", age)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", age, err)
+	}
+	return duration, nil
+}
@@ -0,0 +1,269 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// deletableStore is an in-memory target that deletes manifests and lists its tags, as a registry supporting the
+// delete API does
+type deletableStore struct {
+	*memory.Store
+	mu      sync.Mutex
+	tags    map[string]digest.Digest
+	deleted map[digest.Digest]bool
+}
+
+func newDeletableStore() *deletableStore {
+	return &deletableStore{
+		Store:   memory.New(),
+		tags:    make(map[string]digest.Digest),
+		deleted: make(map[digest.Digest]bool),
+	}
+}
+
+func (s *deletableStore) isDeleted(d digest.Digest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleted[d]
+}
+
+func (s *deletableStore) Fetch(ctx context.Context, target v1.Descriptor) (io.ReadCloser, error) {
+	if s.isDeleted(target.Digest) {
+		return nil, errdef.ErrNotFound
+	}
+	return s.Store.Fetch(ctx, target)
+}
+
+func (s *deletableStore) Exists(ctx context.Context, target v1.Descriptor) (bool, error) {
+	if s.isDeleted(target.Digest) {
+		return false, nil
+	}
+	return s.Store.Exists(ctx, target)
+}
+
+func (s *deletableStore) Resolve(ctx context.Context, reference string) (v1.Descriptor, error) {
+	desc, err := s.Store.Resolve(ctx, reference)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if s.isDeleted(desc.Digest) {
+		return v1.Descriptor{}, errdef.ErrNotFound
+	}
+	return desc, nil
+}
+
+func (s *deletableStore) Tag(ctx context.Context, desc v1.Descriptor, reference string) error {
+	if err := s.Store.Tag(ctx, desc, reference); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[reference] = desc.Digest
+	return nil
+}
+
+func (s *deletableStore) Predecessors(ctx context.Context, node v1.Descriptor) ([]v1.Descriptor, error) {
+	predecessors, err := s.Store.Predecessors(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	var existing []v1.Descriptor
+	for _, predecessor := range predecessors {
+		if !s.isDeleted(predecessor.Digest) {
+			existing = append(existing, predecessor)
+		}
+	}
+	return existing, nil
+}
+
+func (s *deletableStore) Delete(ctx context.Context, target v1.Descriptor) error {
+	if exists, err := s.Exists(ctx, target); err != nil || !exists {
+		return fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted[target.Digest] = true
+	for tag, d := range s.tags {
+		if d == target.Digest {
+			delete(s.tags, tag)
+		}
+	}
+	return nil
+}
+
+func (s *deletableStore) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	s.mu.Lock()
+	tags := make([]string, 0, len(s.tags))
+	for tag := range s.tags {
+		tags = append(tags, tag)
+	}
+	s.mu.Unlock()
+	sort.Strings(tags)
+	return fn(tags)
+}
+
+// pushTestSBOMCreated pushes the test SBOM with the creation time to the store and returns the manifest descriptor
+func pushTestSBOMCreated(t *testing.T, store *deletableStore, reference string, created string, attachArtifacts map[string][]string) v1.Descriptor {
+	t.Helper()
+
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	annotations, err := GetAnnotations(sbom.SPDXDocument)
+	if err != nil {
+		t.Fatalf("expected no error from GetAnnotations, got: %v", err)
+	}
+	annotations[OCI_ANNOTATION_CREATION_DATE] = created
+
+	result, err := PushSBOMWithOptions(context.Background(), sbom.Document, &sbom.Descriptor, sbom.Bytes, reference, store, PushOptions{
+		Annotations:     annotations,
+		AttachArtifacts: attachArtifacts,
+	})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	return result.Descriptor
+}
+
+func TestDeleteSBOM_Cascade(t *testing.T) {
+	ctx := context.Background()
+	store := newDeletableStore()
+	attachArtifacts := map[string][]string{
+		"application/json": {"../examples/artifact.example.json"},
+		"application/yaml": {"../examples/artifact.example.yaml"},
+	}
+	desc := pushTestSBOMCreated(t, store, "localhost:5000/spdx:v1", "2020-07-23T18:30:22Z", attachArtifacts)
+
+	// a dry run reports the referrers before the SBOM manifest without deleting anything
+	planned, err := DeleteSBOM(ctx, store, "v1", DeleteOptions{Cascade: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error from DeleteSBOM, got: %v", err)
+	}
+	if len(planned) != 3 {
+		t.Fatalf("expected 3 manifests to be deleted, got: %d", len(planned))
+	}
+	if planned[2].Digest != desc.Digest {
+		t.Errorf("expected the SBOM manifest to be deleted last, got: %s", planned[2].Digest)
+	}
+	if _, err := store.Resolve(ctx, "v1"); err != nil {
+		t.Fatalf("expected a dry run to not delete the SBOM, got: %v", err)
+	}
+
+	deleted, err := DeleteSBOM(ctx, store, "v1", DeleteOptions{Cascade: true})
+	if err != nil {
+		t.Fatalf("expected no error from DeleteSBOM, got: %v", err)
+	}
+	if len(deleted) != 3 {
+		t.Fatalf("expected 3 manifests to be deleted, got: %d", len(deleted))
+	}
+	for _, manifest := range deleted {
+		if exists, _ := store.Exists(ctx, manifest); exists {
+			t.Errorf("expected manifest %s to be deleted", manifest.Digest)
+		}
+	}
+}
+
+func TestDeleteSBOM_WithoutCascade(t *testing.T) {
+	ctx := context.Background()
+	store := newDeletableStore()
+	pushTestSBOMCreated(t, store, "localhost:5000/spdx:v1", "2020-07-23T18:30:22Z", map[string][]string{
+		"application/json": {"../examples/artifact.example.json"},
+	})
+
+	deleted, err := DeleteSBOM(ctx, store, "v1", DeleteOptions{})
+	if err != nil {
+		t.Fatalf("expected no error from DeleteSBOM, got: %v", err)
+	}
+	if len(deleted) != 1 {
+		t.Errorf("expected only the SBOM manifest to be deleted, got: %d", len(deleted))
+	}
+}
+
+func TestPruneSBOMs(t *testing.T) {
+	ctx := context.Background()
+	store := newDeletableStore()
+	oldest := pushTestSBOMCreated(t, store, "localhost:5000/spdx:v1", "2024-01-01T00:00:00Z", map[string][]string{
+		"application/json": {"../examples/artifact.example.json"},
+	})
+	old := pushTestSBOMCreated(t, store, "localhost:5000/spdx:v2,stable", "2024-02-01T00:00:00Z", nil)
+	recent := pushTestSBOMCreated(t, store, "localhost:5000/spdx:v3", "2024-05-01T00:00:00Z", nil)
+	latest := pushTestSBOMCreated(t, store, "localhost:5000/spdx:v4", "2024-06-01T00:00:00Z", nil)
+
+	now, _ := time.Parse(time.RFC3339, "2024-06-15T00:00:00Z")
+	opts := PruneOptions{KeepLast: 1, OlderThan: 90 * 24 * time.Hour, Now: now, DryRun: true}
+
+	result, err := PruneSBOMs(ctx, store, opts)
+	if err != nil {
+		t.Fatalf("expected no error from PruneSBOMs, got: %v", err)
+	}
+	if len(result.Pruned) != 2 || result.Pruned[0].Descriptor.Digest != old.Digest || result.Pruned[1].Descriptor.Digest != oldest.Digest {
+		t.Fatalf("expected the two SBOMs older than 90 days to be pruned, got: %v", result.Pruned)
+	}
+	if len(result.Kept) != 2 || result.Kept[0].Descriptor.Digest != latest.Digest || result.Kept[1].Descriptor.Digest != recent.Digest {
+		t.Errorf("expected the two recent SBOMs to be kept, got: %v", result.Kept)
+	}
+	if strings.Join(result.Pruned[0].Tags, ",") != "stable,v2" {
+		t.Errorf("expected the tags of the pruned SBOM to be grouped, got: %v", result.Pruned[0].Tags)
+	}
+	if len(result.Pruned[1].Deleted) != 2 {
+		t.Errorf("expected the SBOM to be pruned with its referrer, got: %d manifests", len(result.Pruned[1].Deleted))
+	}
+	if _, err := store.Resolve(ctx, "v1"); err != nil {
+		t.Fatalf("expected a dry run to not delete the SBOM, got: %v", err)
+	}
+
+	opts.DryRun = false
+	if _, err := PruneSBOMs(ctx, store, opts); err != nil {
+		t.Fatalf("expected no error from PruneSBOMs, got: %v", err)
+	}
+	for _, tag := range []string{"v1", "v2", "stable"} {
+		if _, err := store.Resolve(ctx, tag); err == nil {
+			t.Errorf("expected tag %s to be pruned", tag)
+		}
+	}
+	for _, tag := range []string{"v3", "v4"} {
+		if _, err := store.Resolve(ctx, tag); err != nil {
+			t.Errorf("expected tag %s to be kept, got: %v", tag, err)
+		}
+	}
+}
+
+func TestPruneSBOMs_RequiresCriteria(t *testing.T) {
+	if _, err := PruneSBOMs(context.Background(), newDeletableStore(), PruneOptions{}); err == nil {
+		t.Fatalf("expected error without the number of SBOMs to keep or their maximum age, got no error")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	}
+	for age, expected := range tests {
+		duration, err := ParseAge(age)
+		if err != nil {
+			t.Fatalf("expected no error from ParseAge(%s), got: %v", age, err)
+		}
+		if duration != expected {
+			t.Errorf("expected %s to be %v, got: %v", age, expected, duration)
+		}
+	}
+
+	if _, err := ParseAge("ninety days"); err == nil {
+		t.Errorf("expected error for an invalid age, got no error")
+	}
+}
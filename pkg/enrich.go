@@ -0,0 +1,438 @@
+package obom
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	purl "github.com/package-url/packageurl-go"
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ENRICH_ANNOTATOR is the default tool recorded as the annotator of the enrichment annotations
+	ENRICH_ANNOTATOR = "obom"
+
+	ENRICH_FIELD_PURL              = "purl"
+	ENRICH_FIELD_CPE               = "cpe"
+	ENRICH_FIELD_LICENSE_DECLARED  = "licenseDeclared"
+	ENRICH_FIELD_LICENSE_CONCLUDED = "licenseConcluded"
+	ENRICH_FIELD_SUPPLIER          = "supplier"
+	ENRICH_FIELD_CHECKSUM          = "checksum"
+)
+
+// EnrichOptions contains the optional parameters of EnrichSBOM
+type EnrichOptions struct {
+	// Suppliers are the rules filling the supplier of the packages without one, the first matching rule applies
+	Suppliers []SupplierRule
+	// Annotator is the tool recorded as the annotator of the enrichment annotations, defaulting to ENRICH_ANNOTATOR
+	Annotator string
+	// Now is the date of the enrichment annotations, defaulting to the current time
+	Now time.Time
+}
+
+// Enrichment is a change made to a package by EnrichSBOM, also recorded as an SPDX annotation of the package
+type Enrichment struct {
+	SPDXID string `json:"spdxId"`
+	// Field is the changed field of the package, one of the ENRICH_FIELD_ constants
+	Field string `json:"field"`
+	// Previous is the value replaced by a normalization, empty when the field was added
+	Previous string `json:"previous,omitempty"`
+	Value    string `json:"value"`
+	// Reason is how the value was inferred
+	Reason string `json:"reason"`
+}
+
+// SupplierRule fills the supplier of the packages matching its PURL prefix or its name pattern
+type SupplierRule struct {
+	// PURL is a prefix of the package URLs of the packages, such as pkg:npm/%40angular/
+	PURL string `json:"purl,omitempty" yaml:"purl,omitempty"`
+	// Name is a pattern of the names of the packages in the syntax of path.Match, such as github.com/Azure/*
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Supplier is the SPDX supplier of the packages, such as "Organization: Microsoft Corporation"
+	Supplier string `json:"supplier" yaml:"supplier"`
+}
+
+// SupplierFile is the format of the supplier mapping files of obom enrich --suppliers
+type SupplierFile struct {
+	Suppliers []SupplierRule `json:"suppliers" yaml:"suppliers"`
+}
+
+// LoadSupplierFile loads the rules of a JSON or YAML supplier mapping file
+func LoadSupplierFile(filename string) ([]SupplierRule, error) {
+	fileBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading supplier file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML
+	var file SupplierFile
+	if err := yaml.Unmarshal(fileBytes, &file); err != nil {
+		return nil, fmt.Errorf("error parsing supplier file %s: %w", filename, err)
+	}
+
+	for i, rule := range file.Suppliers {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("error in supplier %d of %s: %w", i, filename, err)
+		}
+	}
+	return file.Suppliers, nil
+}
+
+// validate checks that the rule matches packages and that the supplier is a person or an organization
+func (r SupplierRule) validate() error {
+	if r.PURL == "" && r.Name == "" {
+		return fmt.Errorf("missing purl or name to match the packages of supplier %q", r.Supplier)
+	}
+	if _, err := path.Match(r.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q: %w", r.Name, err)
+	}
+	if _, err := parseSupplier(r.Supplier); err != nil {
+		return err
+	}
+	return nil
+}
+
+// matches reports whether the package matches the PURL prefix or the name pattern of the rule
+func (r SupplierRule) matches(pkg *v2_3.Package) bool {
+	if r.PURL != "" {
+		if packageURL := getPURL(pkg); packageURL != "" && strings.HasPrefix(packageURL, r.PURL) {
+			return true
+		}
+	}
+	if r.Name != "" {
+		if matched, _ := path.Match(r.Name, pkg.PackageName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSupplier parses an SPDX supplier in the format of Person: name or Organization: name
+func parseSupplier(supplier string) (*v2common.Supplier, error) {
+	supplierType, name, found := strings.Cut(supplier, ": ")
+	if !found || name == "" || (supplierType != "Person" && supplierType != "Organization") {
+		return nil, fmt.Errorf("invalid supplier %q, expected Person: name or Organization: name", supplier)
+	}
+	return &v2common.Supplier{SupplierType: supplierType, Supplier: name}, nil
+}
+
+// EnrichSBOM fills the data missing from the packages of the SPDX document: package URLs inferred from the download
+// location or the name of the packages, CPE 2.3 names, suppliers from the supplier rules, license strings normalized
+// to SPDX license identifiers and checksums normalized to lowercase. The document is changed in place and every
+// change is recorded as an annotation of the package, which are returned in order.
+func EnrichSBOM(sbom *v2_3.Document, opts EnrichOptions) []Enrichment {
+	annotator := opts.Annotator
+	if annotator == "" {
+		annotator = ENRICH_ANNOTATOR
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var enrichments []Enrichment
+	for _, pkg := range sbom.Packages {
+		if pkg == nil {
+			continue
+		}
+		changes := enrichPackage(pkg, opts.Suppliers)
+		for _, change := range changes {
+			pkg.Annotations = append(pkg.Annotations, v2_3.Annotation{
+				Annotator:                v2common.Annotator{AnnotatorType: "Tool", Annotator: annotator},
+				AnnotationDate:           now.UTC().Format(time.RFC3339),
+				AnnotationType:           "OTHER",
+				AnnotationSPDXIdentifier: v2common.DocElementID{ElementRefID: pkg.PackageSPDXIdentifier},
+				AnnotationComment:        change.comment(),
+			})
+		}
+		enrichments = append(enrichments, changes...)
+	}
+	return enrichments
+}
+
+// comment returns the annotation comment recording the change
+func (e Enrichment) comment() string {
+	if e.Previous != "" {
+		return fmt.Sprintf("obom enrich: %s normalized from %s to %s (%s)", e.Field, e.Previous, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("obom enrich: %s set to %s (%s)", e.Field, e.Value, e.Reason)
+}
+
+// enrichPackage fills the data missing from the package and returns the changes
+func enrichPackage(pkg *v2_3.Package, suppliers []SupplierRule) []Enrichment {
+	id := getElementID(pkg.PackageSPDXIdentifier)
+	var changes []Enrichment
+
+	if getPURL(pkg) == "" {
+		if packageURL, reason := inferPURL(pkg); packageURL != "" {
+			pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+				Category: v2common.CategoryPackageManager,
+				RefType:  v2common.TypePackageManagerPURL,
+				Locator:  packageURL,
+			})
+			changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_PURL, Value: packageURL, Reason: reason})
+		}
+	}
+
+	if pkg.PackageSupplier == nil || pkg.PackageSupplier.Supplier == "" || pkg.PackageSupplier.Supplier == noAssertion {
+		for _, rule := range suppliers {
+			if !rule.matches(pkg) {
+				continue
+			}
+			// the rules are validated when loaded
+			if supplier, err := parseSupplier(rule.Supplier); err == nil {
+				pkg.PackageSupplier = supplier
+				changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_SUPPLIER, Value: rule.Supplier, Reason: "supplier mapping " + rule.pattern()})
+			}
+			break
+		}
+	}
+
+	if !hasCPE(pkg) {
+		if cpe := generateCPE(pkg); cpe != "" {
+			pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+				Category: v2common.CategorySecurity,
+				RefType:  v2common.TypeSecurityCPE23Type,
+				Locator:  cpe,
+			})
+			changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_CPE, Value: cpe, Reason: "generated from the supplier, name and version"})
+		}
+	}
+
+	if license, ok := NormalizeLicense(pkg.PackageLicenseDeclared); ok {
+		changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_LICENSE_DECLARED, Previous: pkg.PackageLicenseDeclared, Value: license, Reason: "SPDX license identifier"})
+		pkg.PackageLicenseDeclared = license
+	}
+	if license, ok := NormalizeLicense(pkg.PackageLicenseConcluded); ok {
+		changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_LICENSE_CONCLUDED, Previous: pkg.PackageLicenseConcluded, Value: license, Reason: "SPDX license identifier"})
+		pkg.PackageLicenseConcluded = license
+	}
+
+	// SPDX checksums are lowercase hexadecimal values
+	for i, checksum := range pkg.PackageChecksums {
+		if value := strings.ToLower(checksum.Value); value != checksum.Value {
+			changes = append(changes, Enrichment{SPDXID: id, Field: ENRICH_FIELD_CHECKSUM, Previous: string(checksum.Algorithm) + ": " + checksum.Value, Value: string(checksum.Algorithm) + ": " + value, Reason: "lowercase hexadecimal"})
+			pkg.PackageChecksums[i].Value = value
+		}
+	}
+
+	return changes
+}
+
+// pattern returns the PURL prefix or the name pattern of the rule
+func (r SupplierRule) pattern() string {
+	if r.PURL != "" {
+		return r.PURL
+	}
+	return r.Name
+}
+
+// getPURL returns the first package URL of the package, or an empty string
+func getPURL(pkg *v2_3.Package) string {
+	for _, exRef := range pkg.PackageExternalReferences {
+		if exRef != nil && exRef.RefType == v2common.TypePackageManagerPURL {
+			return exRef.Locator
+		}
+	}
+	return ""
+}
+
+// hasCPE reports whether the package has a CPE 2.2 or 2.3 name
+func hasCPE(pkg *v2_3.Package) bool {
+	for _, exRef := range pkg.PackageExternalReferences {
+		if exRef != nil && (exRef.RefType == v2common.TypeSecurityCPE22Type || exRef.RefType == v2common.TypeSecurityCPE23Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// getVersion returns the version of the package, or an empty string when it is not set
+func getVersion(pkg *v2_3.Package) string {
+	if pkg.PackageVersion == noAssertion {
+		return ""
+	}
+	return pkg.PackageVersion
+}
+
+// inferPURL infers the package URL of the package from its download location, or from its name when it is a Go
+// module path, and returns it with the reason, or empty strings when it cannot be inferred
+func inferPURL(pkg *v2_3.Package) (string, string) {
+	version := getVersion(pkg)
+	if packageURL := purlFromLocation(pkg.PackageDownloadLocation, pkg.PackageName, version); packageURL != nil {
+		return packageURL.ToString(), "inferred from the download location"
+	}
+	if namespace, name, ok := splitGoModule(pkg.PackageName); ok {
+		return purl.NewPackageURL(purl.TypeGolang, namespace, name, version, nil, "").ToString(), "inferred from the Go module name"
+	}
+	return "", ""
+}
+
+// purlFromLocation infers the package URL from a download location of a package registry or a GitHub repository
+func purlFromLocation(location, name, version string) *purl.PackageURL {
+	if source, _, ok := parseVCSLocation(location); ok {
+		location = source
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch u.Host {
+	case "registry.npmjs.org", "registry.yarnpkg.com":
+		// /<name>/-/<name>-<version>.tgz or /@<scope>/<name>/-/<name>-<version>.tgz
+		if len(segments) >= 2 && strings.HasPrefix(segments[0], "@") {
+			return purl.NewPackageURL(purl.TypeNPM, segments[0], segments[1], version, nil, "")
+		}
+		if len(segments) >= 1 && segments[0] != "" {
+			return purl.NewPackageURL(purl.TypeNPM, "", segments[0], version, nil, "")
+		}
+	case "files.pythonhosted.org", "pypi.org", "pypi.python.org":
+		if name != "" {
+			return purl.NewPackageURL(purl.TypePyPi, "", normalizePyPiName(name), version, nil, "")
+		}
+	case "proxy.golang.org":
+		// /<module>/@v/<version>.zip
+		for i, segment := range segments {
+			if segment == "@v" {
+				if namespace, module, ok := splitGoModule(unescapeGoModule(strings.Join(segments[:i], "/"))); ok {
+					return purl.NewPackageURL(purl.TypeGolang, namespace, module, version, nil, "")
+				}
+			}
+		}
+	case "repo1.maven.org", "repo.maven.apache.org", "search.maven.org":
+		// /maven2/<group path>/<artifact>/<version>/<file>, or the filepath query parameter of search.maven.org
+		mavenPath := strings.TrimPrefix(strings.Trim(u.Path, "/"), "maven2/")
+		if filepath := u.Query().Get("filepath"); filepath != "" {
+			mavenPath = strings.Trim(filepath, "/")
+		}
+		if parts := strings.Split(mavenPath, "/"); len(parts) >= 4 {
+			group := strings.Join(parts[:len(parts)-3], ".")
+			artifact, artifactVersion := parts[len(parts)-3], parts[len(parts)-2]
+			if version == "" {
+				version = artifactVersion
+			}
+			return purl.NewPackageURL(purl.TypeMaven, group, artifact, version, nil, "")
+		}
+	case "rubygems.org":
+		if name != "" {
+			return purl.NewPackageURL(purl.TypeGem, "", name, version, nil, "")
+		}
+	case "crates.io", "static.crates.io":
+		// /api/v1/crates/<name>/<version>/download or /crates/<name>/<name>-<version>.crate
+		for i, segment := range segments {
+			if segment == "crates" && i+1 < len(segments) {
+				return purl.NewPackageURL(purl.TypeCargo, "", segments[i+1], version, nil, "")
+			}
+		}
+	case "www.nuget.org", "api.nuget.org":
+		if name != "" {
+			return purl.NewPackageURL(purl.TypeNuget, "", name, version, nil, "")
+		}
+	case "github.com":
+		if len(segments) >= 2 {
+			return purl.NewPackageURL(purl.TypeGithub, strings.ToLower(segments[0]), strings.ToLower(strings.TrimSuffix(segments[1], ".git")), version, nil, "")
+		}
+	}
+	return nil
+}
+
+// splitGoModule splits a Go module path such as github.com/spf13/cobra into the namespace and the name of its package
+// URL, reporting false when the name is not a module path with a domain
+func splitGoModule(module string) (string, string, bool) {
+	slash := strings.LastIndex(module, "/")
+	if slash < 0 || strings.ContainsAny(module, " @:") {
+		return "", "", false
+	}
+	domain, _, _ := strings.Cut(module, "/")
+	if !strings.Contains(domain, ".") {
+		return "", "", false
+	}
+	return module[:slash], module[slash+1:], true
+}
+
+// unescapeGoModule reverses the case encoding of the module paths of the Go module proxy, where an uppercase letter
+// is written as ! followed by the lowercase letter
+func unescapeGoModule(escaped string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range escaped {
+		if r == '!' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = []rune(strings.ToUpper(string(r)))[0]
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// normalizePyPiName normalizes a Python package name as the PyPI package URLs do
+func normalizePyPiName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// cpeSpecialCharacters are the characters escaped in the attributes of CPE 2.3 formatted strings
+var cpeSpecialCharacters = regexp.MustCompile(`[^a-z0-9._\-]`)
+
+// generateCPE generates the CPE 2.3 name of an application package from its vendor, name and version, or returns an
+// empty string when the package has no version. The vendor is the supplier organization, the namespace of the
+// package URL, or the name of the package.
+func generateCPE(pkg *v2_3.Package) string {
+	version := getVersion(pkg)
+	if version == "" || pkg.PackageName == "" {
+		return ""
+	}
+
+	product := pkg.PackageName
+	vendor := ""
+	if pkg.PackageSupplier != nil && pkg.PackageSupplier.SupplierType == "Organization" {
+		vendor = pkg.PackageSupplier.Supplier
+	}
+	if packageURL, err := purl.FromString(getPURL(pkg)); err == nil {
+		product = packageURL.Name
+		if vendor == "" {
+			vendor = getPURLVendor(packageURL)
+		}
+	}
+	if vendor == "" {
+		vendor = product
+	}
+
+	return fmt.Sprintf("cpe:2.3:a:%s:%s:%s:*:*:*:*:*:*:*", escapeCPE(vendor), escapeCPE(product), escapeCPE(version))
+}
+
+// getPURLVendor returns the vendor of a package URL, such as the GitHub organization of a Go module or the
+// organization of a Maven group
+func getPURLVendor(packageURL purl.PackageURL) string {
+	namespace := strings.TrimPrefix(packageURL.Namespace, "@")
+	if namespace == "" {
+		return ""
+	}
+	if packageURL.Type == purl.TypeMaven {
+		// org.apache.commons is supplied by apache
+		if parts := strings.Split(namespace, "."); len(parts) >= 2 {
+			return parts[1]
+		}
+		return namespace
+	}
+	return path.Base(namespace)
+}
+
+// escapeCPE returns the value as an attribute of a CPE 2.3 formatted string, in lowercase with spaces replaced by
+// underscores and the special characters escaped
+func escapeCPE(value string) string {
+	value = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), " ", "_")
+	return cpeSpecialCharacters.ReplaceAllStringFunc(value, func(s string) string { return `\` + s })
+}
@@ -0,0 +1,144 @@
+package obom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+func TestEnrichSBOM(t *testing.T) {
+	sbom := &v2_3.Document{
+		Packages: []*v2_3.Package{
+			{
+				PackageSPDXIdentifier:   "Package-cobra",
+				PackageName:             "github.com/spf13/cobra",
+				PackageVersion:          "v1.8.0",
+				PackageDownloadLocation: "NOASSERTION",
+				PackageLicenseDeclared:  "Apache 2.0",
+			},
+			{
+				PackageSPDXIdentifier:   "Package-lodash",
+				PackageName:             "lodash",
+				PackageVersion:          "4.17.21",
+				PackageDownloadLocation: "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+				PackageLicenseDeclared:  "mit",
+				PackageChecksums:        []v2common.Checksum{{Algorithm: v2common.SHA1, Value: "679591C564C3BFFAAE8454CF0B3DF370C3D6911C"}},
+			},
+			{
+				PackageSPDXIdentifier:   "Package-jena",
+				PackageName:             "Jena",
+				PackageVersion:          "3.12.0",
+				PackageDownloadLocation: "https://search.maven.org/remotecontent?filepath=org/apache/jena/apache-jena/3.12.0/apache-jena-3.12.0.tar.gz",
+				PackageLicenseDeclared:  "(LGPL-2.0-only AND LicenseRef-3)",
+			},
+		},
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-06-01T00:00:00Z")
+	enrichments := EnrichSBOM(sbom, EnrichOptions{
+		Suppliers: []SupplierRule{{Name: "github.com/spf13/*", Supplier: "Organization: spf13"}},
+		Now:       now,
+	})
+
+	values := make(map[string]string)
+	for _, enrichment := range enrichments {
+		values[enrichment.SPDXID+" "+enrichment.Field] = enrichment.Value
+	}
+	expected := map[string]string{
+		"SPDXRef-Package-cobra purl":             "pkg:golang/github.com/spf13/cobra@v1.8.0",
+		"SPDXRef-Package-cobra supplier":         "Organization: spf13",
+		"SPDXRef-Package-cobra cpe":              "cpe:2.3:a:spf13:cobra:v1.8.0:*:*:*:*:*:*:*",
+		"SPDXRef-Package-cobra licenseDeclared":  "Apache-2.0",
+		"SPDXRef-Package-lodash purl":            "pkg:npm/lodash@4.17.21",
+		"SPDXRef-Package-lodash cpe":             "cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:*:*:*",
+		"SPDXRef-Package-lodash licenseDeclared": "MIT",
+		"SPDXRef-Package-lodash checksum":        "SHA1: 679591c564c3bffaae8454cf0b3df370c3d6911c",
+		"SPDXRef-Package-jena purl":              "pkg:maven/org.apache.jena/apache-jena@3.12.0",
+		"SPDXRef-Package-jena cpe":               "cpe:2.3:a:apache:apache-jena:3.12.0:*:*:*:*:*:*:*",
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %s, got: %s", key, value, values[key])
+		}
+	}
+	if len(enrichments) != len(expected) {
+		t.Errorf("expected %d enrichments, got: %d", len(expected), len(enrichments))
+	}
+
+	// the enriched packages are listed by their package manager and every change is annotated
+	packageManager, err := GetPackageManager(sbom.Packages[0].PackageExternalReferences)
+	if err != nil || packageManager != "golang" {
+		t.Errorf("expected package manager golang, got: %s, %v", packageManager, err)
+	}
+	annotations := sbom.Packages[1].Annotations
+	if len(annotations) != 4 {
+		t.Fatalf("expected 4 annotations of the enriched package, got: %d", len(annotations))
+	}
+	if annotations[0].AnnotationDate != "2024-06-01T00:00:00Z" || annotations[0].Annotator.Annotator != ENRICH_ANNOTATOR {
+		t.Errorf("expected the annotation date and annotator to be recorded, got: %+v", annotations[0])
+	}
+	if !strings.Contains(annotations[0].AnnotationComment, "pkg:npm/lodash@4.17.21") {
+		t.Errorf("expected the annotation comment to record the package URL, got: %s", annotations[0].AnnotationComment)
+	}
+
+	// enriching again changes nothing
+	if enrichments := EnrichSBOM(sbom, EnrichOptions{}); len(enrichments) != 0 {
+		t.Errorf("expected no enrichments of an enriched SBOM, got: %v", enrichments)
+	}
+}
+
+func TestNormalizeLicense(t *testing.T) {
+	tests := map[string]string{
+		"Apache License, Version 2.0":          "Apache-2.0",
+		"mit":                                  "MIT",
+		"GPL-2.0+":                             "GPL-2.0-or-later",
+		"MIT/Apache-2.0":                       "MIT OR Apache-2.0",
+		"(mit or apache-2.0) and BSD-3-Clause": "(MIT OR Apache-2.0) AND BSD-3-Clause",
+		"GPL-2.0-only with Classpath-exception-2.0": "GPL-2.0-only WITH Classpath-exception-2.0",
+	}
+	for license, expected := range tests {
+		normalized, ok := NormalizeLicense(license)
+		if !ok || normalized != expected {
+			t.Errorf("expected %q to be normalized to %q, got: %q", license, expected, normalized)
+		}
+	}
+
+	for _, license := range []string{"MIT", "NOASSERTION", "(LGPL-2.0-only AND LicenseRef-3)", "Some Custom License"} {
+		if normalized, ok := NormalizeLicense(license); ok {
+			t.Errorf("expected %q to be left as is, got: %q", license, normalized)
+		}
+	}
+}
+
+func TestLoadSupplierFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "suppliers.yaml")
+	content := `suppliers:
+  - purl: pkg:npm/
+    supplier: "Organization: npm community"
+  - name: "github.com/Azure/*"
+    supplier: "Organization: Microsoft Corporation"
+`
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing supplier file: %v", err)
+	}
+	rules, err := LoadSupplierFile(filename)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSupplierFile, got: %v", err)
+	}
+	if len(rules) != 2 || rules[1].Name != "github.com/Azure/*" {
+		t.Errorf("expected 2 supplier rules, got: %v", rules)
+	}
+
+	if err := os.WriteFile(filename, []byte("suppliers:\n  - name: lodash\n    supplier: Microsoft\n"), 0644); err != nil {
+		t.Fatalf("error writing supplier file: %v", err)
+	}
+	if _, err := LoadSupplierFile(filename); err == nil {
+		t.Errorf("expected error for a supplier without its type, got no error")
+	}
+}
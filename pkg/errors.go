@@ -0,0 +1,14 @@
+package obom
+
+import "errors"
+
+var (
+	// ErrNotSPDX is returned when a document is not an SPDX JSON document
+	ErrNotSPDX = errors.New("not an SPDX document")
+	// ErrStrictParse is returned when strict parsing is enabled and the document does not conform to the SPDX specification
+	ErrStrictParse = errors.New("SPDX document does not conform to the SPDX specification")
+	// ErrReferenceInvalid is returned when a reference cannot be parsed
+	ErrReferenceInvalid = errors.New("invalid reference")
+	// ErrDigestMismatch is returned when an SBOM is pushed to a digest reference that does not match its manifest
+	ErrDigestMismatch = errors.New("digest mismatch")
+)
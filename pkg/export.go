@@ -0,0 +1,62 @@
+package obom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// PushExport is the machine-readable record of a pushed SBOM for the following steps of a pipeline
+type PushExport struct {
+	// Reference is the reference of the SBOM manifest by digest
+	Reference string `json:"reference"`
+	// Tags are the tags of the SBOM manifest, empty when pushed by digest only
+	Tags []string `json:"tags"`
+	// Descriptor is the descriptor of the SBOM manifest
+	Descriptor v1.Descriptor `json:"descriptor"`
+	// Manifest is the SBOM manifest as pushed
+	Manifest json.RawMessage `json:"manifest"`
+	// Referrers are the descriptors of the manifests of the artifacts attached to the SBOM
+	Referrers []v1.Descriptor `json:"referrers"`
+}
+
+// NewPushExport returns the record of the SBOM pushed to the repository, in the format of registry/repository
+func NewPushExport(repository string, result *PushResult) PushExport {
+	export := PushExport{
+		Reference:  repository + "@" + result.Descriptor.Digest.String(),
+		Tags:       result.Tags,
+		Descriptor: result.Descriptor,
+		Manifest:   result.Manifest,
+		Referrers:  result.Referrers,
+	}
+	if export.Tags == nil {
+		export.Tags = []string{}
+	}
+	if export.Referrers == nil {
+		export.Referrers = []v1.Descriptor{}
+	}
+	return export
+}
+
+// WritePushExport writes the record of the push as JSON to the file. The JSON is not indented, so that the manifest
+// is written byte for byte as pushed and matches the digest of its descriptor.
+func WritePushExport(filename string, export PushExport) error {
+	exportBytes, err := json.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("error marshaling push export: %w", err)
+	}
+	if err := os.WriteFile(filename, append(exportBytes, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing push export: %w", err)
+	}
+	return nil
+}
+
+// WriteDigestFile writes the digest of the SBOM manifest to the file
+func WriteDigestFile(filename string, result *PushResult) error {
+	if err := os.WriteFile(filename, []byte(result.Descriptor.Digest.String()), 0644); err != nil {
+		return fmt.Errorf("error writing digest file: %w", err)
+	}
+	return nil
+}
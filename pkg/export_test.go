@@ -0,0 +1,72 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestWritePushExport(t *testing.T) {
+	ctx := context.Background()
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", memory.New(), PushOptions{
+		AttachArtifacts: map[string][]string{"application/json": {"../examples/artifact.example.json"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	dir := t.TempDir()
+	exportFile := filepath.Join(dir, "push.json")
+	if err := WritePushExport(exportFile, NewPushExport("localhost:5000/spdx", result)); err != nil {
+		t.Fatalf("expected no error from WritePushExport, got: %v", err)
+	}
+
+	exportBytes, err := os.ReadFile(exportFile)
+	if err != nil {
+		t.Fatalf("error reading push export: %v", err)
+	}
+	var export PushExport
+	if err := json.Unmarshal(exportBytes, &export); err != nil {
+		t.Fatalf("error unmarshaling push export: %v", err)
+	}
+
+	expectedReference := "localhost:5000/spdx@" + result.Descriptor.Digest.String()
+	if export.Reference != expectedReference {
+		t.Errorf("expected reference %s, got: %s", expectedReference, export.Reference)
+	}
+	if len(export.Tags) != 1 || export.Tags[0] != "v1" {
+		t.Errorf("expected tags [v1], got: %v", export.Tags)
+	}
+	if export.Descriptor.Digest != result.Descriptor.Digest {
+		t.Errorf("expected descriptor digest %s, got: %s", result.Descriptor.Digest, export.Descriptor.Digest)
+	}
+	if digest.FromBytes(export.Manifest) != result.Descriptor.Digest {
+		t.Errorf("expected the exported manifest to match the digest %s", result.Descriptor.Digest)
+	}
+	if len(export.Referrers) != 1 || export.Referrers[0].ArtifactType != "application/json" {
+		t.Errorf("expected the descriptor of the attached artifact, got: %v", export.Referrers)
+	}
+
+	digestFile := filepath.Join(dir, "digest.txt")
+	if err := WriteDigestFile(digestFile, result); err != nil {
+		t.Fatalf("expected no error from WriteDigestFile, got: %v", err)
+	}
+	digestBytes, err := os.ReadFile(digestFile)
+	if err != nil {
+		t.Fatalf("error reading digest file: %v", err)
+	}
+	if string(digestBytes) != result.Descriptor.Digest.String() {
+		t.Errorf("expected digest file to contain %s, got: %s", result.Descriptor.Digest, digestBytes)
+	}
+}
@@ -0,0 +1,209 @@
+package obom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// FetchResult is an SBOM fetched from a registry along with the descriptor of its manifest
+type FetchResult struct {
+	SBOM
+	// Manifest is the descriptor of the SBOM manifest
+	Manifest v1.Descriptor
+}
+
+// FetchSBOMArtifact fetches the SBOM artifact tagged or identified by reference from the source and loads the SPDX
+// document from its SBOM layer. Compressed layers are decompressed, and the descriptor of the SBOM describes the
+// decompressed document.
+func FetchSBOMArtifact(ctx context.Context, src oras.ReadOnlyTarget, reference string, strict bool) (*FetchResult, error) {
+	manifestDesc, manifest, err := FetchManifest(ctx, src, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := GetSBOMLayer(manifest)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("fetching SBOM layer", "manifest", manifestDesc.Digest, "digest", layer.Digest, "mediaType", layer.MediaType, "size", layer.Size)
+
+	var sbom *SBOM
+	if isChunked(layer) {
+		sbomBytes, err := fetchChunkedSBOM(ctx, src, manifest, layer)
+		if err != nil {
+			return nil, err
+		}
+		if sbom, err = ReadSBOM(bytes.NewReader(sbomBytes), strict); err != nil {
+			return nil, err
+		}
+	} else {
+		layerReader, err := src.Fetch(ctx, layer)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching SBOM layer: %w", err)
+		}
+		defer layerReader.Close()
+		verifyReader := content.NewVerifyReader(layerReader, layer)
+		if sbom, err = ReadSBOM(verifyReader, strict); err != nil {
+			return nil, err
+		}
+		if err := verifyContent(verifyReader); err != nil {
+			return nil, fmt.Errorf("error verifying SBOM layer: %w", err)
+		}
+	}
+
+	// keep the title of the pushed layer
+	if title := layer.Annotations[v1.AnnotationTitle]; title != "" {
+		AddFilenameAnnotationIfMissing(&sbom.Descriptor, title)
+	}
+
+	return &FetchResult{SBOM: *sbom, Manifest: *manifestDesc}, nil
+}
+
+// FetchSBOM fetches the SBOM artifact tagged or identified by reference from the source and loads the SPDX document
+// from its SBOM layer, see FetchSBOMArtifact.
+//
+// Deprecated: use FetchSBOMArtifact, which returns the document, the descriptor and the bytes as a single result
+// along with the manifest descriptor.
+func FetchSBOM(ctx context.Context, src oras.ReadOnlyTarget, reference string, strict bool) (*SPDXDocument, *v1.Descriptor, []byte, error) {
+	result, err := FetchSBOMArtifact(ctx, src, reference, strict)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result.SPDXDocument, &result.Descriptor, result.Bytes, nil
+}
+
+// FetchManifest resolves the reference in the source and fetches the manifest it points to
+func FetchManifest(ctx context.Context, src oras.ReadOnlyTarget, reference string) (*v1.Descriptor, *v1.Manifest, error) {
+	desc, manifestBytes, err := oras.FetchBytes(ctx, src, reference, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching manifest: %w", err)
+	}
+
+	var manifest v1.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshaling manifest: %w", err)
+	}
+
+	return &desc, &manifest, nil
+}
+
+// GetSBOMLayer returns the SBOM layer of the manifest, which is the first layer with an SPDX media type or the header
+// layer of a chunked SBOM, compressed or not, or the first layer if there is none
+func GetSBOMLayer(manifest *v1.Manifest) (v1.Descriptor, error) {
+	if len(manifest.Layers) == 0 {
+		return v1.Descriptor{}, fmt.Errorf("manifest has no layers")
+	}
+
+	for _, layer := range manifest.Layers {
+		if isSBOMLayer(layer) {
+			return layer, nil
+		}
+	}
+
+	return manifest.Layers[0], nil
+}
+
+// HasSBOMLayer reports whether the manifest has a layer with an SPDX media type or the header layer of a chunked SBOM,
+// compressed or not
+func HasSBOMLayer(manifest *v1.Manifest) bool {
+	for _, layer := range manifest.Layers {
+		if isSBOMLayer(layer) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSBOMLayer reports whether the layer has an SPDX media type or is the header layer of a chunked SBOM
+func isSBOMLayer(layer v1.Descriptor) bool {
+	mediaType, _ := UncompressedMediaType(layer.MediaType)
+	return mediaType == MEDIATYPE_SPDX || mediaType == MEDIATYPE_TEXT_SPDX || mediaType == MEDIATYPE_SPDX_HEADER
+}
+
+// fetchChunkedSBOM fetches the header and chunk layers of a chunked SBOM and reassembles the original document,
+// verified against the digest and size kept in the header layer annotations
+func fetchChunkedSBOM(ctx context.Context, src oras.ReadOnlyTarget, manifest *v1.Manifest, headerLayer v1.Descriptor) ([]byte, error) {
+	expected, err := digest.Parse(headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_DIGEST])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the digest of the chunked SBOM: %w", err)
+	}
+	expectedSize, err := strconv.ParseInt(headerLayer.Annotations[OCI_ANNOTATION_CHUNKED_SIZE], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the size of the chunked SBOM: %w", err)
+	}
+
+	header, err := fetchLayerBytes(ctx, src, headerLayer)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching SBOM header layer: %w", err)
+	}
+
+	chunkLayers, offsets, err := getChunkLayers(manifest)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]SBOMChunk, len(chunkLayers))
+	for i, layer := range chunkLayers {
+		chunkBytes, err := fetchLayerBytes(ctx, src, layer)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching SBOM chunk %d: %w", i, err)
+		}
+		chunks[i] = SBOMChunk{
+			Field:  layer.Annotations[OCI_ANNOTATION_CHUNK_FIELD],
+			Offset: offsets[i],
+			Bytes:  chunkBytes,
+		}
+	}
+
+	sbomBytes, err := JoinSBOM(header, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("error reassembling SBOM chunks: %w", err)
+	}
+	if int64(len(sbomBytes)) != expectedSize || digest.FromBytes(sbomBytes) != expected {
+		return nil, fmt.Errorf("reassembled SBOM does not match the chunked SBOM digest %s", expected)
+	}
+	return sbomBytes, nil
+}
+
+// fetchLayerBytes fetches the layer content, verified against the layer descriptor, and decompresses it
+func fetchLayerBytes(ctx context.Context, src oras.ReadOnlyTarget, layer v1.Descriptor) ([]byte, error) {
+	layerReader, err := src.Fetch(ctx, layer)
+	if err != nil {
+		return nil, err
+	}
+	defer layerReader.Close()
+
+	verifyReader := content.NewVerifyReader(layerReader, layer)
+	reader, err := DecompressReader(io.NopCloser(verifyReader))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	layerBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyContent(verifyReader); err != nil {
+		return nil, err
+	}
+	return layerBytes, nil
+}
+
+// verifyContent reads the rest of the content and verifies it against its descriptor. The reader only checks the size
+// while reading, the digest is checked once the content is read to the end.
+func verifyContent(reader *content.VerifyReader) error {
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return err
+	}
+	return reader.Verify()
+}
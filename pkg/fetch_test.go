@@ -0,0 +1,130 @@
+package obom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestFetchSBOM_CompressedLayer(t *testing.T) {
+	ctx := context.Background()
+	memDest := memory.New()
+
+	doc, desc, sbomBytes, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+
+	_, err = PushSBOMWithOptions(ctx, doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{Compression: COMPRESSION_GZIP})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+	layer := manifest.Layers[0]
+	if layer.MediaType != MEDIATYPE_SPDX_GZIP {
+		t.Errorf("expected layer media type to be %s, got: %s", MEDIATYPE_SPDX_GZIP, layer.MediaType)
+	}
+	if layer.Size >= desc.Size {
+		t.Errorf("expected compressed layer size %d to be smaller than %d", layer.Size, desc.Size)
+	}
+	if layer.Annotations[OCI_ANNOTATION_UNCOMPRESSED_DIGEST] != desc.Digest.String() {
+		t.Errorf("expected uncompressed digest annotation to be %s, got: %s", desc.Digest, layer.Annotations[OCI_ANNOTATION_UNCOMPRESSED_DIGEST])
+	}
+
+	fetchedDoc, fetchedDesc, fetchedBytes, err := FetchSBOM(ctx, memDest, "v1", true)
+	if err != nil {
+		t.Fatalf("expected no error from FetchSBOM, got: %v", err)
+	}
+	if !bytes.Equal(fetchedBytes, sbomBytes) {
+		t.Errorf("expected fetched SBOM to match the pushed SBOM")
+	}
+	if fetchedDesc.Digest != desc.Digest {
+		t.Errorf("expected fetched digest to be %s, got: %s", desc.Digest, fetchedDesc.Digest)
+	}
+	if fetchedDesc.Annotations[ocispec.AnnotationTitle] != "SPDXJSONExample-v2.3.spdx.json" {
+		t.Errorf("expected fetched title to be kept, got: %s", fetchedDesc.Annotations[ocispec.AnnotationTitle])
+	}
+	if fetchedDoc.Document.DocumentName != "SPDX-Tools-v2.0" {
+		t.Errorf("expected document name to be 'SPDX-Tools-v2.0', got: %v", fetchedDoc.Document.DocumentName)
+	}
+}
+
+func TestFetchSBOM_UncompressedLayer(t *testing.T) {
+	ctx := context.Background()
+	memDest := memory.New()
+
+	reader := io.NopCloser(strings.NewReader(spdxStr))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	if _, err := PushSBOM(doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", nil, true, nil, memDest); err != nil {
+		t.Fatalf("expected no error from PushSBOM, got: %v", err)
+	}
+
+	_, fetchedDesc, fetchedBytes, err := FetchSBOM(ctx, memDest, "v1", true)
+	if err != nil {
+		t.Fatalf("expected no error from FetchSBOM, got: %v", err)
+	}
+	if !bytes.Equal(fetchedBytes, sbomBytes) {
+		t.Errorf("expected fetched SBOM to match the pushed SBOM")
+	}
+	if fetchedDesc.Digest != desc.Digest {
+		t.Errorf("expected fetched digest to be %s, got: %s", desc.Digest, fetchedDesc.Digest)
+	}
+}
+
+// tamperedStore is an in-memory target that returns other content of the same size for a blob, as a compromised
+// registry would
+type tamperedStore struct {
+	*memory.Store
+	digest  digest.Digest
+	content []byte
+}
+
+func (s *tamperedStore) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if target.Digest == s.digest {
+		return io.NopCloser(bytes.NewReader(s.content)), nil
+	}
+	return s.Store.Fetch(ctx, target)
+}
+
+func TestFetchSBOM_TamperedLayer(t *testing.T) {
+	ctx := context.Background()
+	memDest := memory.New()
+
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	if _, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", memDest, PushOptions{}); err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+
+	// the tampered document is valid SPDX of the same size, only its digest differs
+	tampered := bytes.Replace(sbom.Bytes, []byte(sbom.Document.DocumentName), bytes.ToUpper([]byte(sbom.Document.DocumentName)), 1)
+	if bytes.Equal(tampered, sbom.Bytes) {
+		t.Fatalf("expected the document name to be changed")
+	}
+	store := &tamperedStore{Store: memDest, digest: manifest.Layers[0].Digest, content: tampered}
+	if _, err := FetchSBOMArtifact(ctx, store, "v1", true); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("expected a mismatched digest error from FetchSBOMArtifact, got: %v", err)
+	}
+}
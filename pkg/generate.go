@@ -0,0 +1,358 @@
+package obom
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"debug/buildinfo"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	purl "github.com/package-url/packageurl-go"
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+const (
+	// GENERATE_TOOL is the default tool creator of the generated SPDX documents
+	GENERATE_TOOL = "obom"
+	// GENERATE_NAMESPACE_PREFIX prefixes the name and a random UUID in the namespace of the generated SPDX documents
+	GENERATE_NAMESPACE_PREFIX = "https://spdx.org/spdxdocs/"
+	// GO_MODULE_HASH_REF_TYPE is the type of the OTHER external references of the h1: hashes of the Go modules
+	GO_MODULE_HASH_REF_TYPE = "go-module-hash"
+
+	// goStdlib is the package of the Go standard library linked into Go binaries
+	goStdlib = "stdlib"
+)
+
+// GenerateOptions contains the optional parameters of the generation of SPDX documents
+type GenerateOptions struct {
+	// Name is the name of the document, defaulting to the path and the version of the main module
+	Name string
+	// Namespace is the namespace of the document, defaulting to a unique URI of the name
+	Namespace string
+	// Version is the version of the main module of a go.mod file, which does not record it
+	Version string
+	// Tool is the tool creator of the document, defaulting to obom
+	Tool string
+	// Now returns the creation time of the document, defaulting to time.Now
+	Now func() time.Time
+}
+
+// goModule is a module of a go.mod file or of the build info of a Go binary
+type goModule struct {
+	Path    string
+	Version string
+	// Sum is the h1: hash of the module of go.sum or of the build info
+	Sum      string
+	Indirect bool
+}
+
+// GenerateGoModSBOM generates an SPDX document of the main module of the go.mod file and of its required modules,
+// applying the replace directives. The checksums of the modules are read from the go.sum file, which may be nil.
+func GenerateGoModSBOM(goMod []byte, goSum []byte, opts GenerateOptions) (*v2_3.Document, error) {
+	main, requires, err := parseGoMod(goMod)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing go.mod: %w", err)
+	}
+	main.Version = opts.Version
+
+	sums := parseGoSum(goSum)
+	for i := range requires {
+		requires[i].Sum = sums[requires[i].Path+"@"+requires[i].Version]
+	}
+	return buildGoSBOM(main, requires, "", opts), nil
+}
+
+// GenerateGoBinarySBOM generates an SPDX document of the main module of a Go binary, of the modules it depends on
+// and of the Go standard library it is built with, read from the build info embedded in the binary
+func GenerateGoBinarySBOM(binary io.ReaderAt, opts GenerateOptions) (*v2_3.Document, error) {
+	info, err := buildinfo.Read(binary)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Go build info: %w", err)
+	}
+
+	main := goModule{Path: info.Main.Path, Version: info.Main.Version, Sum: info.Main.Sum}
+	if main.Path == "" {
+		// binaries built from files rather than a module only have their package path
+		main.Path = info.Path
+	}
+	if main.Version == "(devel)" {
+		main.Version = ""
+	}
+
+	deps := make([]goModule, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		module := goModule{Path: dep.Path, Version: dep.Version, Sum: dep.Sum}
+		switch {
+		case dep.Replace != nil && dep.Replace.Version == "":
+			// a replacement by a local directory keeps the module path, without a version
+			module = goModule{Path: dep.Path}
+		case dep.Replace != nil:
+			module = goModule{Path: dep.Replace.Path, Version: dep.Replace.Version, Sum: dep.Replace.Sum}
+		}
+		deps = append(deps, module)
+	}
+	return buildGoSBOM(main, deps, info.GoVersion, opts), nil
+}
+
+// buildGoSBOM builds the SPDX document describing the main module, which depends on the other modules and on the Go
+// standard library when its version is set
+func buildGoSBOM(main goModule, deps []goModule, goVersion string, opts GenerateOptions) *v2_3.Document {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	tool := opts.Tool
+	if tool == "" {
+		tool = GENERATE_TOOL
+	}
+	name := opts.Name
+	if name == "" {
+		name = main.Path
+		if main.Version != "" {
+			name += "@" + main.Version
+		}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = GENERATE_NAMESPACE_PREFIX + strings.ReplaceAll(name, "@", "-") + "-" + newUUID()
+	}
+
+	doc := &v2_3.Document{
+		SPDXVersion:       v2_3.Version,
+		DataLicense:       v2_3.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      name,
+		DocumentNamespace: namespace,
+		CreationInfo: &v2_3.CreationInfo{
+			Creators: []v2common.Creator{{CreatorType: "Tool", Creator: tool}},
+			Created:  now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	ids := make(map[v2common.ElementID]bool)
+	mainPackage := newGoPackage(main, ids)
+	if goVersion != "" {
+		mainPackage.PrimaryPackagePurpose = "APPLICATION"
+	}
+	doc.Packages = append(doc.Packages, mainPackage)
+	doc.Relationships = append(doc.Relationships, &v2_3.Relationship{
+		RefA:         v2common.MakeDocElementID("", "DOCUMENT"),
+		RefB:         v2common.MakeDocElementID("", string(mainPackage.PackageSPDXIdentifier)),
+		Relationship: v2common.TypeRelationshipDescribe,
+	})
+
+	if goVersion != "" {
+		// the Go standard library is versioned with the toolchain, such as go1.24.1
+		deps = append(deps, goModule{Path: goStdlib, Version: goVersion})
+	}
+	for _, dep := range deps {
+		pkg := newGoPackage(dep, ids)
+		pkg.PrimaryPackagePurpose = "LIBRARY"
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, &v2_3.Relationship{
+			RefA:         v2common.MakeDocElementID("", string(mainPackage.PackageSPDXIdentifier)),
+			RefB:         v2common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: v2common.TypeRelationshipDependsOn,
+		})
+	}
+	return doc
+}
+
+// newGoPackage returns the SPDX package of the module with a unique identifier among ids. The h1: hash of go.sum and
+// of the build info is a hash of the file tree of the module rather than a checksum of an artifact, so it is recorded
+// verbatim as an external reference rather than as a checksum of the package.
+func newGoPackage(module goModule, ids map[v2common.ElementID]bool) *v2_3.Package {
+	id := v2common.ElementID("Package-go-" + sanitizeElementID(module.Path))
+	if module.Version != "" {
+		id += v2common.ElementID("-" + sanitizeElementID(module.Version))
+	}
+	unique := id
+	for i := 2; ids[unique]; i++ {
+		unique = v2common.ElementID(fmt.Sprintf("%s-%d", id, i))
+	}
+	ids[unique] = true
+
+	pkg := &v2_3.Package{
+		PackageSPDXIdentifier:     unique,
+		PackageName:               module.Path,
+		PackageVersion:            module.Version,
+		PackageSupplier:           &v2common.Supplier{Supplier: noAssertion},
+		PackageDownloadLocation:   noAssertion,
+		FilesAnalyzed:             false,
+		IsFilesAnalyzedTagPresent: true,
+		PackageLicenseConcluded:   noAssertion,
+		PackageLicenseDeclared:    noAssertion,
+		PackageCopyrightText:      noAssertion,
+	}
+	if module.Indirect {
+		pkg.PackageComment = "indirect dependency"
+	}
+
+	// modules replaced by a local directory have no version and no package URL
+	if module.Version != "" {
+		pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+			Category: v2common.CategoryPackageManager,
+			RefType:  v2common.TypePackageManagerPURL,
+			Locator:  goPURL(module.Path, module.Version),
+		})
+	}
+	if module.Sum != "" {
+		pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &v2_3.PackageExternalReference{
+			Category:           v2common.CategoryOther,
+			RefType:            GO_MODULE_HASH_REF_TYPE,
+			Locator:            module.Sum,
+			ExternalRefComment: "hash of the file tree of the module, as recorded in go.sum",
+		})
+	}
+	return pkg
+}
+
+// goPURL returns the package URL of a Go module, whose namespace is the path up to the last element
+func goPURL(path string, version string) string {
+	if path == goStdlib {
+		version = strings.TrimPrefix(version, "go")
+	}
+	namespace, name := "", path
+	if slash := strings.LastIndex(path, "/"); slash >= 0 {
+		namespace, name = path[:slash], path[slash+1:]
+	}
+	return purl.NewPackageURL(purl.TypeGolang, namespace, name, version, nil, "").ToString()
+}
+
+// sanitizeElementID replaces the characters that are not allowed in SPDX identifiers with dashes
+func sanitizeElementID(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, value)
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	// crypto/rand.Read does not fail on the supported platforms
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// parseGoMod parses the module path and the required modules of a go.mod file, applying its replace directives
+func parseGoMod(data []byte) (goModule, []goModule, error) {
+	var main goModule
+	var requires []goModule
+	// replacements are keyed by path, or by path@version for the replacements of a version
+	replacements := make(map[string]goModule)
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		verb := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "":
+			verb, fields = fields[0], fields[1:]
+		}
+
+		for i, field := range fields {
+			if strings.HasPrefix(field, `"`) || strings.HasPrefix(field, "`") {
+				unquoted, err := strconv.Unquote(field)
+				if err != nil {
+					return goModule{}, nil, fmt.Errorf("line %d: invalid quoted string %s", lineNumber, field)
+				}
+				fields[i] = unquoted
+			}
+		}
+
+		switch verb {
+		case "module":
+			if len(fields) != 1 {
+				return goModule{}, nil, fmt.Errorf("line %d: invalid module directive", lineNumber)
+			}
+			main.Path = fields[0]
+		case "require":
+			if len(fields) != 2 {
+				return goModule{}, nil, fmt.Errorf("line %d: invalid require directive", lineNumber)
+			}
+			requires = append(requires, goModule{
+				Path:     fields[0],
+				Version:  fields[1],
+				Indirect: strings.TrimSpace(comment) == "indirect",
+			})
+		case "replace":
+			// old [version] => new [version]
+			arrow := -1
+			for i, field := range fields {
+				if field == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 1 || arrow > 2 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+				return goModule{}, nil, fmt.Errorf("line %d: invalid replace directive", lineNumber)
+			}
+			key := fields[0]
+			if arrow == 2 {
+				key += "@" + fields[1]
+			}
+			replacement := goModule{Path: fields[arrow+1]}
+			if len(fields)-arrow == 3 {
+				replacement.Version = fields[arrow+2]
+			}
+			replacements[key] = replacement
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return goModule{}, nil, err
+	}
+	if main.Path == "" {
+		return goModule{}, nil, fmt.Errorf("missing module directive")
+	}
+
+	for i, require := range requires {
+		replacement, found := replacements[require.Path+"@"+require.Version]
+		if !found {
+			replacement, found = replacements[require.Path]
+		}
+		switch {
+		case found && replacement.Version == "":
+			// a replacement by a local directory keeps the module path, without a version
+			requires[i].Version = ""
+		case found:
+			requires[i] = goModule{Path: replacement.Path, Version: replacement.Version, Indirect: require.Indirect}
+		}
+	}
+	return main, requires, nil
+}
+
+// parseGoSum returns the h1: hashes of the modules of a go.sum file by path@version, leaving out the hashes of
+// their go.mod files
+func parseGoSum(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+"@"+fields[1]] = fields[2]
+	}
+	return sums
+}
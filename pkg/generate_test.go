@@ -0,0 +1,152 @@
+package obom
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+func findPackage(doc *v2_3.Document, name string) *v2_3.Package {
+	for _, pkg := range doc.Packages {
+		if pkg.PackageName == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// getGoModuleHash returns the h1: hash of the external references of the package
+func getGoModuleHash(pkg *v2_3.Package) string {
+	for _, ref := range pkg.PackageExternalReferences {
+		if ref.Category == v2common.CategoryOther && ref.RefType == GO_MODULE_HASH_REF_TYPE {
+			return ref.Locator
+		}
+	}
+	return ""
+}
+
+func hasDependsOn(doc *v2_3.Document, from *v2_3.Package, to *v2_3.Package) bool {
+	for _, relationship := range doc.Relationships {
+		if relationship.Relationship == v2common.TypeRelationshipDependsOn &&
+			relationship.RefA.ElementRefID == from.PackageSPDXIdentifier && relationship.RefB.ElementRefID == to.PackageSPDXIdentifier {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateGoModSBOM(t *testing.T) {
+	goMod := `module example.com/app
+
+go 1.23.0
+
+require (
+	github.com/spf13/cobra v1.9.1
+	github.com/old/dep v1.0.0 // indirect
+	example.com/local v0.1.0
+)
+
+require "golang.org/x/term" v0.28.0
+
+replace github.com/old/dep => github.com/new/dep v1.2.0
+
+replace example.com/local => ../local
+`
+	goSum := `github.com/spf13/cobra v1.9.1 h1:CXSaggrXdbHK9CF+8ywj8Amf7PBRmPCOJugH954Nnlo=
+github.com/spf13/cobra v1.9.1/go.mod h1:nDyEzZ8ogv936Cinf6g1RU9MRY64Ir93oCnqb9wxYW0=
+`
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	doc, err := GenerateGoModSBOM([]byte(goMod), []byte(goSum), GenerateOptions{Version: "v1.0.0", Now: func() time.Time { return created }})
+	if err != nil {
+		t.Fatalf("expected no error from GenerateGoModSBOM, got: %v", err)
+	}
+	if doc.DocumentName != "example.com/app@v1.0.0" || doc.CreationInfo.Created != "2026-10-01T12:00:00Z" {
+		t.Errorf("expected the document example.com/app@v1.0.0 created at 2026-10-01T12:00:00Z, got: %s at %s", doc.DocumentName, doc.CreationInfo.Created)
+	}
+	if len(doc.Packages) != 5 {
+		t.Fatalf("expected 5 packages, got: %d", len(doc.Packages))
+	}
+
+	app := doc.Packages[0]
+	cobra := findPackage(doc, "github.com/spf13/cobra")
+	if cobra == nil || getPURL(cobra) != "pkg:golang/github.com/spf13/cobra@v1.9.1" {
+		t.Fatalf("expected the package URL of cobra, got: %v", cobra)
+	}
+	if len(cobra.PackageChecksums) != 0 || getGoModuleHash(cobra) != "h1:CXSaggrXdbHK9CF+8ywj8Amf7PBRmPCOJugH954Nnlo=" {
+		t.Errorf("expected the h1 hash of go.sum as an external reference and no checksum, got: %v", cobra.PackageExternalReferences)
+	}
+	if !hasDependsOn(doc, app, cobra) {
+		t.Errorf("expected %s to depend on %s", app.PackageSPDXIdentifier, cobra.PackageSPDXIdentifier)
+	}
+
+	replaced := findPackage(doc, "github.com/new/dep")
+	if replaced == nil || replaced.PackageVersion != "v1.2.0" || replaced.PackageComment != "indirect dependency" {
+		t.Errorf("expected the indirect replacement github.com/new/dep v1.2.0, got: %v", replaced)
+	}
+	local := findPackage(doc, "example.com/local")
+	if local == nil || local.PackageVersion != "" || len(local.PackageExternalReferences) != 0 {
+		t.Errorf("expected the local replacement example.com/local without a version, got: %v", local)
+	}
+	if term := findPackage(doc, "golang.org/x/term"); term == nil || term.PackageVersion != "v0.28.0" {
+		t.Errorf("expected golang.org/x/term v0.28.0, got: %v", term)
+	}
+
+	// the generated document is parsed strictly as push does
+	var buf bytes.Buffer
+	if err := WriteSBOM(&buf, doc); err != nil {
+		t.Fatalf("expected no error from WriteSBOM, got: %v", err)
+	}
+	sbom, err := ReadSBOM(&buf, true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	if sbom.Version != "SPDX-2.3" || len(sbom.Document.Packages) != 5 {
+		t.Errorf("expected an SPDX-2.3 document of 5 packages, got: %s of %d packages", sbom.Version, len(sbom.Document.Packages))
+	}
+
+	if _, err := GenerateGoModSBOM([]byte("go 1.23.0\n"), nil, GenerateOptions{}); err == nil {
+		t.Errorf("expected an error from GenerateGoModSBOM without a module directive")
+	}
+}
+
+func TestGenerateGoBinarySBOM(t *testing.T) {
+	// the test binary is a Go binary with the build info of this module
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("expected no error from os.Executable, got: %v", err)
+	}
+	file, err := os.Open(executable)
+	if err != nil {
+		t.Fatalf("expected no error opening the test binary, got: %v", err)
+	}
+	defer file.Close()
+
+	doc, err := GenerateGoBinarySBOM(file, GenerateOptions{Namespace: "https://example.com/obom.test"})
+	if err != nil {
+		t.Fatalf("expected no error from GenerateGoBinarySBOM, got: %v", err)
+	}
+	if doc.DocumentNamespace != "https://example.com/obom.test" {
+		t.Errorf("expected the namespace https://example.com/obom.test, got: %s", doc.DocumentNamespace)
+	}
+	main := doc.Packages[0]
+	if main.PrimaryPackagePurpose != "APPLICATION" {
+		t.Errorf("expected the main package to be an application, got: %s", main.PrimaryPackagePurpose)
+	}
+	spdx := findPackage(doc, "github.com/spdx/tools-golang")
+	if spdx == nil || spdx.PackageVersion != "v0.5.5" || !strings.HasPrefix(getGoModuleHash(spdx), "h1:") || !hasDependsOn(doc, main, spdx) {
+		t.Errorf("expected the dependency github.com/spdx/tools-golang v0.5.5 with its h1 hash, got: %v", spdx)
+	}
+	stdlib := findPackage(doc, "stdlib")
+	if stdlib == nil || !hasDependsOn(doc, main, stdlib) {
+		t.Fatalf("expected the dependency on the Go standard library, got: %v", stdlib)
+	}
+
+	if _, err := GenerateGoBinarySBOM(bytes.NewReader([]byte("#!/bin/sh\n")), GenerateOptions{}); err == nil {
+		t.Errorf("expected an error from GenerateGoBinarySBOM for a script")
+	}
+}
@@ -0,0 +1,559 @@
+package obom
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	purl "github.com/package-url/packageurl-go"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	MEDIATYPE_DOCKER_MANIFEST      = "application/vnd.docker.distribution.manifest.v2+json"
+	MEDIATYPE_DOCKER_MANIFEST_LIST = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// whiteoutPrefix marks a file deleted by a layer, and whiteoutOpaque a directory whose lower content is hidden
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+	// maxGoBinarySize is the size of the largest executable read into memory for its Go build info
+	maxGoBinarySize = 256 * 1024 * 1024
+	// maxPackageDatabaseSize is the size of the largest package database read for its installed packages
+	maxPackageDatabaseSize = 512 * 1024 * 1024
+)
+
+// VerifyImageOptions contains the optional parameters of VerifyImage
+type VerifyImageOptions struct {
+	// Platform selects the image of a multi-platform image, defaulting to linux and the architecture of the runtime
+	Platform *v1.Platform
+}
+
+// ImageVerificationReport is the outcome of the verification of an SPDX document against the image it describes
+type ImageVerificationReport struct {
+	Reference string `json:"reference"`
+	// Digest is the digest of the image manifest, of the selected platform for a multi-platform image
+	Digest digest.Digest `json:"digest"`
+	Layers []ImageLayer  `json:"layers"`
+	// Files is the verification of the files of the SPDX document against the file system of the image
+	Files *FileVerificationReport `json:"files"`
+	// Packages is the comparison of the packages of the SPDX document with the packages installed in the image
+	Packages *PackageComparison `json:"packages"`
+	// Sources are the package databases and Go binaries the installed packages were read from
+	Sources []string `json:"sources"`
+	// Unsupported are the package databases that cannot be read offline, such as rpm Berkeley DB databases
+	Unsupported []string `json:"unsupported"`
+	// ParseErrors are the package databases that could not be parsed, whose packages are not compared
+	ParseErrors []PackageDatabaseError `json:"parseErrors"`
+}
+
+// PackageDatabaseError is a package database of an image that could not be parsed
+type PackageDatabaseError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// ImageLayer lists the files of a layer of an image
+type ImageLayer struct {
+	Digest    digest.Digest `json:"digest"`
+	MediaType string        `json:"mediaType"`
+	// Files are the regular files added or changed by the layer
+	Files []string `json:"files"`
+	// Deleted are the files and directories of the lower layers deleted by the layer
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// PackageComparison compares the packages of an SPDX document with the packages installed in an image
+type PackageComparison struct {
+	// Installed is the number of installed packages found
+	Installed int `json:"installed"`
+	// Coverage is the percentage of the installed packages listed in the SPDX document with their version
+	Coverage float64 `json:"coverage"`
+	// Matched are the packages of the SPDX document installed with the same version
+	Matched []PackageMatch `json:"matched"`
+	// VersionMismatches are the packages of the SPDX document installed with another version
+	VersionMismatches []PackageMatch `json:"versionMismatches"`
+	// NotInstalled are the packages of the SPDX document of a checked type that are not installed
+	NotInstalled []PackageMatch `json:"notInstalled"`
+	// Unlisted are the installed packages that are not packages of the SPDX document
+	Unlisted []InstalledPackage `json:"unlisted"`
+	// Unchecked is the number of packages of the SPDX document whose type is not checked, such as npm packages
+	Unchecked int `json:"unchecked"`
+}
+
+// PackageMatch is a package of an SPDX document along with the versions installed in an image
+type PackageMatch struct {
+	SPDXID  string `json:"spdxId"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Installed are the installed versions of the package
+	Installed []string `json:"installed,omitempty"`
+}
+
+// OK reports whether the image matches the SPDX document, without file discrepancies or package differences
+func (r *ImageVerificationReport) OK() bool {
+	if !r.Files.OK() {
+		return false
+	}
+	return len(r.Packages.VersionMismatches) == 0 && len(r.Packages.NotInstalled) == 0 && len(r.Packages.Unlisted) == 0
+}
+
+// imageFile is a regular file of the file system of an image
+type imageFile struct {
+	checksums fileChecksums
+	// layer is the index of the layer of the file
+	layer int
+	// packages are the packages installed according to the file, when it is a package database or a Go binary
+	packages []InstalledPackage
+	// isPackageSource reports whether the file is a package database or a Go binary
+	isPackageSource bool
+	unsupported     bool
+	// parseError is the error parsing the package database, when it is malformed
+	parseError string
+}
+
+// VerifyImage verifies the SPDX document against the image tagged or identified by reference in the source, such as
+// a remote repository or an OCI layout. The layers of the image are read to list their files and to assemble the file
+// system of the image, whose files are verified as VerifyFiles does. The packages installed according to the dpkg,
+// apk and rpm sqlite databases and to the build info of the Go binaries are compared with the packages of the SPDX
+// document, by their package URL type and name, or by their name for the packages without a package URL.
+func VerifyImage(ctx context.Context, sbom *v2_3.Document, src oras.ReadOnlyTarget, reference string, opts VerifyImageOptions) (*ImageVerificationReport, error) {
+	desc, manifest, err := fetchImageManifest(ctx, src, reference, opts.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImageVerificationReport{
+		Reference:   reference,
+		Digest:      desc.Digest,
+		Layers:      make([]ImageLayer, 0, len(manifest.Layers)),
+		Sources:     []string{},
+		Unsupported: []string{},
+		ParseErrors: []PackageDatabaseError{},
+	}
+	files := make(map[string]*imageFile)
+	for i, layer := range manifest.Layers {
+		imageLayer, err := readImageLayer(ctx, src, layer, i, files)
+		if err != nil {
+			return nil, fmt.Errorf("error reading layer %s: %w", layer.Digest, err)
+		}
+		report.Layers = append(report.Layers, *imageLayer)
+	}
+
+	checksums := make(map[string]fileChecksums, len(files))
+	var installed []InstalledPackage
+	for name, file := range files {
+		checksums[name] = file.checksums
+		if file.unsupported {
+			report.Unsupported = append(report.Unsupported, name)
+		}
+		if file.parseError != "" {
+			report.ParseErrors = append(report.ParseErrors, PackageDatabaseError{File: name, Error: file.parseError})
+		}
+		if file.isPackageSource {
+			report.Sources = append(report.Sources, name)
+			installed = append(installed, file.packages...)
+		}
+	}
+	sort.Strings(report.Sources)
+	sort.Strings(report.Unsupported)
+	sort.Slice(report.ParseErrors, func(i, j int) bool { return report.ParseErrors[i].File < report.ParseErrors[j].File })
+
+	report.Files = compareFiles(sbom, checksums)
+	report.Files.Root = reference
+	report.Packages = comparePackages(sbom, installed)
+	return report, nil
+}
+
+// fetchImageManifest resolves the reference and fetches the image manifest, selecting the manifest of the platform
+// of a multi-platform image
+func fetchImageManifest(ctx context.Context, src oras.ReadOnlyTarget, reference string, platform *v1.Platform) (v1.Descriptor, *v1.Manifest, error) {
+	desc, err := src.Resolve(ctx, reference)
+	if err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("error resolving %s: %w", reference, err)
+	}
+	manifestBytes, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("error fetching manifest: %w", err)
+	}
+
+	if desc.MediaType == v1.MediaTypeImageIndex || desc.MediaType == MEDIATYPE_DOCKER_MANIFEST_LIST {
+		var index v1.Index
+		if err := json.Unmarshal(manifestBytes, &index); err != nil {
+			return v1.Descriptor{}, nil, fmt.Errorf("error unmarshaling image index: %w", err)
+		}
+		if platform == nil {
+			platform = &v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+		}
+		selected, err := selectPlatform(index.Manifests, platform)
+		if err != nil {
+			return v1.Descriptor{}, nil, err
+		}
+		desc = selected
+		manifestBytes, err = content.FetchAll(ctx, src, desc)
+		if err != nil {
+			return v1.Descriptor{}, nil, fmt.Errorf("error fetching manifest: %w", err)
+		}
+	}
+
+	if desc.MediaType != v1.MediaTypeImageManifest && desc.MediaType != MEDIATYPE_DOCKER_MANIFEST {
+		return v1.Descriptor{}, nil, fmt.Errorf("%s is not an image manifest: %s", reference, desc.MediaType)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return v1.Descriptor{}, nil, fmt.Errorf("error unmarshaling manifest: %w", err)
+	}
+	return desc, &manifest, nil
+}
+
+// selectPlatform returns the manifest of the index matching the platform, the variant being optional
+func selectPlatform(manifests []v1.Descriptor, platform *v1.Platform) (v1.Descriptor, error) {
+	for _, manifest := range manifests {
+		if manifest.Platform == nil || manifest.Platform.OS != platform.OS || manifest.Platform.Architecture != platform.Architecture {
+			continue
+		}
+		if platform.Variant != "" && manifest.Platform.Variant != platform.Variant {
+			continue
+		}
+		return manifest, nil
+	}
+	return v1.Descriptor{}, fmt.Errorf("no image for platform %s", formatPlatform(platform))
+}
+
+// ParsePlatform parses a platform in the format of os/arch[/variant], such as linux/arm64/v8
+func ParsePlatform(platform string) (*v1.Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q, expected os/arch[/variant]", platform)
+	}
+	p := &v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// formatPlatform returns the platform in the format of os/arch[/variant]
+func formatPlatform(platform *v1.Platform) string {
+	formatted := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		formatted += "/" + platform.Variant
+	}
+	return formatted
+}
+
+// readImageLayer reads the tar archive of the layer, applying its files and whiteouts to the files of the lower
+// layers
+func readImageLayer(ctx context.Context, src oras.ReadOnlyTarget, layer v1.Descriptor, index int, files map[string]*imageFile) (*ImageLayer, error) {
+	layerReader, err := src.Fetch(ctx, layer)
+	if err != nil {
+		return nil, err
+	}
+	defer layerReader.Close()
+
+	verifyReader := content.NewVerifyReader(layerReader, layer)
+	reader, err := DecompressReader(io.NopCloser(verifyReader))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	imageLayer := &ImageLayer{Digest: layer.Digest, MediaType: layer.MediaType, Files: []string{}}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		name := normalizeFileName(header.Name)
+		dir, base := path.Split(name)
+		switch {
+		case base == whiteoutOpaque:
+			dir = strings.TrimSuffix(dir, "/")
+			removeLowerFiles(files, dir, index, false)
+			imageLayer.Deleted = append(imageLayer.Deleted, dir+"/")
+		case strings.HasPrefix(base, whiteoutPrefix):
+			deleted := dir + strings.TrimPrefix(base, whiteoutPrefix)
+			removeLowerFiles(files, deleted, index, true)
+			imageLayer.Deleted = append(imageLayer.Deleted, deleted)
+		case header.Typeflag == tar.TypeReg:
+			file, err := readImageFile(tarReader, name, header)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", name, err)
+			}
+			file.layer = index
+			files[name] = file
+			imageLayer.Files = append(imageLayer.Files, name)
+		case header.Typeflag == tar.TypeLink:
+			// a hard link has the content of the file it links to
+			if target, found := files[normalizeFileName(header.Linkname)]; found {
+				linked := *target
+				linked.layer = index
+				linked.isPackageSource = false
+				linked.packages = nil
+				files[name] = &linked
+				imageLayer.Files = append(imageLayer.Files, name)
+			}
+		case header.Typeflag != tar.TypeDir:
+			// a symbolic link or a special file replaces the file of a lower layer
+			delete(files, name)
+		}
+	}
+
+	// the end of the tar archive may be followed by padding, read to the end to verify the digest of the layer
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(io.Discard, verifyReader); err != nil {
+		return nil, err
+	}
+	if err := verifyReader.Verify(); err != nil {
+		return nil, err
+	}
+	return imageLayer, nil
+}
+
+// removeLowerFiles removes the files of the lower layers under the directory, and the file itself when self is set
+func removeLowerFiles(files map[string]*imageFile, name string, layer int, self bool) {
+	for fileName, file := range files {
+		if file.layer >= layer {
+			continue
+		}
+		if (self && fileName == name) || name == "" || strings.HasPrefix(fileName, name+"/") {
+			delete(files, fileName)
+		}
+	}
+}
+
+// readImageFile computes the checksums of a regular file of a layer, and reads the installed packages of the
+// package databases and the Go binaries. A malformed package database is recorded with its parse error rather than
+// failing the verification of the image.
+func readImageFile(reader io.Reader, name string, header *tar.Header) (*imageFile, error) {
+	file := &imageFile{unsupported: isUnsupportedPackageDatabase(name)}
+	parser, isDatabase := getPackageDatabaseParser(name)
+	if isDatabase && header.Size > maxPackageDatabaseSize {
+		isDatabase = false
+		file.parseError = fmt.Sprintf("package database of %d bytes exceeds the maximum size of %d bytes", header.Size, maxPackageDatabaseSize)
+	}
+	isExecutable := header.Mode&0111 != 0 && header.Size > 0 && header.Size <= maxGoBinarySize
+	if isExecutable {
+		// only the executables in a format of the Go build info are read into memory, not scripts and the like
+		buffered := bufio.NewReader(reader)
+		magic, _ := buffered.Peek(4)
+		isExecutable = isBinaryExecutable(magic)
+		reader = buffered
+	}
+
+	if !isDatabase && !isExecutable {
+		checksums, err := computeFileChecksums(reader)
+		if err != nil {
+			return nil, err
+		}
+		file.checksums = checksums
+		return file, nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	sha1Sum := sha1.Sum(data)
+	sha256Sum := sha256.Sum256(data)
+	file.checksums = fileChecksums{SHA1: hex.EncodeToString(sha1Sum[:]), SHA256: hex.EncodeToString(sha256Sum[:])}
+
+	if isDatabase {
+		packages, err := parser(data, name)
+		if err != nil {
+			file.parseError = err.Error()
+			return file, nil
+		}
+		file.packages = packages
+		file.isPackageSource = true
+		return file, nil
+	}
+	if packages := parseGoBinary(data, name); len(packages) > 0 {
+		file.packages = packages
+		file.isPackageSource = true
+	}
+	return file, nil
+}
+
+// binaryMagics are the magic numbers of the ELF, PE and Mach-O executables, the formats of the Go build info
+var binaryMagics = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xfe, 0xed, 0xfa, 0xce},
+	{0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe},
+	{0xcf, 0xfa, 0xed, 0xfe},
+}
+
+// isBinaryExecutable reports whether the file starting with the magic number is an executable that may hold Go build
+// info
+func isBinaryExecutable(magic []byte) bool {
+	for _, binaryMagic := range binaryMagics {
+		if bytes.HasPrefix(magic, binaryMagic) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkedPackageTypes are the package URL types of the packages that are compared with the installed packages
+var checkedPackageTypes = map[string]bool{
+	purl.TypeDebian: true,
+	purl.TypeApk:    true,
+	purl.TypeRPM:    true,
+	purl.TypeGolang: true,
+}
+
+// comparePackages compares the packages of the SPDX document with the installed packages by their package URL type
+// and name
+func comparePackages(sbom *v2_3.Document, installed []InstalledPackage) *PackageComparison {
+	comparison := &PackageComparison{
+		Matched:           []PackageMatch{},
+		VersionMismatches: []PackageMatch{},
+		NotInstalled:      []PackageMatch{},
+		Unlisted:          []InstalledPackage{},
+	}
+
+	// the installed versions by type and name, and by name for the packages of the SPDX document without a PURL
+	versions := make(map[string][]string)
+	namesByName := make(map[string][]string)
+	unique := make(map[InstalledPackage]bool)
+	var packages []InstalledPackage
+	for _, pkg := range installed {
+		key := InstalledPackage{Type: pkg.Type, Name: pkg.Name, Version: pkg.Version}
+		if unique[key] {
+			continue
+		}
+		unique[key] = true
+		packages = append(packages, pkg)
+		versions[pkg.Type+"/"+pkg.Name] = append(versions[pkg.Type+"/"+pkg.Name], pkg.Version)
+		namesByName[pkg.Name] = append(namesByName[pkg.Name], pkg.Type+"/"+pkg.Name)
+	}
+	comparison.Installed = len(packages)
+
+	// covered are the installed packages listed with their version, listed the installed packages listed by name
+	covered := make(map[string]bool)
+	listed := make(map[string]bool)
+	for _, pkg := range sbom.Packages {
+		if pkg == nil {
+			continue
+		}
+		match := PackageMatch{SPDXID: getElementID(pkg.PackageSPDXIdentifier), Name: pkg.PackageName, Version: pkg.PackageVersion}
+		candidates := []string{pkg.PackageVersion}
+
+		var keys []string
+		if packageURL, err := purl.FromString(getPURL(pkg)); err == nil {
+			match.Type = packageURL.Type
+			match.Name = packageURL.Name
+			if packageURL.Type == purl.TypeGolang && packageURL.Namespace != "" {
+				match.Name = packageURL.Namespace + "/" + packageURL.Name
+			}
+			if packageURL.Version != "" {
+				match.Version = packageURL.Version
+				candidates = append(candidates, packageURL.Version)
+				if epoch := packageURL.Qualifiers.Map()["epoch"]; epoch != "" && epoch != "0" {
+					candidates = append(candidates, epoch+":"+packageURL.Version)
+				}
+			}
+			if !checkedPackageTypes[match.Type] {
+				comparison.Unchecked++
+				continue
+			}
+			keys = []string{match.Type + "/" + match.Name}
+		} else {
+			keys = namesByName[pkg.PackageName]
+			if len(keys) == 0 {
+				comparison.Unchecked++
+				continue
+			}
+		}
+
+		found := false
+		for _, key := range keys {
+			listed[key] = true
+			for _, version := range versions[key] {
+				found = true
+				match.Installed = append(match.Installed, version)
+				if version == "" || containsString(candidates, version) {
+					covered[key+"@"+version] = true
+				}
+			}
+		}
+		switch {
+		case !found:
+			comparison.NotInstalled = append(comparison.NotInstalled, match)
+		case matchesAnyVersion(match.Installed, candidates):
+			comparison.Matched = append(comparison.Matched, match)
+		default:
+			comparison.VersionMismatches = append(comparison.VersionMismatches, match)
+		}
+	}
+
+	for _, pkg := range packages {
+		key := pkg.Type + "/" + pkg.Name
+		if !listed[key] {
+			comparison.Unlisted = append(comparison.Unlisted, pkg)
+		}
+	}
+	sort.Slice(comparison.Unlisted, func(i, j int) bool {
+		if comparison.Unlisted[i].Type != comparison.Unlisted[j].Type {
+			return comparison.Unlisted[i].Type < comparison.Unlisted[j].Type
+		}
+		return comparison.Unlisted[i].Name < comparison.Unlisted[j].Name
+	})
+
+	if comparison.Installed > 0 {
+		coveredCount := 0
+		for _, pkg := range packages {
+			if covered[pkg.Type+"/"+pkg.Name+"@"+pkg.Version] {
+				coveredCount++
+			}
+		}
+		comparison.Coverage = float64(coveredCount) * 100 / float64(comparison.Installed)
+	}
+	return comparison
+}
+
+// matchesAnyVersion reports whether one of the installed versions is one of the candidate versions of a package, an
+// unknown installed version, such as the version of a Go main module built from a checkout, matching any version
+func matchesAnyVersion(installed []string, candidates []string) bool {
+	for _, version := range installed {
+		if version == "" || containsString(candidates, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether the value is one of the non-empty values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v != "" && v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package obom
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// tarEntry is an entry of a test layer, a regular file unless it is a hard link
+type tarEntry struct {
+	name     string
+	content  string
+	mode     int64
+	linkname string
+}
+
+func buildLayer(t *testing.T, entries []tarEntry, compress bool) []byte {
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if header.Mode == 0 {
+			header.Mode = 0644
+		}
+		if entry.linkname != "" {
+			header.Typeflag = tar.TypeLink
+			header.Linkname = entry.linkname
+			header.Size = 0
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("error writing tar header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.content)); err != nil && entry.linkname == "" {
+			t.Fatalf("error writing tar content: %v", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("error closing tar writer: %v", err)
+	}
+	if !compress {
+		return buf.Bytes()
+	}
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(buf.Bytes()); err != nil {
+		t.Fatalf("error compressing layer: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("error closing gzip writer: %v", err)
+	}
+	return compressed.Bytes()
+}
+
+func pushJSON(t *testing.T, ctx context.Context, store *memory.Store, mediaType string, value any) v1.Descriptor {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("error marshaling %s: %v", mediaType, err)
+	}
+	desc, err := oras.PushBytes(ctx, store, mediaType, data)
+	if err != nil {
+		t.Fatalf("error pushing %s: %v", mediaType, err)
+	}
+	return desc
+}
+
+func TestVerifyImage(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	status := "Package: libc6\nStatus: install ok installed\nVersion: 2.36-9\n\nPackage: base-files\nStatus: install ok installed\nVersion: 12.4\n\nPackage: tzdata\nStatus: install ok installed\nVersion: 2024a\n"
+	layers := [][]byte{
+		buildLayer(t, []tarEntry{
+			{name: "var/lib/dpkg/status", content: status},
+			{name: "bin/app", content: "app", mode: 0755},
+			{name: "etc/old.conf", content: "old"},
+			{name: "etc/conf.d/a.conf", content: "a"},
+			{name: "var/lib/rpm/rpmdb.sqlite", content: "not a database"},
+		}, false),
+		buildLayer(t, []tarEntry{
+			{name: "etc/.wh.old.conf"},
+			{name: "etc/conf.d/.wh..wh..opq"},
+			{name: "etc/conf.d/b.conf", content: "b"},
+			{name: "usr/bin/app", linkname: "bin/app"},
+		}, true),
+	}
+	mediaTypes := []string{v1.MediaTypeImageLayer, v1.MediaTypeImageLayerGzip}
+
+	config := pushJSON(t, ctx, store, v1.MediaTypeImageConfig, v1.Image{Platform: v1.Platform{OS: "linux", Architecture: "amd64"}})
+	manifest := v1.Manifest{MediaType: v1.MediaTypeImageManifest, Config: config}
+	manifest.SchemaVersion = 2
+	for i, layer := range layers {
+		desc, err := oras.PushBytes(ctx, store, mediaTypes[i], layer)
+		if err != nil {
+			t.Fatalf("error pushing layer: %v", err)
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+	manifestDesc := pushJSON(t, ctx, store, v1.MediaTypeImageManifest, manifest)
+	manifestDesc.Platform = &v1.Platform{OS: "linux", Architecture: "amd64"}
+
+	otherManifest := v1.Manifest{MediaType: v1.MediaTypeImageManifest, Config: config, Layers: []v1.Descriptor{}}
+	otherManifest.SchemaVersion = 2
+	otherDesc := pushJSON(t, ctx, store, v1.MediaTypeImageManifest, otherManifest)
+	otherDesc.Platform = &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+
+	index := v1.Index{MediaType: v1.MediaTypeImageIndex, Manifests: []v1.Descriptor{otherDesc, manifestDesc}}
+	index.SchemaVersion = 2
+	indexDesc := pushJSON(t, ctx, store, v1.MediaTypeImageIndex, index)
+	if err := store.Tag(ctx, indexDesc, "v1"); err != nil {
+		t.Fatalf("error tagging image: %v", err)
+	}
+
+	sbom := &v2_3.Document{
+		Packages: []*v2_3.Package{
+			{PackageSPDXIdentifier: "Package-libc6", PackageName: "libc6", PackageVersion: "2.36-9", PackageExternalReferences: []*v2_3.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:deb/debian/libc6@2.36-9?arch=amd64"},
+			}},
+			{PackageSPDXIdentifier: "Package-base-files", PackageName: "base-files", PackageVersion: "12.0", PackageExternalReferences: []*v2_3.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:deb/debian/base-files@12.0"},
+			}},
+			{PackageSPDXIdentifier: "Package-curl", PackageName: "curl", PackageVersion: "7.88.1", PackageExternalReferences: []*v2_3.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:deb/debian/curl@7.88.1"},
+			}},
+			{PackageSPDXIdentifier: "Package-left-pad", PackageName: "left-pad", PackageVersion: "1.3.0", PackageExternalReferences: []*v2_3.PackageExternalReference{
+				{Category: "PACKAGE-MANAGER", RefType: "purl", Locator: "pkg:npm/left-pad@1.3.0"},
+			}},
+		},
+		Files: []*v2_3.File{
+			{FileSPDXIdentifier: "File-app", FileName: "./bin/app", Checksums: []v2common.Checksum{{Algorithm: v2common.SHA256, Value: sha256Hex("app")}}},
+			{FileSPDXIdentifier: "File-old", FileName: "./etc/old.conf"},
+		},
+	}
+
+	report, err := VerifyImage(ctx, sbom, store, "v1", VerifyImageOptions{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}})
+	if err != nil {
+		t.Fatalf("expected no error from VerifyImage, got: %v", err)
+	}
+	if report.Digest != manifestDesc.Digest {
+		t.Errorf("expected the manifest of linux/amd64 %s, got: %s", manifestDesc.Digest, report.Digest)
+	}
+	if len(report.Layers) != 2 || len(report.Layers[0].Files) != 5 || len(report.Layers[1].Deleted) != 2 {
+		t.Errorf("expected 2 layers with 5 files and 2 deletions, got: %+v", report.Layers)
+	}
+	if report.OK() {
+		t.Errorf("expected the report to have discrepancies")
+	}
+
+	// the whiteouts delete etc/old.conf and etc/conf.d/a.conf, the hard link adds usr/bin/app
+	if report.Files.Matched != 1 {
+		t.Errorf("expected 1 matched file, got: %d", report.Files.Matched)
+	}
+	if len(report.Files.Missing) != 1 || report.Files.Missing[0] != "./etc/old.conf" {
+		t.Errorf("expected the missing etc/old.conf, got: %v", report.Files.Missing)
+	}
+	expectedExtra := []string{"etc/conf.d/b.conf", "usr/bin/app", "var/lib/dpkg/status", "var/lib/rpm/rpmdb.sqlite"}
+	if len(report.Files.Extra) != len(expectedExtra) {
+		t.Fatalf("expected the extra files %v, got: %v", expectedExtra, report.Files.Extra)
+	}
+	for i, name := range expectedExtra {
+		if report.Files.Extra[i] != name {
+			t.Errorf("expected the extra files %v, got: %v", expectedExtra, report.Files.Extra)
+		}
+	}
+
+	// the malformed rpm database is reported without failing the verification
+	if len(report.ParseErrors) != 1 || report.ParseErrors[0].File != "var/lib/rpm/rpmdb.sqlite" {
+		t.Errorf("expected the parse error of var/lib/rpm/rpmdb.sqlite, got: %v", report.ParseErrors)
+	}
+
+	packages := report.Packages
+	if packages.Installed != 3 || len(report.Sources) != 1 || report.Sources[0] != "var/lib/dpkg/status" {
+		t.Errorf("expected 3 packages installed from var/lib/dpkg/status, got: %d from %v", packages.Installed, report.Sources)
+	}
+	if len(packages.Matched) != 1 || packages.Matched[0].Name != "libc6" {
+		t.Errorf("expected libc6 to match, got: %v", packages.Matched)
+	}
+	if len(packages.VersionMismatches) != 1 || packages.VersionMismatches[0].Installed[0] != "12.4" {
+		t.Errorf("expected the version mismatch of base-files, got: %v", packages.VersionMismatches)
+	}
+	if len(packages.NotInstalled) != 1 || packages.NotInstalled[0].Name != "curl" {
+		t.Errorf("expected curl to not be installed, got: %v", packages.NotInstalled)
+	}
+	if len(packages.Unlisted) != 1 || packages.Unlisted[0].Name != "tzdata" {
+		t.Errorf("expected tzdata to be unlisted, got: %v", packages.Unlisted)
+	}
+	if packages.Unchecked != 1 {
+		t.Errorf("expected 1 unchecked npm package, got: %d", packages.Unchecked)
+	}
+	if packages.Coverage < 33 || packages.Coverage > 34 {
+		t.Errorf("expected a coverage of a third of the installed packages, got: %f", packages.Coverage)
+	}
+
+	if _, err := VerifyImage(ctx, sbom, store, "v1", VerifyImageOptions{Platform: &v1.Platform{OS: "windows", Architecture: "amd64"}}); err == nil {
+		t.Errorf("expected an error from VerifyImage for a missing platform")
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	platform, err := ParsePlatform("linux/arm64/v8")
+	if err != nil {
+		t.Fatalf("expected no error from ParsePlatform, got: %v", err)
+	}
+	if platform.OS != "linux" || platform.Architecture != "arm64" || platform.Variant != "v8" {
+		t.Errorf("expected linux/arm64/v8, got: %+v", platform)
+	}
+	for _, invalid := range []string{"linux", "linux/", "a/b/c/d"} {
+		if _, err := ParsePlatform(invalid); err == nil {
+			t.Errorf("expected an error from ParsePlatform for %q", invalid)
+		}
+	}
+}
+
+func TestReadImageFile_Executables(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		content string
+		binary  bool
+	}{
+		{"usr/bin/script", "#!/bin/sh\necho hello\n", false},
+		{"usr/bin/elf", "\x7fELF not really an executable", true},
+		{"usr/bin/exe", "MZ not really an executable", true},
+		{"usr/bin/x", "x", false},
+	} {
+		if isBinaryExecutable([]byte(test.content)) != test.binary {
+			t.Errorf("expected isBinaryExecutable of %s to be %v", test.name, test.binary)
+		}
+
+		header := &tar.Header{Name: test.name, Mode: 0755, Size: int64(len(test.content))}
+		file, err := readImageFile(bytes.NewReader([]byte(test.content)), test.name, header)
+		if err != nil {
+			t.Fatalf("expected no error from readImageFile, got: %v", err)
+		}
+		checksums, err := computeFileChecksums(bytes.NewReader([]byte(test.content)))
+		if err != nil {
+			t.Fatalf("expected no error from computeFileChecksums, got: %v", err)
+		}
+		if file.checksums != checksums {
+			t.Errorf("expected the checksums of %s to be %v, got: %v", test.name, checksums, file.checksums)
+		}
+		if len(file.packages) != 0 {
+			t.Errorf("expected no packages for %s, got: %v", test.name, file.packages)
+		}
+	}
+}
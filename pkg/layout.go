@@ -0,0 +1,139 @@
+package obom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	MANIFEST_VERSION_1_0       = "1.0"
+	MANIFEST_VERSION_1_1       = "1.1"
+	MEDIATYPE_HARBOR_SBOM      = "application/vnd.goharbor.harbor.sbom.v1"
+	MEDIATYPE_OCI_IMAGE_CONFIG = v1.MediaTypeImageConfig
+	MEDIATYPE_TEXT_SPDX        = "text/spdx"
+)
+
+// emptyImageConfig is the default content of an image config, with the architecture, os and rootfs required by the
+// image config schema. The SBOM layers are not file system layers, so the rootfs has no diff_ids.
+var emptyImageConfig = []byte(`{"architecture":"unknown","os":"unknown","rootfs":{"type":"layers","diff_ids":[]}}`)
+
+// ManifestLayout describes how the SBOM manifest is packed. Empty fields fall back to the default layout.
+type ManifestLayout struct {
+	// ManifestVersion is the OCI image manifest version, either "1.0" or "1.1"
+	ManifestVersion string
+	// ArtifactType is the artifactType of the manifest. Image manifests v1.0 have no artifactType,
+	// so it is used as the config media type instead when ConfigMediaType is empty.
+	ArtifactType string
+	// ConfigMediaType is the media type of the config blob. When empty, the empty config is used.
+	ConfigMediaType string
+	// ConfigContent is the content of the config blob, when empty a minimal image config for the image config media
+	// type and "{}" otherwise. Only used with ConfigMediaType.
+	ConfigContent []byte
+	// LayerMediaType is the media type of the SBOM layer
+	LayerMediaType string
+}
+
+// DefaultManifestLayout is the image manifest v1.1 layout with the empty config and the SPDX artifactType
+var DefaultManifestLayout = ManifestLayout{
+	ManifestVersion: MANIFEST_VERSION_1_1,
+	ArtifactType:    MEDIATYPE_SPDX,
+	LayerMediaType:  MEDIATYPE_SPDX,
+}
+
+// ManifestLayoutPresets are the manifest layouts expected by known SBOM consumers
+var ManifestLayoutPresets = map[string]ManifestLayout{
+	"default": DefaultManifestLayout,
+	// Trivy discovers the SBOMs of an image among its referrers by the SPDX artifactType, which is the default layout
+	"trivy": {
+		ManifestVersion: MANIFEST_VERSION_1_1,
+		ArtifactType:    MEDIATYPE_SPDX,
+		LayerMediaType:  MEDIATYPE_SPDX,
+	},
+	// Harbor lists SBOM accessories by its own artifactType
+	"harbor": {
+		ManifestVersion: MANIFEST_VERSION_1_1,
+		ArtifactType:    MEDIATYPE_HARBOR_SBOM,
+		LayerMediaType:  MEDIATYPE_SPDX,
+	},
+	// Older registries only accept image manifests v1.0 with an image config
+	"oci-1.0": {
+		ManifestVersion: MANIFEST_VERSION_1_0,
+		ConfigMediaType: MEDIATYPE_OCI_IMAGE_CONFIG,
+		LayerMediaType:  MEDIATYPE_SPDX,
+	},
+	// Consumers looking up SPDX documents by the text/spdx media type
+	"text-spdx": {
+		ManifestVersion: MANIFEST_VERSION_1_1,
+		ArtifactType:    MEDIATYPE_TEXT_SPDX,
+		LayerMediaType:  MEDIATYPE_TEXT_SPDX,
+	},
+}
+
+// GetManifestLayoutPreset returns the manifest layout preset with the given name
+func GetManifestLayoutPreset(name string) (ManifestLayout, error) {
+	layout, ok := ManifestLayoutPresets[name]
+	if !ok {
+		return ManifestLayout{}, fmt.Errorf("unknown manifest layout preset %q, expected one of %v", name, GetManifestLayoutPresetNames())
+	}
+	return layout, nil
+}
+
+// GetManifestLayoutPresetNames returns the sorted names of the manifest layout presets
+func GetManifestLayoutPresetNames() []string {
+	names := make([]string, 0, len(ManifestLayoutPresets))
+	for name := range ManifestLayoutPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packManifestVersion returns the oras pack version for the manifest version of the layout
+func (l ManifestLayout) packManifestVersion() (oras.PackManifestVersion, error) {
+	switch l.ManifestVersion {
+	case "", MANIFEST_VERSION_1_1:
+		return oras.PackManifestVersion1_1, nil
+	case MANIFEST_VERSION_1_0:
+		return oras.PackManifestVersion1_0, nil
+	default:
+		return 0, fmt.Errorf("unsupported manifest version %q, expected %s or %s", l.ManifestVersion, MANIFEST_VERSION_1_0, MANIFEST_VERSION_1_1)
+	}
+}
+
+// artifactType returns the artifactType of the layout, defaulting to the SPDX media type
+// unless a v1.0 manifest carries its type in the config media type
+func (l ManifestLayout) artifactType() string {
+	if l.ArtifactType != "" {
+		return l.ArtifactType
+	}
+	if l.ManifestVersion == MANIFEST_VERSION_1_0 && l.ConfigMediaType != "" {
+		return ""
+	}
+	return MEDIATYPE_SPDX
+}
+
+// pushConfig pushes the config blob of the layout and returns its descriptor, or nil if the layout uses the default config
+func (l ManifestLayout) pushConfig(ctx context.Context, pusher content.Pusher) (*v1.Descriptor, error) {
+	if l.ConfigMediaType == "" {
+		return nil, nil
+	}
+
+	configContent := l.ConfigContent
+	if len(configContent) == 0 {
+		configContent = []byte("{}")
+		if l.ConfigMediaType == MEDIATYPE_OCI_IMAGE_CONFIG {
+			configContent = emptyImageConfig
+		}
+	}
+
+	configDesc := content.NewDescriptorFromBytes(l.ConfigMediaType, configContent)
+	if err := pushIfMissing(ctx, pusher, configDesc, configContent); err != nil {
+		return nil, fmt.Errorf("error pushing config into memory store: %w", err)
+	}
+	return &configDesc, nil
+}
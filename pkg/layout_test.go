@@ -0,0 +1,155 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func pushWithLayout(t *testing.T, layout ManifestLayout) (*PushResult, ocispec.Manifest, *memory.Store) {
+	t.Helper()
+	ctx := context.Background()
+	memDest := memory.New()
+
+	reader := io.NopCloser(strings.NewReader(spdxStr))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	result, err := PushSBOMWithOptions(ctx, doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{Layout: layout})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	fetchedRc, err := memDest.Fetch(ctx, result.Descriptor)
+	if err != nil {
+		t.Fatalf("error fetching sbom manifest from memory store: %v", err)
+	}
+	defer fetchedRc.Close()
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(fetchedRc).Decode(&manifest); err != nil {
+		t.Fatalf("error decoding fetched manifest: %v", err)
+	}
+	return result, manifest, memDest
+}
+
+func TestPushSBOMWithOptions_DefaultLayout(t *testing.T) {
+	_, manifest, _ := pushWithLayout(t, ManifestLayout{})
+
+	if manifest.ArtifactType != MEDIATYPE_SPDX {
+		t.Errorf("expected artifactType to be %s, got: %s", MEDIATYPE_SPDX, manifest.ArtifactType)
+	}
+	if manifest.Config.MediaType != ocispec.MediaTypeEmptyJSON {
+		t.Errorf("expected config media type to be %s, got: %s", ocispec.MediaTypeEmptyJSON, manifest.Config.MediaType)
+	}
+	if manifest.Layers[0].MediaType != MEDIATYPE_SPDX {
+		t.Errorf("expected layer media type to be %s, got: %s", MEDIATYPE_SPDX, manifest.Layers[0].MediaType)
+	}
+}
+
+func TestPushSBOMWithOptions_ManifestV1_0WithImageConfig(t *testing.T) {
+	layout, err := GetManifestLayoutPreset("oci-1.0")
+	if err != nil {
+		t.Fatalf("expected no error from GetManifestLayoutPreset, got: %v", err)
+	}
+
+	_, manifest, memDest := pushWithLayout(t, layout)
+
+	if manifest.ArtifactType != "" {
+		t.Errorf("expected no artifactType for a v1.0 manifest, got: %s", manifest.ArtifactType)
+	}
+	if manifest.Config.MediaType != ocispec.MediaTypeImageConfig {
+		t.Errorf("expected config media type to be %s, got: %s", ocispec.MediaTypeImageConfig, manifest.Config.MediaType)
+	}
+	if manifest.Layers[0].MediaType != MEDIATYPE_SPDX {
+		t.Errorf("expected layer media type to be %s, got: %s", MEDIATYPE_SPDX, manifest.Layers[0].MediaType)
+	}
+
+	// the image config has the architecture, os and rootfs required by the image config schema
+	configBytes, err := content.FetchAll(context.Background(), memDest, manifest.Config)
+	if err != nil {
+		t.Fatalf("error fetching config: %v", err)
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		t.Fatalf("error decoding config: %v", err)
+	}
+	if config.Architecture == "" || config.OS == "" || config.RootFS.Type != "layers" {
+		t.Errorf("expected an image config with an architecture, os and rootfs, got: %s", configBytes)
+	}
+}
+
+func TestPushSBOMWithOptions_CustomLayout(t *testing.T) {
+	layout := ManifestLayout{
+		ArtifactType:    "text/spdx",
+		ConfigMediaType: "application/vnd.example.config.v1+json",
+		ConfigContent:   []byte(`{"example": true}`),
+		LayerMediaType:  "text/spdx",
+	}
+
+	result, manifest, _ := pushWithLayout(t, layout)
+
+	if result.Descriptor.ArtifactType != "text/spdx" {
+		t.Errorf("expected descriptor artifactType to be 'text/spdx', got: %s", result.Descriptor.ArtifactType)
+	}
+	if manifest.Config.MediaType != "application/vnd.example.config.v1+json" {
+		t.Errorf("expected config media type to be 'application/vnd.example.config.v1+json', got: %s", manifest.Config.MediaType)
+	}
+	if manifest.Config.Size != int64(len(`{"example": true}`)) {
+		t.Errorf("expected config size to match the config content, got: %d", manifest.Config.Size)
+	}
+	if manifest.Layers[0].MediaType != "text/spdx" {
+		t.Errorf("expected layer media type to be 'text/spdx', got: %s", manifest.Layers[0].MediaType)
+	}
+}
+
+func TestGetManifestLayoutPreset(t *testing.T) {
+	layout, err := GetManifestLayoutPreset("harbor")
+	if err != nil {
+		t.Fatalf("expected no error from GetManifestLayoutPreset, got: %v", err)
+	}
+	if layout.ArtifactType != MEDIATYPE_HARBOR_SBOM {
+		t.Errorf("expected harbor artifactType to be %s, got: %s", MEDIATYPE_HARBOR_SBOM, layout.ArtifactType)
+	}
+
+	layout, err = GetManifestLayoutPreset("text-spdx")
+	if err != nil {
+		t.Fatalf("expected no error from GetManifestLayoutPreset, got: %v", err)
+	}
+	if layout.ArtifactType != MEDIATYPE_TEXT_SPDX || layout.LayerMediaType != MEDIATYPE_TEXT_SPDX {
+		t.Errorf("expected the text/spdx artifactType and layer media type, got: %+v", layout)
+	}
+
+	layout, err = GetManifestLayoutPreset("trivy")
+	if err != nil {
+		t.Fatalf("expected no error from GetManifestLayoutPreset, got: %v", err)
+	}
+	if layout.ArtifactType != MEDIATYPE_SPDX || layout.ManifestVersion != MANIFEST_VERSION_1_1 {
+		t.Errorf("expected the SPDX artifactType on an image manifest v1.1, got: %+v", layout)
+	}
+
+	if _, err := GetManifestLayoutPreset("unknown"); err == nil {
+		t.Errorf("expected error for unknown preset, got no error")
+	}
+}
+
+func TestPushSBOMWithOptions_UnsupportedManifestVersion(t *testing.T) {
+	reader := io.NopCloser(strings.NewReader(spdxStr))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	_, err = PushSBOMWithOptions(context.Background(), doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memory.New(), PushOptions{Layout: ManifestLayout{ManifestVersion: "2.0"}})
+	if err == nil {
+		t.Fatalf("expected error for unsupported manifest version, got no error")
+	}
+}
@@ -0,0 +1,158 @@
+package obom
+
+import (
+	"strings"
+)
+
+// spdxLicenseIDs are common SPDX license and exception identifiers, by their lowercase identifier to fix their case
+var spdxLicenseIDs = map[string]string{}
+
+func init() {
+	for _, id := range []string{
+		"0BSD", "AFL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later", "Apache-1.1", "Apache-2.0", "Artistic-2.0",
+		"BSD-2-Clause", "BSD-3-Clause", "BSD-4-Clause", "BSL-1.0", "CC-BY-4.0", "CC-BY-SA-4.0", "CC0-1.0",
+		"CDDL-1.0", "CDDL-1.1", "EPL-1.0", "EPL-2.0", "EUPL-1.2", "GPL-2.0-only", "GPL-2.0-or-later", "GPL-3.0-only",
+		"GPL-3.0-or-later", "ISC", "LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1-only", "LGPL-2.1-or-later",
+		"LGPL-3.0-only", "LGPL-3.0-or-later", "MIT", "MIT-0", "MPL-1.0", "MPL-1.1", "MPL-2.0", "OpenSSL",
+		"PostgreSQL", "PSF-2.0", "Python-2.0", "Ruby", "Unicode-DFS-2016", "Unlicense", "W3C", "WTFPL", "X11", "Zlib",
+		"Classpath-exception-2.0", "GCC-exception-3.1", "LLVM-exception",
+	} {
+		spdxLicenseIDs[strings.ToLower(id)] = id
+	}
+}
+
+// licenseAliases are the SPDX license identifiers of common license names and deprecated identifiers, by their
+// lowercase name without commas
+var licenseAliases = map[string]string{
+	"apache 2":                           "Apache-2.0",
+	"apache 2.0":                         "Apache-2.0",
+	"apache-2":                           "Apache-2.0",
+	"apache2":                            "Apache-2.0",
+	"apache license 2.0":                 "Apache-2.0",
+	"apache license version 2.0":         "Apache-2.0",
+	"apache software license 2.0":        "Apache-2.0",
+	"asl 2.0":                            "Apache-2.0",
+	"mit license":                        "MIT",
+	"the mit license":                    "MIT",
+	"expat":                              "MIT",
+	"bsd 3-clause":                       "BSD-3-Clause",
+	"bsd-3":                              "BSD-3-Clause",
+	"3-clause bsd":                       "BSD-3-Clause",
+	"new bsd":                            "BSD-3-Clause",
+	"new bsd license":                    "BSD-3-Clause",
+	"modified bsd license":               "BSD-3-Clause",
+	"bsd 2-clause":                       "BSD-2-Clause",
+	"bsd-2":                              "BSD-2-Clause",
+	"2-clause bsd":                       "BSD-2-Clause",
+	"simplified bsd":                     "BSD-2-Clause",
+	"simplified bsd license":             "BSD-2-Clause",
+	"freebsd":                            "BSD-2-Clause",
+	"gpl-2.0":                            "GPL-2.0-only",
+	"gpl-2.0+":                           "GPL-2.0-or-later",
+	"gplv2":                              "GPL-2.0-only",
+	"gplv2+":                             "GPL-2.0-or-later",
+	"gpl v2":                             "GPL-2.0-only",
+	"gpl 2.0":                            "GPL-2.0-only",
+	"gpl-3.0":                            "GPL-3.0-only",
+	"gpl-3.0+":                           "GPL-3.0-or-later",
+	"gplv3":                              "GPL-3.0-only",
+	"gplv3+":                             "GPL-3.0-or-later",
+	"gpl v3":                             "GPL-3.0-only",
+	"gpl 3.0":                            "GPL-3.0-only",
+	"lgpl-2.0":                           "LGPL-2.0-only",
+	"lgpl-2.0+":                          "LGPL-2.0-or-later",
+	"lgpl-2.1":                           "LGPL-2.1-only",
+	"lgpl-2.1+":                          "LGPL-2.1-or-later",
+	"lgplv2.1":                           "LGPL-2.1-only",
+	"lgpl 2.1":                           "LGPL-2.1-only",
+	"lgpl-3.0":                           "LGPL-3.0-only",
+	"lgpl-3.0+":                          "LGPL-3.0-or-later",
+	"lgplv3":                             "LGPL-3.0-only",
+	"lgpl 3.0":                           "LGPL-3.0-only",
+	"agpl-3.0":                           "AGPL-3.0-only",
+	"agplv3":                             "AGPL-3.0-only",
+	"mpl 2.0":                            "MPL-2.0",
+	"mozilla public license 2.0":         "MPL-2.0",
+	"eclipse public license 2.0":         "EPL-2.0",
+	"epl 2.0":                            "EPL-2.0",
+	"isc license":                        "ISC",
+	"cc0":                                "CC0-1.0",
+	"cc0 1.0":                            "CC0-1.0",
+	"the unlicense":                      "Unlicense",
+	"boost software license 1.0":         "BSL-1.0",
+	"zlib license":                       "Zlib",
+	"python software foundation license": "PSF-2.0",
+}
+
+// NormalizeLicense normalizes a license string to an SPDX license expression: common license names and deprecated
+// identifiers are replaced by their SPDX license identifier, the case of the identifiers and operators is fixed and
+// slash separated licenses are joined with OR. It returns the normalized expression and true when it differs from
+// the license, or false when the license is not set or cannot be normalized.
+func NormalizeLicense(license string) (string, bool) {
+	if !isLicenseSet(license) {
+		return "", false
+	}
+
+	normalized, ok := normalizeLicenseName(license)
+	if !ok {
+		normalized, ok = normalizeLicenseExpression(license)
+	}
+	if !ok || normalized == license {
+		return "", false
+	}
+	return normalized, true
+}
+
+// normalizeLicenseName returns the SPDX license identifier of a single license name or identifier
+func normalizeLicenseName(name string) (string, bool) {
+	key := strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(name), ",", "")), " ")
+	if id, ok := spdxLicenseIDs[key]; ok {
+		return id, true
+	}
+	if id, ok := licenseAliases[key]; ok {
+		return id, true
+	}
+	return "", false
+}
+
+// normalizeLicenseExpression normalizes each license of an SPDX license expression, or of a slash separated list of
+// licenses, keeping LicenseRef- identifiers, and fails when one of the licenses is unknown
+func normalizeLicenseExpression(expression string) (string, bool) {
+	if !strings.ContainsAny(expression, " ()") {
+		parts := strings.Split(expression, "/")
+		if len(parts) < 2 {
+			return "", false
+		}
+		ids := make([]string, 0, len(parts))
+		for _, part := range parts {
+			id, ok := normalizeLicenseName(part)
+			if !ok {
+				return "", false
+			}
+			ids = append(ids, id)
+		}
+		return strings.Join(ids, " OR "), true
+	}
+
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression))
+	var b strings.Builder
+	for i, token := range tokens {
+		switch upper := strings.ToUpper(token); {
+		case upper == "AND" || upper == "OR" || upper == "WITH":
+			token = upper
+		case token == "(" || token == ")":
+		case strings.HasPrefix(token, "LicenseRef-") || strings.HasPrefix(token, "DocumentRef-"):
+		default:
+			id, ok := normalizeLicenseName(token)
+			if !ok {
+				return "", false
+			}
+			token = id
+		}
+		if i > 0 && token != ")" && tokens[i-1] != "(" {
+			b.WriteString(" ")
+		}
+		b.WriteString(token)
+	}
+	return b.String(), true
+}
@@ -3,14 +3,20 @@ package obom
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/spdx/tools-golang/spdx/v2/v2_3"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
 
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote/auth"
@@ -22,114 +28,383 @@ const (
 
 type CredentialsResolver = func(context.Context, string) (auth.Credential, error)
 
+// PushOptions contains the optional parameters for PushSBOMWithOptions
+type PushOptions struct {
+	// Annotations are the manifest annotations of the SBOM artifact
+	Annotations map[string]string
+	// PushSummary adds the summary blob as a layer of the SBOM artifact
+	PushSummary bool
+	// AttachArtifacts maps an artifactType to the paths of the artifacts to attach to the SBOM, one referrer per path
+	AttachArtifacts map[string][]string
+	// Attachments are the artifacts to attach to the SBOM with their layers and annotations, after AttachArtifacts
+	Attachments []Attachment
+	// Reproducible derives the manifest creation time from the SPDX document instead of the current time,
+	// so that pushing the same SBOM again yields the same manifest digest
+	Reproducible bool
+	// Layout controls the manifest version, artifactType, config and layer media type of the SBOM manifest
+	Layout ManifestLayout
+	// Compression compresses the SBOM layer with gzip or zstd and suffixes its media type with +gzip or +zstd
+	Compression string
+	// ChunkSize splits the packages and files of the SBOM into chunk layers of at most about this many bytes,
+	// next to a header layer with the rest of the document. The SBOM is pushed as a single layer when zero.
+	ChunkSize int64
+	// Progress is called with the progress events of the copy of the SBOM and its referrers to the destination
+	Progress ProgressFunc
+	// Tags are additional tags of the SBOM manifest, next to the tags of the reference
+	Tags []string
+	// NoTag pushes the SBOM manifest by digest only, ignoring the tags of the reference and Tags
+	NoTag bool
+}
+
+// PushResult is the outcome of PushSBOMWithOptions
+type PushResult struct {
+	// Descriptor is the descriptor of the SBOM manifest
+	Descriptor v1.Descriptor
+	// Unchanged is set when the SBOM manifest and its referrers already existed in the destination and nothing was copied
+	Unchanged bool
+	// Tags are the tags of the SBOM manifest in the destination, empty when pushed by digest only
+	Tags []string
+	// Manifest is the content of the SBOM manifest
+	Manifest []byte
+	// Referrers are the descriptors of the manifests of the artifacts attached to the SBOM
+	Referrers []v1.Descriptor
+}
+
 // PushSBOM pushes the SPDX SBOM bytes to the registry as an OCI artifact.
 // It takes in a pointer to an SPDX document, a pointer to a descriptor, a byte slice of the SBOM, a reference string, a map of SPDX annotations, and a credentials resolver function.
 // It returns an error if there was an issue pushing the SBOM to the registry.
+//
+// Deprecated: use Client.Push, or PushSBOMWithOptions, which take a context and return a PushResult.
 func PushSBOM(sbomDoc *v2_3.Document, sbomDescriptor *v1.Descriptor, sbomBytes []byte, reference string, spdx_annotations map[string]string, pushSummary bool, attachArtifacts map[string][]string, dest oras.Target) (*v1.Descriptor, error) {
+	result, err := PushSBOMWithOptions(context.Background(), sbomDoc, sbomDescriptor, sbomBytes, reference, dest, PushOptions{
+		Annotations:     spdx_annotations,
+		PushSummary:     pushSummary,
+		AttachArtifacts: attachArtifacts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result.Descriptor, nil
+}
+
+// PushSBOMWithOptions pushes the SPDX SBOM bytes to the destination as an OCI artifact tagged with the tags of the
+// reference, see ParsePushReference, and of the options, or latest when there are none. A digest reference pushes the
+// SBOM by digest only, and the error wraps ErrDigestMismatch when the packed manifest does not match the digest.
+// If the packed SBOM manifest is already tagged in the destination and all of its referrers exist, nothing is copied and
+// the result is marked as unchanged. Blobs that already exist in the destination are never uploaded again.
+func PushSBOMWithOptions(ctx context.Context, sbomDoc *v2_3.Document, sbomDescriptor *v1.Descriptor, sbomBytes []byte, reference string, dest oras.Target, opts PushOptions) (*PushResult, error) {
 	mem := memory.New()
-	ctx := context.Background()
 
-	// Create a Reader for the bytes
-	sbomReader := bytes.NewReader(sbomBytes)
+	layers := []v1.Descriptor{sbomLayerDescriptor(sbomDescriptor, opts)}
+	layersBytes := [][]byte{sbomBytes}
+	if opts.ChunkSize > 0 {
+		header, chunks, err := SplitSBOM(sbomBytes, opts.ChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("error splitting SBOM into chunks: %w", err)
+		}
+		headerLayer, chunkLayers := chunkLayerDescriptors(sbomDescriptor, header, chunks)
+		layers = append([]v1.Descriptor{headerLayer}, chunkLayers...)
+		layersBytes = [][]byte{header}
+		for _, chunk := range chunks {
+			layersBytes = append(layersBytes, chunk.Bytes)
+		}
+	}
+
+	for i := range layers {
+		if opts.Compression != "" {
+			var err error
+			layers[i], layersBytes[i], err = compressLayer(layers[i], layersBytes[i], opts.Compression)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Add descriptor to a memory store, chunks with the same content are pushed once
+		if err := pushIfMissing(ctx, mem, layers[i], layersBytes[i]); err != nil {
+			return nil, fmt.Errorf("error pushing SBOM into memory store: %w", err)
+		}
+	}
 
-	// Add descriptor to a memory store
-	err := mem.Push(ctx, *sbomDescriptor, sbomReader)
+	return pushSBOMLayers(ctx, mem, mem, sbomDoc, layers, reference, dest, opts)
+}
+
+// pushSBOMLayers packs the SBOM manifest with the SBOM layers into the memory store, attaches the artifacts to it
+// and copies it with its referrers from src to the destination. The src target serves the content of the memory store
+// and the SBOM layers, which are not required to be in the memory store.
+func pushSBOMLayers(ctx context.Context, mem *memory.Store, src oras.ReadOnlyGraphTarget, sbomDoc *v2_3.Document, sbomLayers []v1.Descriptor, reference string, dest oras.Target, opts PushOptions) (*PushResult, error) {
+	packVersion, err := opts.Layout.packManifestVersion()
 	if err != nil {
-		return nil, fmt.Errorf("error pushing SBOM into memory store: %w", err)
+		return nil, err
 	}
 
-	layers := []v1.Descriptor{*sbomDescriptor}
+	layers := append([]v1.Descriptor{}, sbomLayers...)
 
 	// Add annotations to the manifest
 	annotations := make(map[string]string)
-	for k, v := range spdx_annotations {
+	for k, v := range opts.Annotations {
 		annotations[k] = v
 	}
 
-	// add the summary blob as a layer if pushSummary is set
-	if pushSummary {
-		sbomSummary, err := GetSBOMSummary(sbomDoc)
+	// Pin the creation time of the manifests to the SBOM creation time in reproducible mode
+	var created string
+	if opts.Reproducible {
+		created, err = getReproducibleCreated(sbomDoc)
 		if err != nil {
-			return nil, fmt.Errorf("error getting SBOM summary: %w", err)
+			return nil, err
 		}
-		// Marshal the summary into a string
-		summaryBytes, err := json.Marshal(sbomSummary)
+		annotations[v1.AnnotationCreated] = created
+	}
+
+	// add the summary blob as a layer if pushSummary is set
+	if opts.PushSummary {
+		summaryBytes, err := GetSBOMSummaryBytes(sbomDoc)
 		if err != nil {
-			return nil, fmt.Errorf("error marshaling summary into bytes: %w", err)
+			return nil, err
 		}
-		summaryDescriptor, err := oras.PushBytes(ctx, mem, "application/json", summaryBytes)
+		summaryDescriptor, err := oras.PushBytes(ctx, mem, MEDIATYPE_SBOM_SUMMARY, summaryBytes)
 		if err != nil {
 			return nil, fmt.Errorf("error pushing summary into memory store: %w", err)
 		}
 		layers = append(layers, summaryDescriptor)
 	}
 
+	configDescriptor, err := opts.Layout.pushConfig(ctx, mem)
+	if err != nil {
+		return nil, err
+	}
+
 	// Pack the files and tag the packed manifest
-	artifactType := MEDIATYPE_SPDX
-	manifestDescriptor, err := oras.PackManifest(ctx, mem, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+	artifactType := opts.Layout.artifactType()
+	manifestDescriptor, err := oras.PackManifest(ctx, mem, packVersion, artifactType, oras.PackManifestOptions{
 		Layers:              layers,
 		ManifestAnnotations: annotations,
+		ConfigDescriptor:    configDescriptor,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error packing manifest: %w", err)
 	}
+	slog.Debug("packed SBOM manifest", "digest", manifestDescriptor.Digest, "layers", len(layers))
 
-	// Use the latest tag if no tag is specified
-	tag := "latest"
-	ref, err := registry.ParseReference(reference)
+	ref, refTags, err := ParsePushReference(reference)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing reference: %w", err)
+		return nil, err
+	}
+	if expected, err := ref.Digest(); err == nil && expected != manifestDescriptor.Digest {
+		return nil, fmt.Errorf("%w: the packed SBOM manifest %s does not match the digest %s of the reference", ErrDigestMismatch, manifestDescriptor.Digest, expected)
+	}
+	tags := getPushTags(ref, refTags, opts)
+
+	// the memory store resolves the manifest by its first tag for the copy
+	if len(tags) > 0 {
+		if err = mem.Tag(ctx, manifestDescriptor, tags[0]); err != nil {
+			return nil, err
+		}
 	}
 
-	if ref.Reference != "" {
-		tag = ref.Reference
+	// attach the artifacts in a stable order so that reproducible pushes attach them identically
+	var referrers []v1.Descriptor
+	for _, attachment := range opts.getAttachments() {
+		referrer, err := attach(ctx, &manifestDescriptor, attachment, created, mem)
+		if err != nil {
+			return nil, fmt.Errorf("error attaching artifact: %w", err)
+		}
+		referrers = append(referrers, referrer)
 	}
 
-	if err = mem.Tag(ctx, manifestDescriptor, tag); err != nil {
+	// Skip the copy if the same manifest is already tagged and all of its referrers exist in the destination
+	unchanged, err := isUnchanged(ctx, dest, tags, manifestDescriptor, referrers)
+	if err != nil {
 		return nil, err
 	}
+	manifestBytes, err := content.FetchAll(ctx, mem, manifestDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching manifest from memory store: %w", err)
+	}
+	result := &PushResult{Descriptor: manifestDescriptor, Tags: tags, Manifest: manifestBytes, Referrers: referrers}
+	if unchanged {
+		slog.Info("SBOM is unchanged in the destination, skipping the copy", "tags", tags, "digest", manifestDescriptor.Digest)
+		result.Unchanged = true
+		return result, nil
+	}
 
-	if len(attachArtifacts) > 0 {
-		for artifactType, paths := range attachArtifacts {
-			for _, path := range paths {
-				// load the artifact from the path
-				artifactDesc, artifactBytes, err := LoadArtifactFromFile(path, artifactType)
-				if err != nil {
-					return nil, fmt.Errorf("error loading artifact: %v", err)
-				}
-				err = AttachArtifact(ctx, &manifestDescriptor, artifactDesc, artifactType, artifactBytes, mem)
-				if err != nil {
-					return nil, fmt.Errorf("error attaching artifact: %v", err)
-				}
-			}
+	// Copy from the memory store to the remote repository
+	tracker := newProgressTracker(opts.Progress)
+	copyOpts := oras.DefaultExtendedCopyOptions
+	copyOpts.CopyGraphOptions = tracker.copyGraphOptions(copyOpts.CopyGraphOptions)
+	if len(tags) == 0 {
+		if err := oras.ExtendedCopyGraph(ctx, tracker.source(src), dest, manifestDescriptor, copyOpts.ExtendedCopyGraphOptions); err != nil {
+			return nil, err
 		}
+		return result, nil
 	}
 
-	// Copy from the memory store to the remote repository
-	manifest, err := oras.ExtendedCopy(ctx, mem, tag, dest, tag, oras.DefaultExtendedCopyOptions)
-	return &manifest, err
+	manifest, err := oras.ExtendedCopy(ctx, tracker.source(src), tags[0], dest, tags[0], copyOpts)
+	if err != nil {
+		return nil, err
+	}
+	tracker.tagged(manifest, tags[0])
+	for _, tag := range tags[1:] {
+		if err := dest.Tag(ctx, manifest, tag); err != nil {
+			return nil, fmt.Errorf("error tagging SBOM manifest with %s: %w", tag, err)
+		}
+		tracker.tagged(manifest, tag)
+	}
+	return result, nil
 }
 
-// AttachArtifact attaches an artifact to the subject descriptor
-func AttachArtifact(ctx context.Context, subject *v1.Descriptor, artifactDescriptor *v1.Descriptor, artifactType string, artifactBytes []byte, mem *memory.Store) error {
-	// Create a Reader for the bytes
-	artifactReader := bytes.NewReader(artifactBytes)
+// ParsePushReference parses a push reference in the format of registry/repository[:tag[,tag...]|@digest], returning
+// the reference of its first tag or its digest along with all of its tags.
+// The error wraps ErrReferenceInvalid when the reference or one of the tags cannot be parsed.
+func ParsePushReference(reference string) (registry.Reference, []string, error) {
+	var tags []string
+	// the tags follow the last colon after the repository path, as a colon before is the port of the registry
+	if !strings.Contains(reference, "@") {
+		if colon := strings.LastIndex(reference, ":"); colon > strings.LastIndex(reference, "/") {
+			tags = strings.Split(reference[colon+1:], ",")
+			reference = reference[:colon+1] + tags[0]
+		}
+	}
 
-	// Add descriptor to a memory store
-	err := mem.Push(ctx, *artifactDescriptor, artifactReader)
+	ref, err := registry.ParseReference(reference)
 	if err != nil {
-		return fmt.Errorf("error pushing artifact into memory store: %w", err)
+		return registry.Reference{}, nil, fmt.Errorf("%w: error parsing reference: %w", ErrReferenceInvalid, err)
+	}
+	for _, tag := range tags {
+		tagRef := ref
+		tagRef.Reference = tag
+		if err := tagRef.ValidateReferenceAsTag(); err != nil {
+			return registry.Reference{}, nil, fmt.Errorf("%w: error parsing tag %q: %w", ErrReferenceInvalid, tag, err)
+		}
 	}
+	return ref, tags, nil
+}
 
-	// Pack the artifact manifest with the subject descriptor
-	_, err = oras.PackManifest(ctx, mem, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
-		Subject: subject,
-		Layers:  []v1.Descriptor{*artifactDescriptor},
-	})
+// getPushTags returns the unique tags of the reference and of the options, or latest when there are none and the
+// reference is not a digest, and no tags when NoTag is set
+func getPushTags(ref registry.Reference, refTags []string, opts PushOptions) []string {
+	if opts.NoTag {
+		return nil
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, refTags...), opts.Tags...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 && ref.ValidateReferenceAsDigest() != nil {
+		tags = []string{"latest"}
+	}
+	return tags
+}
+
+// sbomLayerDescriptor returns the descriptor of the SBOM layer with the layer media type of the layout
+func sbomLayerDescriptor(sbomDescriptor *v1.Descriptor, opts PushOptions) v1.Descriptor {
+	sbomLayer := *sbomDescriptor
+	if opts.Layout.LayerMediaType != "" {
+		sbomLayer.MediaType = opts.Layout.LayerMediaType
+	}
+	return sbomLayer
+}
 
+// compressLayer compresses the layer bytes and returns the descriptor of the compressed layer
+func compressLayer(layer v1.Descriptor, layerBytes []byte, compression string) (v1.Descriptor, []byte, error) {
+	compressedBytes, err := CompressBytes(layerBytes, compression)
 	if err != nil {
-		return fmt.Errorf("error packing artifact manifest: %w", err)
+		return v1.Descriptor{}, nil, err
 	}
 
+	compressed := content.NewDescriptorFromBytes(CompressedMediaType(layer.MediaType, compression), compressedBytes)
+	compressed.Annotations = compressedAnnotations(layer)
+
+	return compressed, compressedBytes, nil
+}
+
+// compressedAnnotations returns the annotations of the uncompressed layer with its digest and size
+// for the compressed layer
+func compressedAnnotations(layer v1.Descriptor) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range layer.Annotations {
+		annotations[k] = v
+	}
+	annotations[OCI_ANNOTATION_UNCOMPRESSED_DIGEST] = layer.Digest.String()
+	annotations[OCI_ANNOTATION_UNCOMPRESSED_SIZE] = strconv.FormatInt(layer.Size, 10)
+	return annotations
+}
+
+// pushIfMissing pushes the data into the storage unless it already exists
+func pushIfMissing(ctx context.Context, pusher content.Pusher, desc v1.Descriptor, data []byte) error {
+	// remote repositories accept blobs that already exist, so check first to not upload them again
+	if storage, ok := pusher.(content.ReadOnlyStorage); ok {
+		if exists, err := storage.Exists(ctx, desc); err == nil && exists {
+			return nil
+		}
+	}
+	err := pusher.Push(ctx, desc, bytes.NewReader(data))
+	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
 	return nil
 }
+
+// getReproducibleCreated returns the SPDX document creation time in RFC 3339 format for the created manifest annotation
+func getReproducibleCreated(sbomDoc *v2_3.Document) (string, error) {
+	if sbomDoc.CreationInfo == nil || sbomDoc.CreationInfo.Created == "" {
+		return "", fmt.Errorf("reproducible push requires the SBOM creationInfo.created field")
+	}
+	created, err := time.Parse(time.RFC3339, sbomDoc.CreationInfo.Created)
+	if err != nil {
+		return "", fmt.Errorf("error parsing SBOM creationInfo.created field: %w", err)
+	}
+	return created.UTC().Format(time.RFC3339), nil
+}
+
+// isUnchanged reports whether all the tags already resolve to the manifest in the destination, or the manifest exists
+// when there are no tags, and all the referrers exist
+func isUnchanged(ctx context.Context, dest oras.Target, tags []string, manifest v1.Descriptor, referrers []v1.Descriptor) (bool, error) {
+	if len(tags) == 0 {
+		exists, err := dest.Exists(ctx, manifest)
+		if err != nil {
+			return false, fmt.Errorf("error checking if manifest %s exists in destination: %w", manifest.Digest, err)
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+	for _, tag := range tags {
+		existing, err := dest.Resolve(ctx, tag)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("error resolving %s in destination: %w", tag, err)
+		}
+		if existing.Digest != manifest.Digest {
+			return false, nil
+		}
+	}
+
+	for _, referrer := range referrers {
+		exists, err := dest.Exists(ctx, referrer)
+		if err != nil {
+			return false, fmt.Errorf("error checking if referrer %s exists in destination: %w", referrer.Digest, err)
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// AttachArtifact attaches an artifact to the subject descriptor, pushing the artifact and its referrer manifest to the
+// target, such as the memory store of a push or the remote repository of a published SBOM
+func AttachArtifact(ctx context.Context, subject *v1.Descriptor, artifactDescriptor *v1.Descriptor, artifactType string, artifactBytes []byte, target content.Pusher) error {
+	if err := pushIfMissing(ctx, target, *artifactDescriptor, artifactBytes); err != nil {
+		return fmt.Errorf("error pushing artifact: %w", err)
+	}
+	_, err := packReferrer(ctx, target, subject, artifactType, []v1.Descriptor{*artifactDescriptor}, nil)
+	return err
+}
@@ -3,6 +3,7 @@ package obom
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -152,3 +153,201 @@ func TestPushSBOM_Success_WithAttachArtifacts(t *testing.T) {
 		}
 	}
 }
+
+func TestPushSBOMWithOptions_ReproducibleIsUnchanged(t *testing.T) {
+	// Create an in-memory target for testing
+	memDest := memory.New()
+	ctx := context.Background()
+
+	reader := io.NopCloser(strings.NewReader(spdxStr))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	opts := PushOptions{
+		Annotations:     map[string]string{"key1": "value1"},
+		AttachArtifacts: map[string][]string{"application/json": {"../examples/artifact.example.json"}},
+		Reproducible:    true,
+	}
+
+	first, err := PushSBOMWithOptions(ctx, doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, opts)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	if first.Unchanged {
+		t.Errorf("expected first push to not be unchanged")
+	}
+
+	second, err := PushSBOMWithOptions(ctx, doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, opts)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	if !second.Unchanged {
+		t.Errorf("expected second push to be unchanged")
+	}
+	if first.Descriptor.Digest != second.Descriptor.Digest {
+		t.Errorf("expected reproducible pushes to have the same digest, got: %s and %s", first.Descriptor.Digest, second.Descriptor.Digest)
+	}
+
+	fetchedRc, err := memDest.Fetch(ctx, first.Descriptor)
+	if err != nil {
+		t.Fatalf("error fetching sbom manifest from memory store: %v", err)
+	}
+	defer fetchedRc.Close()
+
+	var fetchedManifest ocispec.Manifest
+	if err := json.NewDecoder(fetchedRc).Decode(&fetchedManifest); err != nil {
+		t.Fatalf("error decoding fetched manifest: %v", err)
+	}
+	if fetchedManifest.Annotations[ocispec.AnnotationCreated] != "2020-07-23T18:30:22Z" {
+		t.Errorf("expected annotation %s to be '2020-07-23T18:30:22Z', got: %v", ocispec.AnnotationCreated, fetchedManifest.Annotations[ocispec.AnnotationCreated])
+	}
+
+	// pushing the same SBOM to a new tag is not unchanged, but produces the same manifest
+	third, err := PushSBOMWithOptions(ctx, doc.Document, desc, sbomBytes, "localhost:5000/spdx:v2", memDest, opts)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	if third.Unchanged {
+		t.Errorf("expected push to a new tag to not be unchanged")
+	}
+	if third.Descriptor.Digest != first.Descriptor.Digest {
+		t.Errorf("expected reproducible pushes to have the same digest, got: %s and %s", first.Descriptor.Digest, third.Descriptor.Digest)
+	}
+}
+
+func TestPushSBOMWithOptions_ReproducibleRequiresCreated(t *testing.T) {
+	memDest := memory.New()
+
+	spdx := `{
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"spdxVersion": "SPDX-2.3",
+		"name" : "SPDX-Example"
+	}`
+	reader := io.NopCloser(strings.NewReader(spdx))
+	doc, desc, sbomBytes, err := LoadSBOMFromReader(reader, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromReader, got: %v", err)
+	}
+
+	_, err = PushSBOMWithOptions(context.Background(), doc.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{Reproducible: true})
+	if err == nil {
+		t.Fatalf("expected error for reproducible push without creation time, got no error")
+	}
+}
+
+func TestPushSBOMWithOptions_MultipleTags(t *testing.T) {
+	memDest := memory.New()
+	ctx := context.Background()
+
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1.2.3,3f2a1c9", memDest, PushOptions{Tags: []string{"latest", "v1.2.3"}})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	expected := []string{"v1.2.3", "3f2a1c9", "latest"}
+	if strings.Join(result.Tags, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected tags %v, got: %v", expected, result.Tags)
+	}
+	for _, tag := range expected {
+		desc, err := memDest.Resolve(ctx, tag)
+		if err != nil {
+			t.Fatalf("expected tag %s to be pushed, got: %v", tag, err)
+		}
+		if desc.Digest != result.Descriptor.Digest {
+			t.Errorf("expected tag %s to resolve to %s, got: %s", tag, result.Descriptor.Digest, desc.Digest)
+		}
+	}
+}
+
+func TestPushSBOMWithOptions_NoTag(t *testing.T) {
+	memDest := memory.New()
+	ctx := context.Background()
+
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", memDest, PushOptions{NoTag: true})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	if len(result.Tags) != 0 {
+		t.Errorf("expected no tags, got: %v", result.Tags)
+	}
+	exists, err := memDest.Exists(ctx, result.Descriptor)
+	if err != nil || !exists {
+		t.Fatalf("expected the SBOM manifest to be pushed, got: %v", err)
+	}
+	for _, tag := range []string{"v1", "latest"} {
+		if _, err := memDest.Resolve(ctx, tag); err == nil {
+			t.Errorf("expected tag %s to not be pushed", tag)
+		}
+	}
+}
+
+func TestPushSBOMWithOptions_Digest(t *testing.T) {
+	memDest := memory.New()
+	ctx := context.Background()
+
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	opts := PushOptions{Reproducible: true}
+
+	// the reproducible manifest digest is known from a first push
+	first, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", memory.New(), opts)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx@"+first.Descriptor.Digest.String(), memDest, opts)
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+	if result.Descriptor.Digest != first.Descriptor.Digest || len(result.Tags) != 0 {
+		t.Errorf("expected the SBOM to be pushed by digest %s only, got: %s tagged %v", first.Descriptor.Digest, result.Descriptor.Digest, result.Tags)
+	}
+	if _, err := memDest.Resolve(ctx, "latest"); err == nil {
+		t.Errorf("expected a digest push to not be tagged latest")
+	}
+
+	mismatch := "localhost:5000/spdx@sha256:9834876dcfb05cb167a5c24953eba58c4ac89b1adf57f28f2f9d09af107ee8f0"
+	_, err = PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, mismatch, memDest, opts)
+	if !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("expected ErrDigestMismatch for a mismatched digest, got: %v", err)
+	}
+}
+
+func TestParsePushReference(t *testing.T) {
+	ref, tags, err := ParsePushReference("localhost:5000/sboms/spdx:v1,v2")
+	if err != nil {
+		t.Fatalf("expected no error from ParsePushReference, got: %v", err)
+	}
+	if ref.String() != "localhost:5000/sboms/spdx:v1" {
+		t.Errorf("expected reference localhost:5000/sboms/spdx:v1, got: %s", ref.String())
+	}
+	if strings.Join(tags, ",") != "v1,v2" {
+		t.Errorf("expected tags v1,v2, got: %v", tags)
+	}
+
+	_, tags, err = ParsePushReference("localhost:5000/sboms/spdx")
+	if err != nil {
+		t.Fatalf("expected no error from ParsePushReference, got: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("expected no tags for a reference without tags, got: %v", tags)
+	}
+
+	if _, _, err := ParsePushReference("localhost:5000/sboms/spdx:v1,-invalid"); !errors.Is(err, ErrReferenceInvalid) {
+		t.Errorf("expected ErrReferenceInvalid for an invalid tag, got: %v", err)
+	}
+}
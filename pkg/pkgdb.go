@@ -0,0 +1,239 @@
+package obom
+
+import (
+	"bufio"
+	"bytes"
+	"debug/buildinfo"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	purl "github.com/package-url/packageurl-go"
+)
+
+// InstalledPackage is a package installed in an image, read from a package database or from the build info of a Go
+// binary
+type InstalledPackage struct {
+	// Type is the package URL type of the package: deb, apk, rpm or golang
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Source is the path of the package database or the Go binary the package was read from
+	Source string `json:"source"`
+}
+
+// packageDatabaseParser parses the installed packages of a package database file
+type packageDatabaseParser func(data []byte, source string) ([]InstalledPackage, error)
+
+// getPackageDatabaseParser returns the parser of the package database at the path relative to the root, or false
+// when the file is not a package database that can be read offline
+func getPackageDatabaseParser(name string) (packageDatabaseParser, bool) {
+	switch {
+	case name == "var/lib/dpkg/status":
+		return parseDpkgStatus, true
+	case path.Dir(name) == "var/lib/dpkg/status.d" && !strings.HasSuffix(name, ".md5sums"):
+		// distroless images have a status file per package
+		return parseDpkgStatus, true
+	case name == "lib/apk/db/installed":
+		return parseApkInstalled, true
+	case name == "var/lib/rpm/rpmdb.sqlite" || name == "usr/lib/sysimage/rpm/rpmdb.sqlite":
+		return parseRpmDB, true
+	}
+	return nil, false
+}
+
+// isUnsupportedPackageDatabase reports whether the file is an rpm Berkeley DB or NDB database, which are not read
+func isUnsupportedPackageDatabase(name string) bool {
+	switch name {
+	case "var/lib/rpm/Packages", "var/lib/rpm/Packages.db", "usr/lib/sysimage/rpm/Packages", "usr/lib/sysimage/rpm/Packages.db":
+		return true
+	}
+	return false
+}
+
+// parseDpkgStatus parses the packages of a dpkg status file, keeping the installed ones
+func parseDpkgStatus(data []byte, source string) ([]InstalledPackage, error) {
+	var packages []InstalledPackage
+	for _, stanza := range parseControlStanzas(data, ": ") {
+		if stanza["Package"] == "" {
+			continue
+		}
+		// status files of distroless images have no Status field
+		if status, ok := stanza["Status"]; ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		packages = append(packages, InstalledPackage{
+			Type:    purl.TypeDebian,
+			Name:    stanza["Package"],
+			Version: stanza["Version"],
+			Source:  source,
+		})
+	}
+	return packages, nil
+}
+
+// parseApkInstalled parses the packages of an apk installed database, whose fields are a letter and a colon such
+// as P:busybox
+func parseApkInstalled(data []byte, source string) ([]InstalledPackage, error) {
+	var packages []InstalledPackage
+	for _, stanza := range parseControlStanzas(data, ":") {
+		if stanza["P"] == "" {
+			continue
+		}
+		packages = append(packages, InstalledPackage{
+			Type:    purl.TypeApk,
+			Name:    stanza["P"],
+			Version: stanza["V"],
+			Source:  source,
+		})
+	}
+	return packages, nil
+}
+
+// parseControlStanzas parses the stanzas separated by blank lines of a dpkg or apk database into their fields,
+// ignoring the continuation lines of multiline fields
+func parseControlStanzas(data []byte, separator string) []map[string]string {
+	var stanzas []map[string]string
+	stanza := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(stanza) > 0 {
+				stanzas = append(stanzas, stanza)
+				stanza = make(map[string]string)
+			}
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		if key, value, found := strings.Cut(line, separator); found {
+			stanza[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(stanza) > 0 {
+		stanzas = append(stanzas, stanza)
+	}
+	return stanzas
+}
+
+const (
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+
+	rpmTypeInt32  = 4
+	rpmTypeString = 6
+)
+
+// parseRpmDB parses the packages of an rpm sqlite database, whose Packages table holds the header of each package
+func parseRpmDB(data []byte, source string) ([]InstalledPackage, error) {
+	db, err := openSQLite(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rpm database %s: %w", source, err)
+	}
+	rows, err := db.readTable("Packages")
+	if err != nil {
+		return nil, fmt.Errorf("error reading rpm database %s: %w", source, err)
+	}
+
+	var packages []InstalledPackage
+	for _, row := range rows {
+		// Packages(hnum INTEGER PRIMARY KEY, blob BLOB NOT NULL)
+		if len(row) < 2 {
+			continue
+		}
+		blob, ok := row[1].([]byte)
+		if !ok {
+			continue
+		}
+		tags, err := parseRpmHeader(blob)
+		if err != nil {
+			return nil, fmt.Errorf("error reading rpm database %s: %w", source, err)
+		}
+		name := tags[rpmTagName]
+		// gpg-pubkey entries are the imported signing keys rather than packages
+		if name == "" || name == "gpg-pubkey" {
+			continue
+		}
+		version := tags[rpmTagVersion]
+		if release := tags[rpmTagRelease]; release != "" {
+			version += "-" + release
+		}
+		if epoch := tags[rpmTagEpoch]; epoch != "" && epoch != "0" {
+			version = epoch + ":" + version
+		}
+		packages = append(packages, InstalledPackage{Type: purl.TypeRPM, Name: name, Version: version, Source: source})
+	}
+	return packages, nil
+}
+
+// parseRpmHeader parses the name, version, release and epoch tags of an rpm header blob: the number of index
+// entries and the size of the data, the index entries of a tag, a type, an offset and a count, then the data
+func parseRpmHeader(blob []byte) (map[int]string, error) {
+	if len(blob) < 8 {
+		return nil, fmt.Errorf("rpm header too short")
+	}
+	indexCount := int(binary.BigEndian.Uint32(blob[0:4]))
+	dataSize := int(binary.BigEndian.Uint32(blob[4:8]))
+	dataStart := 8 + indexCount*16
+	if indexCount < 0 || dataSize < 0 || dataStart+dataSize > len(blob) {
+		return nil, fmt.Errorf("invalid rpm header")
+	}
+	store := blob[dataStart : dataStart+dataSize]
+
+	tags := make(map[int]string)
+	for i := 0; i < indexCount; i++ {
+		entry := blob[8+i*16:]
+		tag := int(binary.BigEndian.Uint32(entry[0:4]))
+		tagType := binary.BigEndian.Uint32(entry[4:8])
+		offset := int(binary.BigEndian.Uint32(entry[8:12]))
+		if tag < rpmTagName || tag > rpmTagEpoch || offset < 0 || offset >= len(store) {
+			continue
+		}
+		switch tagType {
+		case rpmTypeString:
+			value := store[offset:]
+			if end := bytes.IndexByte(value, 0); end >= 0 {
+				value = value[:end]
+			}
+			tags[tag] = string(value)
+		case rpmTypeInt32:
+			if offset+4 <= len(store) {
+				tags[tag] = strconv.FormatUint(uint64(binary.BigEndian.Uint32(store[offset:])), 10)
+			}
+		}
+	}
+	return tags, nil
+}
+
+// parseGoBinary returns the main module and the dependencies of the build info of a Go binary, or no packages when
+// the file is not a Go binary
+func parseGoBinary(data []byte, source string) []InstalledPackage {
+	info, err := buildinfo.Read(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	var packages []InstalledPackage
+	if info.Main.Path != "" {
+		version := info.Main.Version
+		if version == "(devel)" {
+			version = ""
+		}
+		packages = append(packages, InstalledPackage{Type: purl.TypeGolang, Name: info.Main.Path, Version: version, Source: source})
+	}
+	for _, dep := range info.Deps {
+		version := dep.Version
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			version = dep.Replace.Version
+		}
+		packages = append(packages, InstalledPackage{Type: purl.TypeGolang, Name: dep.Path, Version: version, Source: source})
+	}
+	return packages
+}
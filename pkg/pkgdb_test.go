@@ -0,0 +1,207 @@
+package obom
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDpkgStatus(t *testing.T) {
+	status := `Package: libc6
+Status: install ok installed
+Version: 2.36-9+deb12u4
+Description: GNU C Library
+ multiline description
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+
+Package: base-files
+Status: install ok installed
+Version: 12.4+deb12u5
+`
+	packages, err := parseDpkgStatus([]byte(status), "var/lib/dpkg/status")
+	if err != nil {
+		t.Fatalf("expected no error from parseDpkgStatus, got: %v", err)
+	}
+	expected := []InstalledPackage{
+		{Type: "deb", Name: "libc6", Version: "2.36-9+deb12u4", Source: "var/lib/dpkg/status"},
+		{Type: "deb", Name: "base-files", Version: "12.4+deb12u5", Source: "var/lib/dpkg/status"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("expected the installed packages %v, got: %v", expected, packages)
+	}
+}
+
+func TestParseApkInstalled(t *testing.T) {
+	installed := "C:Q1abc=\nP:musl\nV:1.2.4-r2\nA:x86_64\n\nP:busybox\nV:1.36.1-r5\n"
+	packages, err := parseApkInstalled([]byte(installed), "lib/apk/db/installed")
+	if err != nil {
+		t.Fatalf("expected no error from parseApkInstalled, got: %v", err)
+	}
+	expected := []InstalledPackage{
+		{Type: "apk", Name: "musl", Version: "1.2.4-r2", Source: "lib/apk/db/installed"},
+		{Type: "apk", Name: "busybox", Version: "1.36.1-r5", Source: "lib/apk/db/installed"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("expected the installed packages %v, got: %v", expected, packages)
+	}
+}
+
+func TestParseRpmDB(t *testing.T) {
+	// the openssl header is larger than a page, its payload continues in overflow pages
+	data, err := os.ReadFile("testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error reading the rpm database, got: %v", err)
+	}
+	packages, err := parseRpmDB(data, "var/lib/rpm/rpmdb.sqlite")
+	if err != nil {
+		t.Fatalf("expected no error from parseRpmDB, got: %v", err)
+	}
+	expected := []InstalledPackage{
+		{Type: "rpm", Name: "bash", Version: "5.1.8-6.el9", Source: "var/lib/rpm/rpmdb.sqlite"},
+		{Type: "rpm", Name: "openssl", Version: "1:3.0.7-27.el9", Source: "var/lib/rpm/rpmdb.sqlite"},
+	}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("expected the installed packages %v, got: %v", expected, packages)
+	}
+
+	if _, err := parseRpmDB([]byte("not a database"), "var/lib/rpm/rpmdb.sqlite"); err == nil {
+		t.Errorf("expected an error from parseRpmDB for an invalid database")
+	}
+}
+
+func TestGetPackageDatabaseParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"var/lib/dpkg/status", true},
+		{"var/lib/dpkg/status.d/base", true},
+		{"var/lib/dpkg/status.d/base.md5sums", false},
+		{"lib/apk/db/installed", true},
+		{"usr/lib/sysimage/rpm/rpmdb.sqlite", true},
+		{"var/lib/rpm/Packages", false},
+		{"etc/passwd", false},
+	}
+	for _, test := range tests {
+		if _, found := getPackageDatabaseParser(test.name); found != test.expected {
+			t.Errorf("expected getPackageDatabaseParser(%q) to be %v, got: %v", test.name, test.expected, found)
+		}
+	}
+}
+
+func TestParseGoBinary(t *testing.T) {
+	// the test binary is a Go binary with the build info of this module
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("expected no error from os.Executable, got: %v", err)
+	}
+	data, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatalf("expected no error reading the test binary, got: %v", err)
+	}
+	packages := parseGoBinary(data, "usr/bin/obom.test")
+	found := false
+	for _, pkg := range packages {
+		if pkg.Type != "golang" {
+			t.Errorf("expected golang packages, got: %v", pkg)
+		}
+		if pkg.Name == "github.com/spdx/tools-golang" && pkg.Version != "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the dependency github.com/spdx/tools-golang, got: %v", packages)
+	}
+
+	if packages := parseGoBinary([]byte("#!/bin/sh\n"), "usr/bin/script"); len(packages) != 0 {
+		t.Errorf("expected no packages for a script, got: %v", packages)
+	}
+}
+
+// craftedSQLite returns a database of two pages of 512 bytes whose schema has a single cell of the payload size,
+// continued in the overflow page, which links to itself
+func craftedSQLite(payloadSize uint64) []byte {
+	const pageSize = 512
+	data := make([]byte, 2*pageSize)
+	copy(data, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(data[16:18], pageSize)
+
+	// a leaf table page with one cell at offset 300
+	header := data[100:]
+	header[0] = 0x0d
+	binary.BigEndian.PutUint16(header[3:5], 1)
+	binary.BigEndian.PutUint16(header[8:10], 300)
+
+	var groups []byte
+	for v := payloadSize; ; v >>= 7 {
+		groups = append([]byte{byte(v & 0x7f)}, groups...)
+		if v < 0x80 {
+			break
+		}
+	}
+	for i := 0; i < len(groups)-1; i++ {
+		groups[i] |= 0x80
+	}
+	cell := append(groups, 1) // row id
+	cell = append(cell, make([]byte, 39)...)
+	cell = binary.BigEndian.AppendUint32(cell, 2)
+	copy(data[300:], cell)
+
+	binary.BigEndian.PutUint32(data[pageSize:], 2)
+	return data
+}
+
+// craftedSQLiteRecord returns a database of a single page of 512 bytes whose schema has a single cell of the record
+func craftedSQLiteRecord(record []byte) []byte {
+	const pageSize = 512
+	data := make([]byte, pageSize)
+	copy(data, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(data[16:18], pageSize)
+
+	header := data[100:]
+	header[0] = 0x0d
+	binary.BigEndian.PutUint16(header[3:5], 1)
+	binary.BigEndian.PutUint16(header[8:10], 300)
+
+	cell := append([]byte{byte(len(record)), 1}, record...) // payload size and row id
+	copy(data[300:], cell)
+	return data
+}
+
+func TestSQLiteCraftedDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"payload larger than the database", craftedSQLite(1 << 33), "exceeds the database"},
+		{"overflow page cycle", craftedSQLite(1000), "referenced twice"},
+		{"record header size overflowing int", craftedSQLiteRecord([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}), "invalid record header"},
+		{"record header size smaller than its varint", craftedSQLiteRecord([]byte{0x81, 0x00, 0x00}), "invalid record header"},
+	}
+	for _, test := range tests {
+		db, err := openSQLite(test.data)
+		if err != nil {
+			t.Fatalf("expected no error from openSQLite, got: %v", err)
+		}
+		if _, err := db.readTable("Packages"); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("expected an error containing %q from readTable of the %s, got: %v", test.expected, test.name, err)
+		}
+	}
+
+	// a cell count beyond the page
+	data := craftedSQLite(10)
+	binary.BigEndian.PutUint16(data[103:105], 0xffff)
+	db, err := openSQLite(data)
+	if err != nil {
+		t.Fatalf("expected no error from openSQLite, got: %v", err)
+	}
+	if _, err := db.readTable("Packages"); err == nil || !strings.Contains(err.Error(), "invalid SQLite cell count") {
+		t.Errorf("expected an invalid cell count error from readTable, got: %v", err)
+	}
+}
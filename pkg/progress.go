@@ -0,0 +1,144 @@
+package obom
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// ProgressEventType is the type of a ProgressEvent
+type ProgressEventType string
+
+const (
+	// PROGRESS_BLOB_STARTED is reported when the copy of a blob or manifest starts
+	PROGRESS_BLOB_STARTED ProgressEventType = "started"
+	// PROGRESS_BLOB_TRANSFERRED is reported as the bytes of a blob or manifest are transferred
+	PROGRESS_BLOB_TRANSFERRED ProgressEventType = "transferred"
+	// PROGRESS_BLOB_SKIPPED is reported when a blob or manifest already exists in the destination
+	PROGRESS_BLOB_SKIPPED ProgressEventType = "skipped"
+	// PROGRESS_BLOB_DONE is reported when the copy of a blob or manifest is done
+	PROGRESS_BLOB_DONE ProgressEventType = "done"
+	// PROGRESS_MANIFEST_TAGGED is reported when the root manifest is tagged in the destination
+	PROGRESS_MANIFEST_TAGGED ProgressEventType = "tagged"
+)
+
+// ProgressEvent reports the progress of a push or a copy
+type ProgressEvent struct {
+	// Type is the type of the event
+	Type ProgressEventType
+	// Descriptor is the descriptor of the blob or manifest of the event
+	Descriptor v1.Descriptor
+	// BytesTransferred is the number of bytes of the descriptor transferred so far, the size of the descriptor once
+	// it is done
+	BytesTransferred int64
+	// Reference is the tag of the PROGRESS_MANIFEST_TAGGED events
+	Reference string
+}
+
+// ProgressFunc handles the progress events of a push or a copy. Blobs are copied concurrently, so the function may be
+// called concurrently and must be safe for concurrent use.
+type ProgressFunc func(event ProgressEvent)
+
+// progressTracker reports the progress events of the copies of a graph
+type progressTracker struct {
+	progress ProgressFunc
+	// active holds the digests of the descriptors being copied, so that the bytes read to walk the graph are not
+	// reported as transferred
+	active sync.Map
+}
+
+// newProgressTracker returns the tracker reporting the events to the progress function, or nil without one
+func newProgressTracker(progress ProgressFunc) *progressTracker {
+	if progress == nil {
+		return nil
+	}
+	return &progressTracker{progress: progress}
+}
+
+// source wraps the source of the copy to report the bytes transferred of the descriptors being copied
+func (t *progressTracker) source(src oras.ReadOnlyGraphTarget) oras.ReadOnlyGraphTarget {
+	if t == nil {
+		return src
+	}
+	return &progressSource{ReadOnlyGraphTarget: src, tracker: t}
+}
+
+// copyGraphOptions adds the progress hooks to the copy options, keeping the existing hooks
+func (t *progressTracker) copyGraphOptions(opts oras.CopyGraphOptions) oras.CopyGraphOptions {
+	if t == nil {
+		return opts
+	}
+
+	preCopy, postCopy, onCopySkipped := opts.PreCopy, opts.PostCopy, opts.OnCopySkipped
+	opts.PreCopy = func(ctx context.Context, desc v1.Descriptor) error {
+		if preCopy != nil {
+			if err := preCopy(ctx, desc); err != nil {
+				return err
+			}
+		}
+		t.active.Store(desc.Digest, true)
+		t.progress(ProgressEvent{Type: PROGRESS_BLOB_STARTED, Descriptor: desc})
+		return nil
+	}
+	opts.PostCopy = func(ctx context.Context, desc v1.Descriptor) error {
+		t.active.Delete(desc.Digest)
+		t.progress(ProgressEvent{Type: PROGRESS_BLOB_DONE, Descriptor: desc, BytesTransferred: desc.Size})
+		if postCopy != nil {
+			return postCopy(ctx, desc)
+		}
+		return nil
+	}
+	opts.OnCopySkipped = func(ctx context.Context, desc v1.Descriptor) error {
+		t.progress(ProgressEvent{Type: PROGRESS_BLOB_SKIPPED, Descriptor: desc})
+		if onCopySkipped != nil {
+			return onCopySkipped(ctx, desc)
+		}
+		return nil
+	}
+	return opts
+}
+
+// tagged reports that the manifest was tagged with the reference
+func (t *progressTracker) tagged(desc v1.Descriptor, reference string) {
+	if t == nil {
+		return
+	}
+	t.progress(ProgressEvent{Type: PROGRESS_MANIFEST_TAGGED, Descriptor: desc, Reference: reference})
+}
+
+// progressSource is a copy source reporting the bytes read of the descriptors being copied
+type progressSource struct {
+	oras.ReadOnlyGraphTarget
+	tracker *progressTracker
+}
+
+func (s *progressSource) Fetch(ctx context.Context, target v1.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.ReadOnlyGraphTarget.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.tracker.active.Load(target.Digest); !ok {
+		return rc, nil
+	}
+	return &progressReader{ReadCloser: rc, desc: target, progress: s.tracker.progress}, nil
+}
+
+// progressReader reports the bytes read from the content of a descriptor
+type progressReader struct {
+	io.ReadCloser
+	desc     v1.Descriptor
+	progress ProgressFunc
+	read     int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress(ProgressEvent{Type: PROGRESS_BLOB_TRANSFERRED, Descriptor: r.desc, BytesTransferred: r.read})
+	}
+	return n, err
+}
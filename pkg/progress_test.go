@@ -0,0 +1,113 @@
+package obom
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// progressRecorder records the progress events, safe for concurrent use
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []ProgressEvent
+}
+
+func (r *progressRecorder) record(event ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// byType returns the events of the type by the digest of their descriptor
+func (r *progressRecorder) byType(eventType ProgressEventType) map[digest.Digest]ProgressEvent {
+	events := make(map[digest.Digest]ProgressEvent)
+	for _, event := range r.events {
+		if event.Type == eventType {
+			events[event.Descriptor.Digest] = event
+		}
+	}
+	return events
+}
+
+func TestPushSBOMWithOptions_Progress(t *testing.T) {
+	ctx := context.Background()
+	sbom, err := ReadSBOM(strings.NewReader(spdxStr), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+
+	var recorder progressRecorder
+	dest := memory.New()
+	result, err := PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbom.Bytes, "localhost:5000/spdx:v1", dest, PushOptions{
+		AttachArtifacts: map[string][]string{"application/json": {"../examples/artifact.example.json"}},
+		Progress:        recorder.record,
+	})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	started := recorder.byType(PROGRESS_BLOB_STARTED)
+	done := recorder.byType(PROGRESS_BLOB_DONE)
+	// the SBOM layer, the empty config, the SBOM manifest and the artifact layer and manifest
+	if len(started) != 5 || len(done) != 5 {
+		t.Fatalf("expected 5 started and done descriptors, got: %d started and %d done", len(started), len(done))
+	}
+	for d := range started {
+		if _, ok := done[d]; !ok {
+			t.Errorf("expected started descriptor %s to be done", d)
+		}
+	}
+
+	layer, ok := done[sbom.Descriptor.Digest]
+	if !ok {
+		t.Fatalf("expected the SBOM layer to be done")
+	}
+	if layer.BytesTransferred != sbom.Descriptor.Size {
+		t.Errorf("expected %d bytes transferred for the SBOM layer, got: %d", sbom.Descriptor.Size, layer.BytesTransferred)
+	}
+	transferred := recorder.byType(PROGRESS_BLOB_TRANSFERRED)
+	if event, ok := transferred[sbom.Descriptor.Digest]; !ok || event.BytesTransferred == 0 {
+		t.Errorf("expected the bytes of the SBOM layer to be reported as transferred")
+	}
+
+	tagged := recorder.byType(PROGRESS_MANIFEST_TAGGED)
+	event, ok := tagged[result.Descriptor.Digest]
+	if !ok {
+		t.Fatalf("expected the SBOM manifest to be reported as tagged")
+	}
+	if event.Reference != "v1" {
+		t.Errorf("expected the SBOM manifest to be tagged v1, got: %s", event.Reference)
+	}
+}
+
+func TestCopySBOMWithOptions_ProgressSkipped(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	pushTestSBOMWithArtifacts(t, src, "localhost:5000/spdx:v1")
+
+	// copy once so that every descriptor exists in the destination
+	dst := memory.New()
+	if _, err := CopySBOM(ctx, src, "v1", dst, "v1", true, nil); err != nil {
+		t.Fatalf("expected no error from CopySBOM, got: %v", err)
+	}
+
+	var recorder progressRecorder
+	desc, err := CopySBOMWithOptions(ctx, src, "v1", dst, "v2", CopyOptions{Progress: recorder.record})
+	if err != nil {
+		t.Fatalf("expected no error from CopySBOMWithOptions, got: %v", err)
+	}
+
+	if started := recorder.byType(PROGRESS_BLOB_STARTED); len(started) != 0 {
+		t.Errorf("expected no descriptor to be copied, got: %d", len(started))
+	}
+	if _, ok := recorder.byType(PROGRESS_BLOB_SKIPPED)[desc.Digest]; !ok {
+		t.Errorf("expected the existing SBOM manifest to be reported as skipped")
+	}
+	if event, ok := recorder.byType(PROGRESS_MANIFEST_TAGGED)[desc.Digest]; !ok || event.Reference != "v2" {
+		t.Errorf("expected the SBOM manifest to be reported as tagged v2")
+	}
+}
@@ -2,16 +2,12 @@ package obom
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	purl "github.com/package-url/packageurl-go"
 	spdxjson "github.com/spdx/tools-golang/json"
-	v2common "github.com/spdx/tools-golang/spdx/v2/common"
 	"github.com/spdx/tools-golang/spdx/v2/v2_3"
 )
 
@@ -30,87 +26,136 @@ type SPDXDocument struct {
 	Document *v2_3.Document `json:"document"`
 }
 
-// LoadSBOMFromFile opens a file given by filename, reads its contents, and loads it into an SPDX document.
-// It also calculates the file size and generates an OCI descriptor for the file.
-// It returns the loaded SPDX document, the OCI descriptor, and any error encountered.
-// If the descriptor doesn't have a title annotation, it will be added using the base filename.
-func LoadSBOMFromFile(filename string, strict bool) (*SPDXDocument, *ocispec.Descriptor, []byte, error) {
-	file, err := os.Open(filename)
+// SBOM is an SPDX document loaded along with the descriptor and the bytes of the document
+type SBOM struct {
+	// SPDXDocument is the parsed SPDX document
+	*SPDXDocument
+	// Descriptor describes the document bytes, with the file name in the title annotation when loaded from a file
+	Descriptor ocispec.Descriptor
+	// Bytes are the document bytes, decompressed when the document was compressed. They are not held in memory for
+	// a document loaded from a file by LoadSBOM, whose bytes are read from the file again by Open.
+	Bytes []byte
+
+	// filename is the file the document was loaded from by LoadSBOM
+	filename string
+}
+
+// LoadSBOM opens a file given by filename and loads it into an SPDX document, streaming the file to compute its
+// descriptor while decoding it, see ReadSBOM. The bytes of the document are not held in memory.
+// Gzip and zstd compressed files are decompressed. If the descriptor doesn't have a title annotation,
+// it will be added using the base filename.
+func LoadSBOM(filename string, strict bool) (*SBOM, error) {
+	reader, err := openSBOMFile(filename)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	defer file.Close()
+	defer reader.Close()
 
-	doc, desc, sbomBytes, err := LoadSBOMFromReader(file, strict)
+	doc, desc, err := decodeSBOMStream(reader, io.Discard, strict)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 
-	// Add filename annotation if missing
-	AddFilenameAnnotationIfMissing(desc, filename)
+	// Add filename annotation if missing, without the compression extension as the SBOM is decompressed
+	AddFilenameAnnotationIfMissing(desc, trimCompressionExtension(filename))
 
-	return doc, desc, sbomBytes, nil
+	return &SBOM{SPDXDocument: doc, Descriptor: *desc, filename: filename}, nil
 }
 
-// LoadSBOMFromReader reads an SPDX document from an io.ReadCloser, generates an OCI descriptor for the document,
-// and returns the loaded SPDX document and the OCI descriptor.
-// If an error occurs during reading the document or generating the descriptor, the error will be returned.
-func LoadSBOMFromReader(reader io.ReadCloser, strict bool) (*SPDXDocument, *ocispec.Descriptor, []byte, error) {
-	defer reader.Close()
-
-	desc, sbomBytes, err := LoadArtifactFromReader(reader, MEDIATYPE_SPDX)
+// ReadSBOM reads an SPDX document from the reader and generates an OCI descriptor for the document.
+// The document is decoded as it is read, computing the descriptor along the way, and its bytes are kept in Bytes.
+// Gzip and zstd compressed documents are decompressed, and the descriptor describes the decompressed document.
+// The error wraps ErrNotSPDX when the document is not an SPDX JSON document, and ErrStrictParse when strict is
+// set and the document does not conform to the SPDX specification.
+func ReadSBOM(reader io.Reader, strict bool) (*SBOM, error) {
+	sbomReader, err := DecompressReader(io.NopCloser(reader))
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
+	defer sbomReader.Close()
 
-	doc, err := getSPDXDocumentFromSBOMBytes(sbomBytes, strict)
+	var sbomBytes bytes.Buffer
+	doc, desc, err := decodeSBOMStream(sbomReader, &sbomBytes, strict)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 
-	return doc, desc, sbomBytes, nil
+	return &SBOM{SPDXDocument: doc, Descriptor: *desc, Bytes: sbomBytes.Bytes()}, nil
 }
 
-func getSPDXDocumentFromSBOMBytes(sbomBytes []byte, strict bool) (*SPDXDocument, error) {
-	var jsonDoc map[string]interface{}
-	err := json.Unmarshal(sbomBytes, &jsonDoc)
+// Open returns a reader of the decompressed document bytes, from Bytes when they are set, otherwise from the file the
+// document was loaded from
+func (s *SBOM) Open() (io.ReadCloser, error) {
+	if s.Bytes != nil {
+		return io.NopCloser(bytes.NewReader(s.Bytes)), nil
+	}
+	if s.filename == "" {
+		return nil, fmt.Errorf("SBOM has neither bytes nor a file")
+	}
+	return openSBOMFile(s.filename)
+}
+
+// ReadBytes returns the decompressed document bytes, reading them from the file the document was loaded from when
+// they are not held in memory
+func (s *SBOM) ReadBytes() ([]byte, error) {
+	if s.Bytes != nil {
+		return s.Bytes, nil
+	}
+	reader, err := s.Open()
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling SBOM bytes: %w", err)
+		return nil, err
 	}
+	defer reader.Close()
 
-	version, ok := jsonDoc["spdxVersion"].(string)
-	if !ok {
-		return nil, fmt.Errorf("SBOM does not contain spdxVersion field")
+	sbomBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SBOM: %w", err)
 	}
+	return sbomBytes, nil
+}
 
-	sbomReader := bytes.NewReader(sbomBytes)
-	doc, err := spdxjson.Read(sbomReader)
-	if err != nil && !strict {
-		fmt.Printf("Warning: error parsing SPDX document: %v. Falling back to simple JSON parsing.\n", err)
-		doc, err = GetSBOMFromMap(jsonDoc)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing SPDX document from map: %w", err)
-		}
+// LoadSBOMFromFile loads the SPDX document of the file along with its descriptor and bytes, see LoadSBOM.
+//
+// Deprecated: use LoadSBOM, which returns the document and the descriptor as a single result without holding the bytes
+// in memory.
+func LoadSBOMFromFile(filename string, strict bool) (*SPDXDocument, *ocispec.Descriptor, []byte, error) {
+	sbom, err := LoadSBOM(filename, strict)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	if err != nil && strict {
-		return nil, fmt.Errorf("error parsing SPDX document: %w", err)
+	sbomBytes, err := sbom.ReadBytes()
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	return sbom.SPDXDocument, &sbom.Descriptor, sbomBytes, nil
+}
+
+// LoadSBOMFromReader loads the SPDX document of the reader along with its descriptor and bytes, and closes the
+// reader, see ReadSBOM.
+//
+// Deprecated: use ReadSBOM, which returns the document, the descriptor and the bytes as a single result.
+func LoadSBOMFromReader(reader io.ReadCloser, strict bool) (*SPDXDocument, *ocispec.Descriptor, []byte, error) {
+	defer reader.Close()
 
-	return &SPDXDocument{Version: version, Document: doc}, nil
+	sbom, err := ReadSBOM(reader, strict)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sbom.SPDXDocument, &sbom.Descriptor, sbom.Bytes, nil
 }
 
 func GetSBOMFromMap(sbomMap map[string]interface{}) (*v2_3.Document, error) {
 	version, ok := sbomMap["spdxVersion"].(string)
 	if !ok {
-		return nil, fmt.Errorf("SBOM does not contain spdxVersion field")
+		return nil, fmt.Errorf("%w: SBOM does not contain spdxVersion field", ErrNotSPDX)
 	}
 	namespace, ok := sbomMap["documentNamespace"].(string)
 	if !ok {
-		return nil, fmt.Errorf("SBOM does not contain documentNamespace field")
+		return nil, fmt.Errorf("%w: SBOM does not contain documentNamespace field", ErrNotSPDX)
 	}
 	name, ok := sbomMap["name"].(string)
 	if !ok {
-		return nil, fmt.Errorf("SBOM does not contain name field")
+		return nil, fmt.Errorf("%w: SBOM does not contain name field", ErrNotSPDX)
 	}
 
 	return &v2_3.Document{
@@ -144,102 +189,115 @@ func GetAnnotations(sbomDoc *SPDXDocument) (map[string]string, error) {
 	return annotations, nil
 }
 
-// GetPackages returns the packages from the SBOM
-func GetPackages(sbom *v2_3.Document) ([]string, error) {
-	var packages []string
+// GetDefaultAnnotations returns the standard OCI annotations derived from the SBOM: the creation time, the title,
+// version and licenses of the package the SBOM describes, and the source and revision of its VCS download location.
+// Annotations that cannot be derived are left out.
+func GetDefaultAnnotations(sbom *v2_3.Document) map[string]string {
+	annotations := make(map[string]string)
 
-	for _, pkg := range sbom.Packages {
-		if pkg.PackageExternalReferences != nil {
-			for _, exRef := range pkg.PackageExternalReferences {
-				packages = append(packages, exRef.Locator)
+	if created, err := getReproducibleCreated(sbom); err == nil {
+		annotations[ocispec.AnnotationCreated] = created
+	}
+
+	license := sbom.DataLicense
+	if pkg := GetRootPackage(sbom); pkg != nil {
+		if pkg.PackageName != "" {
+			annotations[ocispec.AnnotationTitle] = pkg.PackageName
+		}
+		if pkg.PackageVersion != "" {
+			annotations[ocispec.AnnotationVersion] = pkg.PackageVersion
+		}
+		if isLicenseSet(pkg.PackageLicenseDeclared) {
+			license = pkg.PackageLicenseDeclared
+		} else if isLicenseSet(pkg.PackageLicenseConcluded) {
+			license = pkg.PackageLicenseConcluded
+		}
+		if source, revision, ok := parseVCSLocation(pkg.PackageDownloadLocation); ok {
+			annotations[ocispec.AnnotationSource] = source
+			if revision != "" {
+				annotations[ocispec.AnnotationRevision] = revision
 			}
 		}
 	}
-
-	return packages, nil
-}
-
-func GetFiles(sbom *v2_3.Document) ([]string, error) {
-	var files []string
-
-	for _, file := range sbom.Files {
-		files = append(files, file.FileName)
+	if isLicenseSet(license) {
+		annotations[ocispec.AnnotationLicenses] = license
 	}
 
-	return files, nil
-}
-
-type SBOMSummary struct {
-	SbomSummary struct {
-		Files    []string         `json:"files"`
-		Packages []PackageSummary `json:"packages"`
-	} `json:"sbomSummary"`
+	return annotations
 }
 
-type PackageSummary struct {
-	Name           string `json:"name"`
-	Version        string `json:"version"`
-	License        string `json:"license"`
-	PackageManager string `json:"packageManager"`
+// isLicenseSet reports whether the SPDX license expression is set to an actual license
+func isLicenseSet(license string) bool {
+	return license != "" && license != "NOASSERTION" && license != "NONE"
 }
 
-func GetPackageSummary(pkg *v2_3.Package) (*PackageSummary, error) {
-	var packageSummary PackageSummary
-
-	packageSummary.Name = pkg.PackageName
-	packageSummary.Version = pkg.PackageVersion
-	packageSummary.License = pkg.PackageLicenseDeclared
-	packageManager, _ := GetPackageManager(pkg.PackageExternalReferences)
-	if packageManager != "" {
-		packageSummary.PackageManager = packageManager
+// parseVCSLocation parses an SPDX VCS download location in the format of
+// <vcs_tool>+<transport>://<host_name>[/<path_to_repository>][@<revision_tag_or_branch>][#<sub_path>]
+// into the repository URL without its user info, which may hold credentials, and the revision
+func parseVCSLocation(location string) (string, string, bool) {
+	tool, url, found := strings.Cut(location, "+")
+	if !found {
+		return "", "", false
+	}
+	switch tool {
+	case "git", "hg", "svn", "bzr":
+	default:
+		return "", "", false
 	}
 
-	return &packageSummary, nil
-}
+	url, _, _ = strings.Cut(url, "#")
+	scheme, rest, found := strings.Cut(url, "://")
+	if !found {
+		return "", "", false
+	}
 
-func GetPackageManager(externalReferences []*v2_3.PackageExternalReference) (string, error) {
-	for _, exRef := range externalReferences {
-		if exRef.Category == v2common.CategoryPackageManager && exRef.RefType == v2common.TypePackageManagerPURL {
-			packageUrl, err := purl.FromString(exRef.Locator)
-			if err != nil {
-				return "", fmt.Errorf("error parsing package url for %s: %v", exRef.Locator, err)
-			}
-			return packageUrl.Type, nil
+	// an @ in the host is the user info, which is left out, and the revision follows the last @ of the path
+	host, path, hasPath := strings.Cut(rest, "/")
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	var revision string
+	if hasPath {
+		if at := strings.LastIndex(path, "@"); at >= 0 {
+			revision = path[at+1:]
+			path = path[:at]
 		}
+		rest = host + "/" + path
+	} else {
+		rest = host
 	}
-
-	return "", fmt.Errorf("no package manager found")
+	return scheme + "://" + rest, revision, true
 }
 
-func GetPackageSummaries(sbom *v2_3.Document) ([]PackageSummary, error) {
-	var packageSummaries []PackageSummary
+// GetPackages returns the packages from the SBOM
+func GetPackages(sbom *v2_3.Document) ([]string, error) {
+	var packages []string
 
 	for _, pkg := range sbom.Packages {
-		packageSummary, err := GetPackageSummary(pkg)
-		if err != nil {
-			return nil, err
+		if pkg.PackageExternalReferences != nil {
+			for _, exRef := range pkg.PackageExternalReferences {
+				packages = append(packages, exRef.Locator)
+			}
 		}
-		packageSummaries = append(packageSummaries, *packageSummary)
 	}
 
-	return packageSummaries, nil
+	return packages, nil
 }
 
-func GetSBOMSummary(sbom *v2_3.Document) (*SBOMSummary, error) {
-	var sbomSummary SBOMSummary
-
-	files, err := GetFiles(sbom)
-	if err != nil {
-		return nil, err
-	}
+func GetFiles(sbom *v2_3.Document) ([]string, error) {
+	var files []string
 
-	packages, err := GetPackageSummaries(sbom)
-	if err != nil {
-		return nil, err
+	for _, file := range sbom.Files {
+		files = append(files, file.FileName)
 	}
 
-	sbomSummary.SbomSummary.Files = files
-	sbomSummary.SbomSummary.Packages = packages
+	return files, nil
+}
 
-	return &sbomSummary, nil
+// WriteSBOM writes the SPDX document to the writer as indented SPDX JSON
+func WriteSBOM(writer io.Writer, sbom *v2_3.Document) error {
+	if err := spdxjson.Write(sbom, writer, spdxjson.Indent("  ")); err != nil {
+		return fmt.Errorf("error writing SPDX document: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,308 @@
+package obom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// sqliteHeader is the header string of SQLite database files
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+const (
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+)
+
+// sqliteDatabase is a minimal read-only reader of the tables of an SQLite database file, enough to read the
+// package headers of an rpm sqlite database without an SQLite driver
+type sqliteDatabase struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+// openSQLite reads the header of an SQLite database file
+func openSQLite(data []byte) (*sqliteDatabase, error) {
+	if len(data) < 100 || !bytes.HasPrefix(data, sqliteHeader) {
+		return nil, fmt.Errorf("not an SQLite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || len(data)%pageSize != 0 {
+		return nil, fmt.Errorf("invalid SQLite page size %d", pageSize)
+	}
+	// the usable size of the pages is at least 480 bytes, as per the SQLite file format
+	usableSize := pageSize - int(data[20])
+	if usableSize < 480 {
+		return nil, fmt.Errorf("invalid SQLite usable page size %d", usableSize)
+	}
+	return &sqliteDatabase{data: data, pageSize: pageSize, usableSize: usableSize}, nil
+}
+
+// readTable returns the rows of the table, each row being the values of its columns: nil, int64, float64, string or
+// []byte. An INTEGER PRIMARY KEY column is NULL, as SQLite stores it as the row id.
+func (db *sqliteDatabase) readTable(name string) ([][]any, error) {
+	// the schema table sqlite_schema(type, name, tbl_name, rootpage, sql) is rooted at the first page
+	schema, err := db.readBTree(1)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SQLite schema: %w", err)
+	}
+	for _, row := range schema {
+		if len(row) < 4 || row[0] != "table" || row[1] != name {
+			continue
+		}
+		rootPage, ok := row[3].(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid root page of SQLite table %s", name)
+		}
+		rows, err := db.readBTree(int(rootPage))
+		if err != nil {
+			return nil, fmt.Errorf("error reading SQLite table %s: %w", name, err)
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("SQLite table %s not found", name)
+}
+
+// page returns the content of the page, numbered from 1
+func (db *sqliteDatabase) page(number int) ([]byte, error) {
+	if number < 1 || number*db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("invalid SQLite page %d", number)
+	}
+	return db.data[(number-1)*db.pageSize : number*db.pageSize], nil
+}
+
+// readBTree returns the records of the table b-tree rooted at the page, in the order of their row ids
+func (db *sqliteDatabase) readBTree(rootPage int) ([][]any, error) {
+	var rows [][]any
+	visited := make(map[int]bool)
+	var walk func(number int) error
+	walk = func(number int) error {
+		if visited[number] {
+			return fmt.Errorf("SQLite page %d is referenced twice", number)
+		}
+		visited[number] = true
+
+		page, err := db.page(number)
+		if err != nil {
+			return err
+		}
+		// the first page starts with the database header
+		headerOffset := 0
+		if number == 1 {
+			headerOffset = 100
+		}
+		header := page[headerOffset:]
+		if len(header) < 12 {
+			return fmt.Errorf("invalid SQLite page %d", number)
+		}
+		cellCount := int(binary.BigEndian.Uint16(header[3:5]))
+
+		// the cell pointers follow the page header, of 12 bytes for interior pages and 8 bytes for leaf pages
+		headerSize := 8
+		if header[0] == sqlitePageInteriorTable {
+			headerSize = 12
+		}
+		if headerSize+2*cellCount > len(header) {
+			return fmt.Errorf("invalid SQLite cell count %d of page %d", cellCount, number)
+		}
+		cellPointers := header[headerSize:]
+		cellOffset := func(i int) (int, error) {
+			cell := int(binary.BigEndian.Uint16(cellPointers[2*i:]))
+			if cell < headerOffset+headerSize+2*cellCount || cell >= db.usableSize {
+				return 0, fmt.Errorf("invalid SQLite cell offset %d of page %d", cell, number)
+			}
+			return cell, nil
+		}
+
+		switch header[0] {
+		case sqlitePageInteriorTable:
+			for i := 0; i < cellCount; i++ {
+				cell, err := cellOffset(i)
+				if err != nil {
+					return err
+				}
+				if cell+4 > len(page) {
+					return fmt.Errorf("invalid SQLite cell of page %d", number)
+				}
+				if err := walk(int(binary.BigEndian.Uint32(page[cell:]))); err != nil {
+					return err
+				}
+			}
+			return walk(int(binary.BigEndian.Uint32(header[8:12])))
+		case sqlitePageLeafTable:
+			for i := 0; i < cellCount; i++ {
+				cell, err := cellOffset(i)
+				if err != nil {
+					return err
+				}
+				payload, err := db.leafPayload(page, cell)
+				if err != nil {
+					return fmt.Errorf("error reading SQLite cell of page %d: %w", number, err)
+				}
+				row, err := parseSQLiteRecord(payload)
+				if err != nil {
+					return fmt.Errorf("error parsing SQLite record of page %d: %w", number, err)
+				}
+				rows = append(rows, row)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected SQLite page type %#x of page %d", header[0], number)
+		}
+	}
+
+	if err := walk(rootPage); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// leafPayload returns the payload of a table leaf cell, following its overflow pages
+func (db *sqliteDatabase) leafPayload(page []byte, cell int) ([]byte, error) {
+	if cell >= len(page) {
+		return nil, fmt.Errorf("invalid cell offset %d", cell)
+	}
+	payloadSize, n := readSQLiteVarint(page[cell:])
+	cell += n
+	_, n = readSQLiteVarint(page[cell:]) // row id
+	cell += n
+
+	// a payload cannot be larger than the database holding it, this bounds the allocation of crafted databases
+	if payloadSize > uint64(len(db.data)) {
+		return nil, fmt.Errorf("payload of %d bytes exceeds the database", payloadSize)
+	}
+
+	// the payload is stored in the cell up to a maximum local size, the rest in a chain of overflow pages
+	size := int(payloadSize)
+	maxLocal := db.usableSize - 35
+	local := size
+	if size > maxLocal {
+		minLocal := (db.usableSize-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if cell+local > len(page) {
+		return nil, fmt.Errorf("payload of %d bytes exceeds the page", size)
+	}
+	payload := make([]byte, 0, size)
+	payload = append(payload, page[cell:cell+local]...)
+	if local == size {
+		return payload, nil
+	}
+
+	if cell+local+4 > len(page) {
+		return nil, fmt.Errorf("missing overflow page")
+	}
+	next := int(binary.BigEndian.Uint32(page[cell+local:]))
+	visited := make(map[int]bool)
+	for len(payload) < size {
+		if visited[next] {
+			return nil, fmt.Errorf("overflow page %d is referenced twice", next)
+		}
+		visited[next] = true
+		overflow, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := overflow[4:db.usableSize]
+		if remaining := size - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = int(binary.BigEndian.Uint32(overflow[:4]))
+	}
+	return payload, nil
+}
+
+// parseSQLiteRecord parses the values of a record: a header of the serial types of the values followed by the values
+func parseSQLiteRecord(record []byte) ([]any, error) {
+	headerSize, n := readSQLiteVarint(record)
+	// the size is checked before its conversion to int, as a crafted size would overflow it
+	if n == 0 || headerSize > uint64(len(record)) || headerSize < uint64(n) {
+		return nil, fmt.Errorf("invalid record header")
+	}
+	var serialTypes []int64
+	for offset := n; offset < int(headerSize); {
+		serialType, n := readSQLiteVarint(record[offset:int(headerSize)])
+		if n == 0 {
+			return nil, fmt.Errorf("invalid record header")
+		}
+		serialTypes = append(serialTypes, int64(serialType))
+		offset += n
+	}
+
+	values := make([]any, 0, len(serialTypes))
+	body := record[headerSize:]
+	for _, serialType := range serialTypes {
+		var size int
+		switch {
+		case serialType >= 1 && serialType <= 4:
+			size = int(serialType)
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		case serialType >= 12:
+			size = int((serialType - 12) / 2)
+		}
+		if size > len(body) {
+			return nil, fmt.Errorf("record value exceeds the record")
+		}
+		value := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType >= 1 && serialType <= 6:
+			// big-endian two's complement integers of 1, 2, 3, 4, 6 or 8 bytes
+			var v int64
+			if value[0]&0x80 != 0 {
+				v = -1
+			}
+			for _, b := range value {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case serialType == 8:
+			values = append(values, int64(0))
+		case serialType == 9:
+			values = append(values, int64(1))
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, value)
+		case serialType >= 13:
+			values = append(values, string(value))
+		default:
+			return nil, fmt.Errorf("unsupported serial type %d", serialType)
+		}
+	}
+	return values, nil
+}
+
+// readSQLiteVarint reads an SQLite variable length integer of 1 to 9 bytes, returning the value and its length, or
+// a length of 0 when the integer is truncated
+func readSQLiteVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
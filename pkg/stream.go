@@ -0,0 +1,440 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_1"
+	"github.com/spdx/tools-golang/spdx/v2/v2_2"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// PushSBOMArtifact pushes the SBOM to the destination like PushSBOMWithOptions. An SBOM loaded from a file by LoadSBOM
+// is pushed straight from the file instead of being held in memory, unless it is split into chunks.
+func PushSBOMArtifact(ctx context.Context, sbom *SBOM, reference string, dest oras.Target, opts PushOptions) (*PushResult, error) {
+	if sbom.Bytes != nil || opts.ChunkSize > 0 {
+		sbomBytes := sbom.Bytes
+		if sbomBytes == nil {
+			var err error
+			if sbomBytes, err = sbom.ReadBytes(); err != nil {
+				return nil, err
+			}
+		}
+		return PushSBOMWithOptions(ctx, sbom.Document, &sbom.Descriptor, sbomBytes, reference, dest, opts)
+	}
+
+	open := sbom.Open
+	layer := sbomLayerDescriptor(&sbom.Descriptor, opts)
+	if opts.Compression != "" {
+		open = func() (io.ReadCloser, error) {
+			reader, err := sbom.Open()
+			if err != nil {
+				return nil, err
+			}
+			compressed, err := CompressReader(reader, opts.Compression)
+			if err != nil {
+				reader.Close()
+				return nil, err
+			}
+			return &decompressReadCloser{Reader: compressed, closers: []io.Closer{compressed, reader}}, nil
+		}
+
+		compressedDesc, err := describeReader(open, CompressedMediaType(layer.MediaType, opts.Compression))
+		if err != nil {
+			return nil, err
+		}
+		compressedDesc.Annotations = compressedAnnotations(layer)
+		layer = compressedDesc
+	}
+
+	mem := memory.New()
+	src := &fileBackedStore{Store: mem, desc: layer, open: open}
+	return pushSBOMLayers(ctx, mem, src, sbom.Document, []v1.Descriptor{layer}, reference, dest, opts)
+}
+
+// decodeSBOMStream decodes the SPDX document from the reader while computing the descriptor of its bytes, copying
+// them to the writer as they are read. See decodeSBOM for the errors.
+func decodeSBOMStream(reader io.Reader, writer io.Writer, strict bool) (*SPDXDocument, *v1.Descriptor, error) {
+	digester := digest.Canonical.Digester()
+	counter := &countingWriter{}
+	teeReader := io.TeeReader(reader, io.MultiWriter(digester.Hash(), counter, writer))
+
+	doc, err := decodeSBOM(teeReader, strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// read the rest of the content, such as trailing whitespace, to complete the digest
+	if _, err := io.Copy(io.Discard, teeReader); err != nil {
+		return nil, nil, fmt.Errorf("error reading SBOM: %w", err)
+	}
+
+	return doc, &v1.Descriptor{
+		MediaType: MEDIATYPE_SPDX,
+		Digest:    digester.Digest(),
+		Size:      counter.n,
+	}, nil
+}
+
+// decodeSBOM decodes the SPDX JSON document token by token. The elements of the packages, files, relationships and
+// the other arrays of the document are decoded one at a time, so that the document is never buffered as a whole.
+// Documents of SPDX 2.1 and 2.2 are decoded as SPDX 2.3 documents, and the relationships of documentDescribes and
+// of the hasFiles fields of the packages are added as the SPDX JSON reader does.
+// The error wraps ErrNotSPDX when the document is not an SPDX JSON document, and ErrStrictParse when strict is set and
+// the document does not conform to the SPDX specification. Otherwise such a document falls back to its version, name
+// and namespace, see GetSBOMFromMap.
+func decodeSBOM(reader io.Reader, strict bool) (*SPDXDocument, error) {
+	decoder := &sbomDecoder{Decoder: json.NewDecoder(reader), header: map[string]interface{}{}}
+	doc, err := decoder.decode()
+	if err != nil {
+		return nil, fmt.Errorf("%w: error decoding SBOM: %w", ErrNotSPDX, err)
+	}
+
+	version, ok := decoder.header["spdxVersion"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: SBOM does not contain spdxVersion field", ErrNotSPDX)
+	}
+	switch version {
+	case v2_1.Version, v2_2.Version, v2_3.Version:
+		doc.SPDXVersion = v2_3.Version
+	default:
+		decoder.fail("spdxVersion", fmt.Errorf("unsupported SPDX version %s", version))
+	}
+
+	if decoder.err != nil {
+		if strict {
+			return nil, fmt.Errorf("%w: error parsing SPDX document: %w", ErrStrictParse, decoder.err)
+		}
+		slog.Warn("error parsing SPDX document, falling back to simple JSON parsing", "error", decoder.err)
+		doc, err = GetSBOMFromMap(decoder.header)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing SPDX document from map: %w", err)
+		}
+	}
+
+	return &SPDXDocument{Version: version, Document: doc}, nil
+}
+
+// sbomDecoder decodes an SPDX JSON document. Invalid JSON fails the decoding, while values that do not decode into
+// the SPDX types are recorded in err so that the rest of the document is still read.
+type sbomDecoder struct {
+	*json.Decoder
+	// header holds the spdxVersion, name and documentNamespace values to fall back on
+	header map[string]interface{}
+	// err is the first error decoding a value into the SPDX types
+	err error
+}
+
+func (d *sbomDecoder) decode() (*v2_3.Document, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	doc := &v2_3.Document{}
+	var describes []common.DocElementID
+	var contains []*v2_3.Relationship
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "spdxVersion", "name", "documentNamespace":
+			err = d.headerField(key, doc)
+		case "dataLicense":
+			err = d.field(key, &doc.DataLicense)
+		case "SPDXID":
+			err = d.field(key, &doc.SPDXIdentifier)
+		case "externalDocumentRefs":
+			err = d.field(key, &doc.ExternalDocumentReferences)
+		case "comment":
+			err = d.field(key, &doc.DocumentComment)
+		case "creationInfo":
+			err = d.field(key, &doc.CreationInfo)
+		case "documentDescribes":
+			err = d.field(key, &describes)
+		case "packages":
+			err = d.array(key, func(raw json.RawMessage) {
+				// decode the package within a document, which turns its hasFiles field into CONTAINS relationships
+				var packageDoc v2_3.Document
+				if string(raw) == "null" {
+					doc.Packages = append(doc.Packages, nil)
+					return
+				}
+				d.unmarshal(key, append(append([]byte(`{"packages":[`), raw...), ']', '}'), &packageDoc)
+				if len(packageDoc.Packages) != 1 {
+					packageDoc.Packages = []*v2_3.Package{nil}
+				}
+				doc.Packages = append(doc.Packages, packageDoc.Packages[0])
+				contains = append(contains, packageDoc.Relationships...)
+			})
+		case "files":
+			err = d.array(key, appendElement(d, key, &doc.Files))
+		case "hasExtractedLicensingInfos":
+			err = d.array(key, appendElement(d, key, &doc.OtherLicenses))
+		case "relationships":
+			err = d.array(key, appendElement(d, key, &doc.Relationships))
+		case "annotations":
+			err = d.array(key, appendElement(d, key, &doc.Annotations))
+		case "snippets":
+			err = d.array(key, appendElement(d, key, &doc.Snippets))
+		default:
+			err = skipJSONValue(d.Decoder)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("unexpected content after the JSON object")
+	}
+
+	addImplicitRelationships(doc, describes, contains)
+	return doc, nil
+}
+
+// headerField decodes the spdxVersion, name or documentNamespace field into the document and the header
+func (d *sbomDecoder) headerField(key string, doc *v2_3.Document) error {
+	var raw json.RawMessage
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+	d.header[key] = value
+
+	switch key {
+	case "name":
+		d.unmarshal(key, raw, &doc.DocumentName)
+	case "documentNamespace":
+		d.unmarshal(key, raw, &doc.DocumentNamespace)
+	}
+	return nil
+}
+
+// field decodes the value of the field into v
+func (d *sbomDecoder) field(key string, v any) error {
+	var raw json.RawMessage
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	d.unmarshal(key, raw, v)
+	return nil
+}
+
+// array decodes the array value of the field one element at a time, calling element with each of them
+func (d *sbomDecoder) array(key string, element func(raw json.RawMessage)) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	delim, ok := token.(json.Delim)
+	if !ok || delim != '[' {
+		d.fail(key, errors.New("expected an array"))
+		if !ok {
+			return nil
+		}
+		// skip the members of the object
+		for d.More() {
+			if _, err := d.Token(); err != nil {
+				return err
+			}
+			if err := skipJSONValue(d.Decoder); err != nil {
+				return err
+			}
+		}
+		_, err := d.Token()
+		return err
+	}
+
+	for d.More() {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return err
+		}
+		element(raw)
+	}
+	_, err = d.Token()
+	return err
+}
+
+func (d *sbomDecoder) unmarshal(key string, raw json.RawMessage, v any) {
+	if err := json.Unmarshal(raw, v); err != nil {
+		d.fail(key, err)
+	}
+}
+
+func (d *sbomDecoder) fail(key string, err error) {
+	if d.err == nil {
+		d.err = fmt.Errorf("error decoding SBOM field %s: %w", key, err)
+	}
+}
+
+// appendElement returns an element function of sbomDecoder.array appending the decoded elements to the slice
+func appendElement[T any](d *sbomDecoder, key string, elements *[]T) func(raw json.RawMessage) {
+	return func(raw json.RawMessage) {
+		var element T
+		d.unmarshal(key, raw, &element)
+		*elements = append(*elements, element)
+	}
+}
+
+// addImplicitRelationships removes the null relationships of the document and adds the DESCRIBES relationships of
+// documentDescribes and the CONTAINS relationships of the hasFiles fields of the packages, unless they already exist
+func addImplicitRelationships(doc *v2_3.Document, describes []common.DocElementID, contains []*v2_3.Relationship) {
+	relationships := doc.Relationships[:0]
+	for _, relationship := range doc.Relationships {
+		if relationship != nil {
+			relationships = append(relationships, relationship)
+		}
+	}
+	doc.Relationships = relationships
+
+	exists := map[string]bool{}
+	for _, relationship := range doc.Relationships {
+		exists[relationshipKey(relationship)] = true
+	}
+	add := func(relationship *v2_3.Relationship) {
+		if key := relationshipKey(relationship); !exists[key] {
+			doc.Relationships = append(doc.Relationships, relationship)
+			exists[key] = true
+		}
+	}
+
+	for _, id := range describes {
+		add(&v2_3.Relationship{
+			RefA:         common.DocElementID{ElementRefID: doc.SPDXIdentifier},
+			RefB:         id,
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+	for _, relationship := range contains {
+		add(relationship)
+	}
+}
+
+// relationshipKey identifies a relationship, CONTAINED_BY and DESCRIBED_BY relationships being identified as the
+// opposite CONTAINS and DESCRIBES relationships
+func relationshipKey(relationship *v2_3.Relationship) string {
+	refA, refB, kind := relationship.RefA, relationship.RefB, relationship.Relationship
+	switch kind {
+	case common.TypeRelationshipContainedBy:
+		refA, refB, kind = refB, refA, common.TypeRelationshipContains
+	case common.TypeRelationshipDescribeBy:
+		refA, refB, kind = refB, refA, common.TypeRelationshipDescribe
+	}
+	return fmt.Sprintf("%v-%v->%v", common.RenderDocElementID(refA), kind, common.RenderDocElementID(refB))
+}
+
+// skipJSONValue consumes the next JSON value from the decoder one token at a time
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// openSBOMFile opens the SBOM file, decompressing it if it is gzip or zstd compressed
+func openSBOMFile(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := DecompressReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// describeReader streams the content returned by open to compute its descriptor
+func describeReader(open func() (io.ReadCloser, error), mediaType string) (v1.Descriptor, error) {
+	reader, err := open()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	defer reader.Close()
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(digester.Hash(), reader)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("error reading content: %w", err)
+	}
+
+	return v1.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+	}, nil
+}
+
+// fileBackedStore is a memory store that serves the content of a single descriptor from disk
+type fileBackedStore struct {
+	*memory.Store
+	desc v1.Descriptor
+	open func() (io.ReadCloser, error)
+}
+
+func (s *fileBackedStore) Fetch(ctx context.Context, target v1.Descriptor) (io.ReadCloser, error) {
+	if target.Digest == s.desc.Digest {
+		return s.open()
+	}
+	return s.Store.Fetch(ctx, target)
+}
+
+func (s *fileBackedStore) Exists(ctx context.Context, target v1.Descriptor) (bool, error) {
+	if target.Digest == s.desc.Digest {
+		return true, nil
+	}
+	return s.Store.Exists(ctx, target)
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
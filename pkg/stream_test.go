@@ -0,0 +1,167 @@
+package obom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestLoadSBOM_Streamed(t *testing.T) {
+	filePath := "../examples/SPDXJSONExample-v2.3.spdx.json"
+
+	sbom, err := LoadSBOM(filePath, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOM, got: %v", err)
+	}
+	if sbom.Bytes != nil {
+		t.Errorf("expected the bytes of the file not to be held in memory")
+	}
+
+	sbomBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("error reading SBOM: %v", err)
+	}
+	readSBOM, err := ReadSBOM(strings.NewReader(string(sbomBytes)), true)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	if sbom.Descriptor.Digest != readSBOM.Descriptor.Digest || sbom.Descriptor.Size != readSBOM.Descriptor.Size {
+		t.Errorf("expected streamed descriptor to be %s (%d), got: %s (%d)", readSBOM.Descriptor.Digest, readSBOM.Descriptor.Size, sbom.Descriptor.Digest, sbom.Descriptor.Size)
+	}
+	if string(readSBOM.Bytes) != string(sbomBytes) {
+		t.Errorf("expected the bytes read by ReadSBOM to match the file")
+	}
+	if sbom.Descriptor.Annotations[ocispec.AnnotationTitle] != "SPDXJSONExample-v2.3.spdx.json" {
+		t.Errorf("expected title annotation to be set, got: %s", sbom.Descriptor.Annotations[ocispec.AnnotationTitle])
+	}
+
+	// the streamed decoding matches the SPDX JSON reader, including the relationships of documentDescribes and hasFiles
+	expected, err := spdxjson.Read(strings.NewReader(string(sbomBytes)))
+	if err != nil {
+		t.Fatalf("expected no error from spdxjson.Read, got: %v", err)
+	}
+	if !reflect.DeepEqual(sbom.Document, expected) {
+		t.Errorf("expected the streamed document to match the document of the SPDX JSON reader")
+	}
+
+	opened, err := sbom.ReadBytes()
+	if err != nil {
+		t.Fatalf("expected no error from ReadBytes, got: %v", err)
+	}
+	if string(opened) != string(sbomBytes) {
+		t.Errorf("expected the bytes read from the file to match the file")
+	}
+}
+
+func TestLoadSBOM_StreamedCompressed(t *testing.T) {
+	sbomBytes, err := os.ReadFile("../examples/SPDXJSONExample-v2.3.spdx.json")
+	if err != nil {
+		t.Fatalf("error reading SBOM: %v", err)
+	}
+	compressed, err := CompressBytes(sbomBytes, COMPRESSION_GZIP)
+	if err != nil {
+		t.Fatalf("expected no error from CompressBytes, got: %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "sbom.spdx.json.gz")
+	if err := os.WriteFile(filePath, compressed, 0644); err != nil {
+		t.Fatalf("error writing compressed SBOM: %v", err)
+	}
+
+	sbom, err := LoadSBOM(filePath, true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOM, got: %v", err)
+	}
+	if sbom.Descriptor.Digest.String() != "sha256:2de3741a7be1be5f5e54e837524f2ec627fedfb82307dc004ae03b195abc092f" {
+		t.Errorf("expected digest of the decompressed SBOM, got: %s", sbom.Descriptor.Digest)
+	}
+	if sbom.Descriptor.Annotations[ocispec.AnnotationTitle] != "sbom.spdx.json" {
+		t.Errorf("expected title annotation without the compression extension, got: %s", sbom.Descriptor.Annotations[ocispec.AnnotationTitle])
+	}
+}
+
+func TestReadSBOM_StreamedErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		document string
+		expected error
+	}{
+		{"missing version", `{"name": "SPDX-Example", "packages": [{"name": "a"}]}`, ErrNotSPDX},
+		{"truncated", `{"spdxVersion": "SPDX-2.3", "packages": [{"name": "a"}`, ErrNotSPDX},
+		{"trailing content", `{"spdxVersion": "SPDX-2.3"} {}`, ErrNotSPDX},
+		{"null package", `{"spdxVersion": "SPDX-2.3", "name": "a", "documentNamespace": "b", "packages": [null, {"SPDXID": "SPDXRef-a", "hasFiles": ["SPDXRef-f"]}]}`, nil},
+		{"invalid package", `{"spdxVersion": "SPDX-2.3", "name": "a", "documentNamespace": "b", "packages": [{"SPDXID": 5}]}`, ErrStrictParse},
+		{"invalid packages", `{"spdxVersion": "SPDX-2.3", "name": "a", "documentNamespace": "b", "packages": {"name": "a"}}`, ErrStrictParse},
+		{"unsupported version", `{"spdxVersion": "SPDX-3.0", "name": "a", "documentNamespace": "b"}`, ErrStrictParse},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadSBOM(strings.NewReader(tc.document), true)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v from ReadSBOM, got: %v", tc.expected, err)
+			}
+		})
+	}
+
+	// the document falls back to its header when not strict
+	sbom, err := ReadSBOM(strings.NewReader(`{"spdxVersion": "SPDX-2.3", "name": "a", "documentNamespace": "b", "packages": [{"SPDXID": 5}]}`), false)
+	if err != nil {
+		t.Fatalf("expected no error from ReadSBOM, got: %v", err)
+	}
+	if sbom.Document.DocumentName != "a" || sbom.Document.DocumentNamespace != "b" || len(sbom.Document.Packages) != 0 {
+		t.Errorf("expected the document header only, got: %+v", sbom.Document)
+	}
+}
+
+func TestPushSBOMArtifact_MatchesPushSBOMWithOptions(t *testing.T) {
+	ctx := context.Background()
+	filePath := "../examples/SPDXJSONExample-v2.3.spdx.json"
+
+	for _, opts := range []PushOptions{
+		{Reproducible: true, PushSummary: true},
+		{Reproducible: true, PushSummary: true, Compression: COMPRESSION_GZIP},
+		{Reproducible: true, PushSummary: true, Compression: COMPRESSION_ZSTD},
+		{Reproducible: true, PushSummary: true, ChunkSize: 1024},
+	} {
+		t.Run(fmt.Sprintf("compression=%s,chunk=%d", opts.Compression, opts.ChunkSize), func(t *testing.T) {
+			sbom, desc, sbomBytes, err := LoadSBOMFromFile(filePath, true)
+			if err != nil {
+				t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+			}
+			opts.Annotations, _ = GetAnnotations(sbom)
+			expected, err := PushSBOMWithOptions(ctx, sbom.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memory.New(), opts)
+			if err != nil {
+				t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+			}
+
+			loaded, err := LoadSBOM(filePath, true)
+			if err != nil {
+				t.Fatalf("expected no error from LoadSBOM, got: %v", err)
+			}
+			memDest := memory.New()
+			result, err := PushSBOMArtifact(ctx, loaded, "localhost:5000/spdx:v1", memDest, opts)
+			if err != nil {
+				t.Fatalf("expected no error from PushSBOMArtifact, got: %v", err)
+			}
+
+			if result.Descriptor.Digest != expected.Descriptor.Digest {
+				t.Errorf("expected streamed push to produce manifest %s, got: %s", expected.Descriptor.Digest, result.Descriptor.Digest)
+			}
+
+			fetched, err := FetchSBOMArtifact(ctx, memDest, "v1", true)
+			if err != nil {
+				t.Fatalf("expected no error fetching the SBOM, got: %v", err)
+			}
+			if string(fetched.Bytes) != string(sbomBytes) {
+				t.Errorf("expected fetched SBOM to match the file")
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package obom
+
+import (
+	"encoding/json"
+	"fmt"
+
+	purl "github.com/package-url/packageurl-go"
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+const (
+	MEDIATYPE_SBOM_SUMMARY = "application/vnd.obom.summary.v1+json"
+	// SBOM_SUMMARY_SCHEMA_VERSION is the version of the summary schema, increased with breaking changes along with the
+	// version of the summary media type
+	SBOM_SUMMARY_SCHEMA_VERSION = "1"
+	// noAssertion is the SPDX value of fields without a value
+	noAssertion = "NOASSERTION"
+)
+
+// SBOMSummary is the summary of an SPDX document pushed as a layer along with the SBOM, so that consumers can query
+// the packages, files and relationships without downloading the full SBOM
+type SBOMSummary struct {
+	// SchemaVersion is the version of the summary schema, see SBOM_SUMMARY_SCHEMA_VERSION
+	SchemaVersion string          `json:"schemaVersion"`
+	Document      DocumentSummary `json:"document"`
+	Counts        SummaryCounts   `json:"counts"`
+	// Licenses are the number of packages per license expression, see PackageSummary.License
+	Licenses      map[string]int        `json:"licenses"`
+	Packages      []PackageSummary      `json:"packages"`
+	Files         []FileSummary         `json:"files"`
+	Relationships []RelationshipSummary `json:"relationships"`
+}
+
+// DocumentSummary are the header fields of the SPDX document
+type DocumentSummary struct {
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace"`
+	SPDXVersion string   `json:"spdxVersion"`
+	DataLicense string   `json:"dataLicense,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Creators    []string `json:"creators,omitempty"`
+}
+
+// SummaryCounts are the totals of the SPDX document
+type SummaryCounts struct {
+	Packages      int `json:"packages"`
+	Files         int `json:"files"`
+	Relationships int `json:"relationships"`
+	Suppliers     int `json:"suppliers"`
+	Licenses      int `json:"licenses"`
+}
+
+// PackageSummary is the summary of an SPDX package
+type PackageSummary struct {
+	SPDXID  string `json:"spdxId"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// License is the declared license, falling back to the concluded license, or NOASSERTION when neither is set
+	License        string            `json:"license"`
+	PackageManager string            `json:"packageManager"`
+	Supplier       string            `json:"supplier,omitempty"`
+	PURL           string            `json:"purl,omitempty"`
+	CPEs           []string          `json:"cpes,omitempty"`
+	Checksums      map[string]string `json:"checksums,omitempty"`
+}
+
+// FileSummary is the summary of an SPDX file
+type FileSummary struct {
+	SPDXID    string            `json:"spdxId"`
+	Name      string            `json:"name"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
+// RelationshipSummary is an SPDX relationship between two elements
+type RelationshipSummary struct {
+	Element        string `json:"element"`
+	Type           string `json:"type"`
+	RelatedElement string `json:"relatedElement"`
+}
+
+// GetSBOMSummary returns the summary of the SPDX document
+func GetSBOMSummary(sbom *v2_3.Document) (*SBOMSummary, error) {
+	packages, err := GetPackageSummaries(sbom)
+	if err != nil {
+		return nil, err
+	}
+
+	sbomSummary := SBOMSummary{
+		SchemaVersion: SBOM_SUMMARY_SCHEMA_VERSION,
+		Document: DocumentSummary{
+			Name:        sbom.DocumentName,
+			Namespace:   sbom.DocumentNamespace,
+			SPDXVersion: sbom.SPDXVersion,
+			DataLicense: sbom.DataLicense,
+		},
+		Licenses:      make(map[string]int),
+		Packages:      packages,
+		Files:         make([]FileSummary, 0, len(sbom.Files)),
+		Relationships: make([]RelationshipSummary, 0, len(sbom.Relationships)),
+	}
+	if sbom.CreationInfo != nil {
+		sbomSummary.Document.Created = sbom.CreationInfo.Created
+		for _, creator := range sbom.CreationInfo.Creators {
+			sbomSummary.Document.Creators = append(sbomSummary.Document.Creators, fmt.Sprintf("%s: %s", creator.CreatorType, creator.Creator))
+		}
+	}
+
+	suppliers := make(map[string]bool)
+	for _, pkg := range packages {
+		sbomSummary.Licenses[pkg.License]++
+		if pkg.Supplier != "" {
+			suppliers[pkg.Supplier] = true
+		}
+	}
+
+	for _, file := range sbom.Files {
+		if file == nil {
+			continue
+		}
+		sbomSummary.Files = append(sbomSummary.Files, FileSummary{
+			SPDXID:    getElementID(file.FileSPDXIdentifier),
+			Name:      file.FileName,
+			Checksums: getChecksums(file.Checksums),
+		})
+	}
+
+	for _, relationship := range sbom.Relationships {
+		if relationship == nil {
+			continue
+		}
+		sbomSummary.Relationships = append(sbomSummary.Relationships, RelationshipSummary{
+			Element:        getDocElementID(relationship.RefA),
+			Type:           relationship.Relationship,
+			RelatedElement: getDocElementID(relationship.RefB),
+		})
+	}
+
+	sbomSummary.Counts = SummaryCounts{
+		Packages:      len(sbomSummary.Packages),
+		Files:         len(sbomSummary.Files),
+		Relationships: len(sbomSummary.Relationships),
+		Suppliers:     len(suppliers),
+		Licenses:      len(sbomSummary.Licenses),
+	}
+
+	return &sbomSummary, nil
+}
+
+// GetSBOMSummaryBytes returns the summary of the SPDX document marshaled into JSON
+func GetSBOMSummaryBytes(sbom *v2_3.Document) ([]byte, error) {
+	sbomSummary, err := GetSBOMSummary(sbom)
+	if err != nil {
+		return nil, fmt.Errorf("error getting SBOM summary: %w", err)
+	}
+	summaryBytes, err := json.Marshal(sbomSummary)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling summary into bytes: %w", err)
+	}
+	return summaryBytes, nil
+}
+
+func GetPackageSummary(pkg *v2_3.Package) (*PackageSummary, error) {
+	var packageSummary PackageSummary
+
+	packageSummary.SPDXID = getElementID(pkg.PackageSPDXIdentifier)
+	packageSummary.Name = pkg.PackageName
+	packageSummary.Version = pkg.PackageVersion
+	packageSummary.License = noAssertion
+	if isLicenseSet(pkg.PackageLicenseDeclared) {
+		packageSummary.License = pkg.PackageLicenseDeclared
+	} else if isLicenseSet(pkg.PackageLicenseConcluded) {
+		packageSummary.License = pkg.PackageLicenseConcluded
+	}
+	packageManager, _ := GetPackageManager(pkg.PackageExternalReferences)
+	if packageManager != "" {
+		packageSummary.PackageManager = packageManager
+	}
+	if pkg.PackageSupplier != nil && pkg.PackageSupplier.Supplier != noAssertion {
+		packageSummary.Supplier = pkg.PackageSupplier.Supplier
+	}
+	for _, exRef := range pkg.PackageExternalReferences {
+		if exRef == nil {
+			continue
+		}
+		switch exRef.RefType {
+		case v2common.TypePackageManagerPURL:
+			if packageSummary.PURL == "" {
+				packageSummary.PURL = exRef.Locator
+			}
+		case v2common.TypeSecurityCPE22Type, v2common.TypeSecurityCPE23Type:
+			packageSummary.CPEs = append(packageSummary.CPEs, exRef.Locator)
+		}
+	}
+	packageSummary.Checksums = getChecksums(pkg.PackageChecksums)
+
+	return &packageSummary, nil
+}
+
+func GetPackageManager(externalReferences []*v2_3.PackageExternalReference) (string, error) {
+	for _, exRef := range externalReferences {
+		if exRef.Category == v2common.CategoryPackageManager && exRef.RefType == v2common.TypePackageManagerPURL {
+			packageUrl, err := purl.FromString(exRef.Locator)
+			if err != nil {
+				return "", fmt.Errorf("error parsing package url for %s: %v", exRef.Locator, err)
+			}
+			return packageUrl.Type, nil
+		}
+	}
+
+	return "", fmt.Errorf("no package manager found")
+}
+
+func GetPackageSummaries(sbom *v2_3.Document) ([]PackageSummary, error) {
+	packageSummaries := make([]PackageSummary, 0, len(sbom.Packages))
+
+	for _, pkg := range sbom.Packages {
+		if pkg == nil {
+			continue
+		}
+		packageSummary, err := GetPackageSummary(pkg)
+		if err != nil {
+			return nil, err
+		}
+		packageSummaries = append(packageSummaries, *packageSummary)
+	}
+
+	return packageSummaries, nil
+}
+
+// getChecksums returns the checksum values by algorithm, or nil if there are none
+func getChecksums(checksums []v2common.Checksum) map[string]string {
+	if len(checksums) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(checksums))
+	for _, checksum := range checksums {
+		values[string(checksum.Algorithm)] = checksum.Value
+	}
+	return values
+}
+
+// getDocElementID returns the SPDX identifier of the element as written in the document, such as SPDXRef-Package or
+// DocumentRef-other:SPDXRef-File, or the special value such as NONE
+func getDocElementID(id v2common.DocElementID) string {
+	if id.ElementRefID == "" {
+		return id.SpecialID
+	}
+	if id.DocumentRefID != "" {
+		return "DocumentRef-" + id.DocumentRefID + ":" + getElementID(id.ElementRefID)
+	}
+	return getElementID(id.ElementRefID)
+}
+
+// getElementID returns the SPDX identifier of the element with its SPDXRef- prefix
+func getElementID(id v2common.ElementID) string {
+	return "SPDXRef-" + string(id)
+}
@@ -0,0 +1,95 @@
+package obom
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestGetSBOMSummary(t *testing.T) {
+	sbom, _, _, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+
+	summary, err := GetSBOMSummary(sbom.Document)
+	if err != nil {
+		t.Fatalf("expected no error from GetSBOMSummary, got: %v", err)
+	}
+
+	if summary.SchemaVersion != SBOM_SUMMARY_SCHEMA_VERSION {
+		t.Errorf("expected schema version to be %s, got: %s", SBOM_SUMMARY_SCHEMA_VERSION, summary.SchemaVersion)
+	}
+	if summary.Document.Name != "SPDX-Tools-v2.0" {
+		t.Errorf("expected document name to be 'SPDX-Tools-v2.0', got: %s", summary.Document.Name)
+	}
+	expectedCounts := SummaryCounts{Packages: 4, Files: 5, Relationships: 13, Suppliers: 1, Licenses: 3}
+	if summary.Counts != expectedCounts {
+		t.Errorf("expected counts to be %+v, got: %+v", expectedCounts, summary.Counts)
+	}
+	if summary.Licenses["NOASSERTION"] != 2 || summary.Licenses["MPL-1.0"] != 1 {
+		t.Errorf("unexpected license totals: %v", summary.Licenses)
+	}
+
+	glibc := summary.Packages[0]
+	if glibc.SPDXID != "SPDXRef-Package" || glibc.Supplier != "Jane Doe (jane.doe@example.com)" {
+		t.Errorf("unexpected package summary: %+v", glibc)
+	}
+	if glibc.Checksums["SHA256"] != "11b6d3ee554eedf79299905a98f9b9a04e498210b59f15094c916c91d150efcd" {
+		t.Errorf("expected the SHA256 checksum of the package, got: %v", glibc.Checksums)
+	}
+	if len(glibc.CPEs) != 1 || glibc.CPEs[0] != "cpe:2.3:a:pivotal_software:spring_framework:4.1.0:*:*:*:*:*:*:*" {
+		t.Errorf("expected the CPE of the package, got: %v", glibc.CPEs)
+	}
+
+	jena := summary.Packages[2]
+	if jena.PURL != "pkg:maven/org.apache.jena/apache-jena@3.12.0" || jena.PackageManager != "maven" {
+		t.Errorf("expected the maven purl of the package, got: %+v", jena)
+	}
+
+	foundDescribes := false
+	for _, relationship := range summary.Relationships {
+		if relationship.Element == "SPDXRef-DOCUMENT" && relationship.Type == "DESCRIBES" && relationship.RelatedElement == "SPDXRef-Package" {
+			foundDescribes = true
+		}
+	}
+	if !foundDescribes {
+		t.Errorf("expected the DESCRIBES relationship of the document, got: %v", summary.Relationships)
+	}
+}
+
+func TestPushSBOMWithOptions_SummaryLayer(t *testing.T) {
+	sbom, desc, sbomBytes, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+
+	ctx := context.Background()
+	memDest := memory.New()
+	_, err = PushSBOMWithOptions(ctx, sbom.Document, desc, sbomBytes, "localhost:5000/spdx:v1", memDest, PushOptions{PushSummary: true})
+	if err != nil {
+		t.Fatalf("expected no error from PushSBOMWithOptions, got: %v", err)
+	}
+
+	_, manifest, err := FetchManifest(ctx, memDest, "v1")
+	if err != nil {
+		t.Fatalf("expected no error from FetchManifest, got: %v", err)
+	}
+	if len(manifest.Layers) != 2 || manifest.Layers[1].MediaType != MEDIATYPE_SBOM_SUMMARY {
+		t.Fatalf("expected the summary layer with media type %s, got: %+v", MEDIATYPE_SBOM_SUMMARY, manifest.Layers)
+	}
+
+	summaryBytes, err := fetchLayerBytes(ctx, memDest, manifest.Layers[1])
+	if err != nil {
+		t.Fatalf("expected no error fetching the summary layer, got: %v", err)
+	}
+	var summary SBOMSummary
+	if err := json.Unmarshal(summaryBytes, &summary); err != nil {
+		t.Fatalf("expected no error unmarshaling the summary, got: %v", err)
+	}
+	if summary.SchemaVersion != SBOM_SUMMARY_SCHEMA_VERSION || summary.Counts.Packages != 4 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
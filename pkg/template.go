@@ -0,0 +1,203 @@
+package obom
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+	"gopkg.in/yaml.v3"
+)
+
+// AnnotationTemplateData is the data available to the annotation templates
+type AnnotationTemplateData struct {
+	// Document is the SPDX document
+	Document *v2_3.Document
+	// RootPackage is the package described by the SPDX document
+	RootPackage AnnotationPackage
+	// Env are the environment variables
+	Env map[string]string
+	// Git is the git information of the working directory
+	Git GitInfo
+}
+
+// AnnotationPackage are the fields of an SPDX package for the annotation templates, empty when not set
+type AnnotationPackage struct {
+	Name             string
+	Version          string
+	Supplier         string
+	Originator       string
+	License          string
+	DownloadLocation string
+	Homepage         string
+	PURL             string
+}
+
+// GitInfo is the git information of a working directory, empty when it is not in a git repository
+type GitInfo struct {
+	Commit    string
+	Branch    string
+	Tag       string
+	RemoteURL string
+}
+
+// annotationTemplateFuncs are the functions available to the annotation templates in addition to the builtins
+var annotationTemplateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// NewAnnotationTemplateData returns the template data for the SPDX document with the environment variables.
+// The git information is read from dir only when withGit is set, as it runs git.
+func NewAnnotationTemplateData(sbom *v2_3.Document, dir string, withGit bool) AnnotationTemplateData {
+	data := AnnotationTemplateData{
+		Document:    sbom,
+		RootPackage: getAnnotationPackage(GetRootPackage(sbom)),
+		Env:         make(map[string]string),
+	}
+	for _, env := range os.Environ() {
+		if key, value, found := strings.Cut(env, "="); found {
+			data.Env[key] = value
+		}
+	}
+	if withGit {
+		data.Git = GetGitInfo(dir)
+	}
+	return data
+}
+
+// LoadAnnotationFile loads the annotation templates from a JSON or YAML file mapping annotation keys to values
+func LoadAnnotationFile(filename string) (map[string]string, error) {
+	fileBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading annotation file: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML
+	var annotations map[string]string
+	if err := yaml.Unmarshal(fileBytes, &annotations); err != nil {
+		return nil, fmt.Errorf("error parsing annotation file %s: %w", filename, err)
+	}
+	return annotations, nil
+}
+
+// RenderAnnotations renders the annotation values as Go templates with the data. Annotations rendering to an empty
+// value are left out, so that templates of fields missing from the SBOM do not produce empty annotations.
+func RenderAnnotations(templates map[string]string, data AnnotationTemplateData) (map[string]string, error) {
+	// render in a stable order so that the first error is always the same
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	annotations := make(map[string]string)
+	for _, key := range keys {
+		tmpl, err := template.New(key).Funcs(annotationTemplateFuncs).Option("missingkey=zero").Parse(templates[key])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing annotation template %s: %w", key, err)
+		}
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, data); err != nil {
+			return nil, fmt.Errorf("error rendering annotation template %s: %w", key, err)
+		}
+		if value.Len() > 0 {
+			annotations[key] = value.String()
+		}
+	}
+	return annotations, nil
+}
+
+// UsesGitInfo reports whether any of the annotation templates uses the git information
+func UsesGitInfo(templates map[string]string) bool {
+	for _, text := range templates {
+		if strings.Contains(text, ".Git") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRootPackage returns the package the SPDX document describes, or the first package if there is no
+// DESCRIBES relationship, or nil if the document has no packages
+func GetRootPackage(sbom *v2_3.Document) *v2_3.Package {
+	if sbom == nil || len(sbom.Packages) == 0 {
+		return nil
+	}
+	for _, relationship := range sbom.Relationships {
+		if relationship == nil || relationship.RefA.ElementRefID != "DOCUMENT" || !strings.EqualFold(relationship.Relationship, "DESCRIBES") {
+			continue
+		}
+		for _, pkg := range sbom.Packages {
+			if pkg != nil && pkg.PackageSPDXIdentifier == relationship.RefB.ElementRefID {
+				return pkg
+			}
+		}
+	}
+	return sbom.Packages[0]
+}
+
+// getAnnotationPackage returns the template fields of the package
+func getAnnotationPackage(pkg *v2_3.Package) AnnotationPackage {
+	if pkg == nil {
+		return AnnotationPackage{}
+	}
+	annotationPackage := AnnotationPackage{
+		Name:             pkg.PackageName,
+		Version:          pkg.PackageVersion,
+		License:          pkg.PackageLicenseDeclared,
+		DownloadLocation: pkg.PackageDownloadLocation,
+		Homepage:         pkg.PackageHomePage,
+	}
+	if pkg.PackageSupplier != nil {
+		annotationPackage.Supplier = pkg.PackageSupplier.Supplier
+	}
+	if pkg.PackageOriginator != nil {
+		annotationPackage.Originator = pkg.PackageOriginator.Originator
+	}
+	for _, ref := range pkg.PackageExternalReferences {
+		if ref != nil && ref.RefType == "purl" {
+			annotationPackage.PURL = ref.Locator
+			break
+		}
+	}
+	return annotationPackage
+}
+
+// GetGitInfo returns the git information of the directory, leaving out what git cannot tell
+func GetGitInfo(dir string) GitInfo {
+	info := GitInfo{
+		Commit:    runGit(dir, "rev-parse", "HEAD"),
+		Branch:    runGit(dir, "rev-parse", "--abbrev-ref", "HEAD"),
+		Tag:       runGit(dir, "describe", "--tags", "--exact-match"),
+		RemoteURL: runGit(dir, "config", "--get", "remote.origin.url"),
+	}
+	// a detached HEAD has no branch
+	if info.Branch == "HEAD" {
+		info.Branch = ""
+	}
+	return info
+}
+
+// runGit runs git with the arguments in the directory and returns its trimmed output, or empty if it fails
+func runGit(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,108 @@
+package obom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderAnnotations(t *testing.T) {
+	sbom, _, _, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+
+	t.Setenv("OBOM_TEST_BUILD", "42")
+	data := NewAnnotationTemplateData(sbom.Document, ".", false)
+	data.Git = GitInfo{Commit: "0123456789abcdef"}
+
+	annotations, err := RenderAnnotations(map[string]string{
+		"org.opencontainers.image.title":    "{{ .RootPackage.Name }}",
+		"org.opencontainers.image.version":  "{{ .RootPackage.Version }}",
+		"org.opencontainers.image.vendor":   "{{ .RootPackage.Supplier }}",
+		"org.opencontainers.image.revision": "{{ .Git.Commit }}",
+		"org.example.build":                 "build-{{ .Env.OBOM_TEST_BUILD }}",
+		"org.example.document":              "{{ .Document.DocumentName | lower }}",
+		"org.example.branch":                "{{ .Git.Branch }}",
+		"org.example.literal":               "value",
+	}, data)
+	if err != nil {
+		t.Fatalf("expected no error from RenderAnnotations, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"org.opencontainers.image.title":    "glibc",
+		"org.opencontainers.image.version":  "2.11.1",
+		"org.opencontainers.image.vendor":   "Jane Doe (jane.doe@example.com)",
+		"org.opencontainers.image.revision": "0123456789abcdef",
+		"org.example.build":                 "build-42",
+		"org.example.document":              "spdx-tools-v2.0",
+		"org.example.literal":               "value",
+	}
+	for k, v := range expected {
+		if annotations[k] != v {
+			t.Errorf("expected annotation %s to be '%s', got: '%s'", k, v, annotations[k])
+		}
+	}
+	if _, ok := annotations["org.example.branch"]; ok {
+		t.Errorf("expected empty annotation to be left out")
+	}
+}
+
+func TestRenderAnnotations_InvalidTemplate(t *testing.T) {
+	_, err := RenderAnnotations(map[string]string{"key": "{{ .RootPackage.Name "}, AnnotationTemplateData{})
+	if err == nil {
+		t.Fatalf("expected error for an invalid template, got no error")
+	}
+
+	_, err = RenderAnnotations(map[string]string{"key": "{{ .Unknown }}"}, AnnotationTemplateData{})
+	if err == nil {
+		t.Fatalf("expected error for an unknown field, got no error")
+	}
+}
+
+func TestGetRootPackage_NoPackages(t *testing.T) {
+	sbom, _, _, err := LoadSBOMFromFile("../examples/SPDXJSONExample-v2.3.spdx.json", true)
+	if err != nil {
+		t.Fatalf("expected no error from LoadSBOMFromFile, got: %v", err)
+	}
+	sbom.Document.Packages = nil
+
+	if pkg := GetRootPackage(sbom.Document); pkg != nil {
+		t.Errorf("expected no root package, got: %s", pkg.PackageName)
+	}
+}
+
+func TestLoadAnnotationFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "annotations.yaml")
+	if err := os.WriteFile(yamlFile, []byte("org.opencontainers.image.version: \"{{ .RootPackage.Version }}\"\n"), 0644); err != nil {
+		t.Fatalf("error writing annotation file: %v", err)
+	}
+	jsonFile := filepath.Join(dir, "annotations.json")
+	if err := os.WriteFile(jsonFile, []byte(`{"org.opencontainers.image.version": "{{ .RootPackage.Version }}"}`), 0644); err != nil {
+		t.Fatalf("error writing annotation file: %v", err)
+	}
+
+	for _, file := range []string{yamlFile, jsonFile} {
+		annotations, err := LoadAnnotationFile(file)
+		if err != nil {
+			t.Fatalf("expected no error from LoadAnnotationFile, got: %v", err)
+		}
+		if annotations["org.opencontainers.image.version"] != "{{ .RootPackage.Version }}" {
+			t.Errorf("unexpected annotations from %s: %v", file, annotations)
+		}
+	}
+
+	if _, err := LoadAnnotationFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatalf("expected error for a missing annotation file, got no error")
+	}
+}
+
+func TestGetGitInfo_NotRepository(t *testing.T) {
+	info := GetGitInfo(t.TempDir())
+	if info.Commit != "" || info.Branch != "" {
+		t.Errorf("expected empty git info outside a repository, got: %+v", info)
+	}
+}
@@ -0,0 +1,285 @@
+package obom
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+// FileVerificationReport is the outcome of the verification of the files of an SPDX document against a file system
+type FileVerificationReport struct {
+	// Root is the verified directory or image
+	Root string `json:"root"`
+	// Matched is the number of files of the SPDX document found with matching checksums
+	Matched int `json:"matched"`
+	// Missing are the files of the SPDX document that are not found
+	Missing []string `json:"missing"`
+	// Extra are the files found that are not files of the SPDX document
+	Extra []string `json:"extra"`
+	// Mismatches are the SHA1 and SHA256 checksums of the SPDX document that do not match the files
+	Mismatches []ChecksumMismatch `json:"mismatches"`
+	// Packages are the recomputed verification codes of the packages with one
+	Packages []PackageVerification `json:"packages"`
+}
+
+// ChecksumMismatch is a checksum of a file of the SPDX document that does not match the file
+type ChecksumMismatch struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+}
+
+// PackageVerification is the verification code of a package recomputed from its files
+type PackageVerification struct {
+	SPDXID string `json:"spdxId"`
+	Name   string `json:"name"`
+	// Expected is the verification code of the SPDX document
+	Expected string `json:"expected"`
+	// Actual is the verification code of the files found, empty when files of the package are missing
+	Actual string `json:"actual,omitempty"`
+	// Files is the number of files of the package in the verification code
+	Files int `json:"files"`
+	// Missing are the files of the package that are not found
+	Missing  []string `json:"missing,omitempty"`
+	Verified bool     `json:"verified"`
+}
+
+// OK reports whether the files match the SPDX document, without missing or extra files, checksum mismatches or
+// unverified packages
+func (r *FileVerificationReport) OK() bool {
+	if len(r.Missing) > 0 || len(r.Extra) > 0 || len(r.Mismatches) > 0 {
+		return false
+	}
+	for _, pkg := range r.Packages {
+		if !pkg.Verified {
+			return false
+		}
+	}
+	return true
+}
+
+// fileChecksums are the SHA1 and SHA256 checksums of a file, as lowercase hexadecimal values
+type fileChecksums struct {
+	SHA1   string
+	SHA256 string
+}
+
+// VerifyFiles verifies the files of the SPDX document against the directory tree of root, such as a build output
+// or an unpacked image root file system. The file names of the SPDX document are relative to root. It reports the
+// missing and extra files, the SHA1 and SHA256 checksums that do not match, and recomputes the verification code of
+// the packages from their files.
+func VerifyFiles(sbom *v2_3.Document, root string) (*FileVerificationReport, error) {
+	files := make(map[string]fileChecksums)
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		checksums, err := computeFileChecksums(file)
+		if err != nil {
+			return fmt.Errorf("error computing checksums of %s: %w", filePath, err)
+		}
+		files[filepath.ToSlash(relPath)] = checksums
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking %s: %w", root, err)
+	}
+
+	report := compareFiles(sbom, files)
+	report.Root = root
+	return report, nil
+}
+
+// computeFileChecksums computes the checksums of the content of the reader
+func computeFileChecksums(reader io.Reader) (fileChecksums, error) {
+	sha1Hash := sha1.New()
+	sha256Hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(sha1Hash, sha256Hash), reader); err != nil {
+		return fileChecksums{}, err
+	}
+	return fileChecksums{
+		SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}, nil
+}
+
+// normalizeFileName returns an SPDX file name such as ./usr/bin/app relative to the root, as usr/bin/app
+func normalizeFileName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// compareFiles compares the files of the SPDX document with the files found, by their name relative to the root
+func compareFiles(sbom *v2_3.Document, files map[string]fileChecksums) *FileVerificationReport {
+	report := &FileVerificationReport{
+		Missing:    []string{},
+		Extra:      []string{},
+		Mismatches: []ChecksumMismatch{},
+		Packages:   []PackageVerification{},
+	}
+
+	documentFiles := make(map[string]bool)
+	for _, file := range getDocumentFiles(sbom) {
+		name := normalizeFileName(file.FileName)
+		if documentFiles[name] {
+			continue
+		}
+		documentFiles[name] = true
+
+		actual, found := files[name]
+		if !found {
+			report.Missing = append(report.Missing, file.FileName)
+			continue
+		}
+		matched := true
+		for _, checksum := range file.Checksums {
+			var value string
+			switch checksum.Algorithm {
+			case v2common.SHA1:
+				value = actual.SHA1
+			case v2common.SHA256:
+				value = actual.SHA256
+			default:
+				continue
+			}
+			if !strings.EqualFold(checksum.Value, value) {
+				matched = false
+				report.Mismatches = append(report.Mismatches, ChecksumMismatch{
+					File:      file.FileName,
+					Algorithm: string(checksum.Algorithm),
+					Expected:  checksum.Value,
+					Actual:    value,
+				})
+			}
+		}
+		if matched {
+			report.Matched++
+		}
+	}
+
+	for name := range files {
+		if !documentFiles[name] {
+			report.Extra = append(report.Extra, name)
+		}
+	}
+	sort.Strings(report.Extra)
+
+	for _, pkg := range sbom.Packages {
+		if pkg == nil || pkg.PackageVerificationCode == nil || pkg.PackageVerificationCode.Value == "" {
+			continue
+		}
+		report.Packages = append(report.Packages, verifyPackage(sbom, pkg, files))
+	}
+	return report
+}
+
+// getDocumentFiles returns the files of the SPDX document, along with the files nested in its packages
+func getDocumentFiles(sbom *v2_3.Document) []*v2_3.File {
+	files := make([]*v2_3.File, 0, len(sbom.Files))
+	for _, file := range sbom.Files {
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	for _, pkg := range sbom.Packages {
+		if pkg == nil {
+			continue
+		}
+		for _, file := range pkg.Files {
+			if file != nil {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// getPackageFiles returns the files of the package, nested in the package or related to it with a CONTAINS or a
+// CONTAINED_BY relationship
+func getPackageFiles(sbom *v2_3.Document, pkg *v2_3.Package) []*v2_3.File {
+	ids := make(map[v2common.ElementID]bool)
+	for _, relationship := range sbom.Relationships {
+		if relationship == nil || relationship.RefA.DocumentRefID != "" || relationship.RefB.DocumentRefID != "" {
+			continue
+		}
+		switch {
+		case relationship.Relationship == v2common.TypeRelationshipContains && relationship.RefA.ElementRefID == pkg.PackageSPDXIdentifier:
+			ids[relationship.RefB.ElementRefID] = true
+		case relationship.Relationship == v2common.TypeRelationshipContainedBy && relationship.RefB.ElementRefID == pkg.PackageSPDXIdentifier:
+			ids[relationship.RefA.ElementRefID] = true
+		}
+	}
+
+	files := append([]*v2_3.File{}, pkg.Files...)
+	for _, file := range sbom.Files {
+		if file != nil && ids[file.FileSPDXIdentifier] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// verifyPackage recomputes the verification code of the package from the SHA1 checksums of its files found, except
+// the excluded files. As per the SPDX specification, the verification code is the SHA1 of the concatenation of the
+// sorted SHA1 checksums of the files.
+func verifyPackage(sbom *v2_3.Document, pkg *v2_3.Package, files map[string]fileChecksums) PackageVerification {
+	verification := PackageVerification{
+		SPDXID:   getElementID(pkg.PackageSPDXIdentifier),
+		Name:     pkg.PackageName,
+		Expected: pkg.PackageVerificationCode.Value,
+	}
+
+	excluded := make(map[string]bool)
+	for _, excludedFile := range pkg.PackageVerificationCode.ExcludedFiles {
+		excluded[normalizeFileName(excludedFile)] = true
+	}
+
+	seen := make(map[string]bool)
+	var checksums []string
+	for _, file := range getPackageFiles(sbom, pkg) {
+		name := normalizeFileName(file.FileName)
+		if excluded[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		actual, found := files[name]
+		if !found {
+			verification.Missing = append(verification.Missing, file.FileName)
+			continue
+		}
+		checksums = append(checksums, actual.SHA1)
+	}
+	verification.Files = len(checksums)
+	if len(verification.Missing) > 0 || len(checksums) == 0 {
+		return verification
+	}
+
+	sort.Strings(checksums)
+	code := sha1.Sum([]byte(strings.Join(checksums, "")))
+	verification.Actual = hex.EncodeToString(code[:])
+	verification.Verified = strings.EqualFold(verification.Actual, verification.Expected)
+	return verification
+}
@@ -0,0 +1,111 @@
+package obom
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	v2common "github.com/spdx/tools-golang/spdx/v2/common"
+	"github.com/spdx/tools-golang/spdx/v2/v2_3"
+)
+
+func sha1Hex(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyFiles(t *testing.T) {
+	root := t.TempDir()
+	contents := map[string]string{
+		"bin/app":        "app",
+		"lib/libfoo.so":  "foo",
+		"etc/app.conf":   "changed",
+		"etc/extra.conf": "extra",
+	}
+	for name, content := range contents {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0755); err != nil {
+			t.Fatalf("error creating directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("error writing file: %v", err)
+		}
+	}
+
+	// the verification code is the SHA1 of the sorted SHA1 checksums of the files
+	checksums := []string{sha1Hex("app"), sha1Hex("foo")}
+	sort.Strings(checksums)
+	appCode := sha1Hex(strings.Join(checksums, ""))
+
+	sbom := &v2_3.Document{
+		Packages: []*v2_3.Package{
+			{
+				PackageSPDXIdentifier:   "Package-app",
+				PackageName:             "app",
+				PackageVerificationCode: &v2common.PackageVerificationCode{Value: appCode},
+			},
+			{
+				PackageSPDXIdentifier:   "Package-config",
+				PackageName:             "config",
+				PackageVerificationCode: &v2common.PackageVerificationCode{Value: sha1Hex(sha1Hex("original"))},
+			},
+		},
+		Files: []*v2_3.File{
+			{FileSPDXIdentifier: "File-app", FileName: "./bin/app", Checksums: []v2common.Checksum{
+				{Algorithm: v2common.SHA1, Value: sha1Hex("app")},
+				{Algorithm: v2common.SHA256, Value: sha256Hex("app")},
+			}},
+			{FileSPDXIdentifier: "File-foo", FileName: "./lib/libfoo.so", Checksums: []v2common.Checksum{
+				{Algorithm: v2common.SHA256, Value: sha256Hex("foo")},
+			}},
+			{FileSPDXIdentifier: "File-conf", FileName: "./etc/app.conf", Checksums: []v2common.Checksum{
+				{Algorithm: v2common.SHA256, Value: sha256Hex("original")},
+			}},
+			{FileSPDXIdentifier: "File-missing", FileName: "./usr/share/doc/README"},
+		},
+		Relationships: []*v2_3.Relationship{
+			{RefA: v2common.MakeDocElementID("", "Package-app"), RefB: v2common.MakeDocElementID("", "File-app"), Relationship: v2common.TypeRelationshipContains},
+			{RefA: v2common.MakeDocElementID("", "File-foo"), RefB: v2common.MakeDocElementID("", "Package-app"), Relationship: v2common.TypeRelationshipContainedBy},
+			{RefA: v2common.MakeDocElementID("", "Package-config"), RefB: v2common.MakeDocElementID("", "File-conf"), Relationship: v2common.TypeRelationshipContains},
+		},
+	}
+
+	report, err := VerifyFiles(sbom, root)
+	if err != nil {
+		t.Fatalf("expected no error from VerifyFiles, got: %v", err)
+	}
+	if report.OK() {
+		t.Errorf("expected the report to have discrepancies")
+	}
+	if report.Matched != 2 {
+		t.Errorf("expected 2 matched files, got: %d", report.Matched)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != "./usr/share/doc/README" {
+		t.Errorf("expected the missing README, got: %v", report.Missing)
+	}
+	if len(report.Extra) != 1 || report.Extra[0] != "etc/extra.conf" {
+		t.Errorf("expected the extra etc/extra.conf, got: %v", report.Extra)
+	}
+	if len(report.Mismatches) != 1 || report.Mismatches[0].File != "./etc/app.conf" || report.Mismatches[0].Actual != sha256Hex("changed") {
+		t.Errorf("expected the SHA256 mismatch of etc/app.conf, got: %v", report.Mismatches)
+	}
+
+	if len(report.Packages) != 2 {
+		t.Fatalf("expected 2 verified packages, got: %d", len(report.Packages))
+	}
+	if !report.Packages[0].Verified || report.Packages[0].Files != 2 {
+		t.Errorf("expected the verification code of app to match its 2 files, got: %+v", report.Packages[0])
+	}
+	if report.Packages[1].Verified || report.Packages[1].Actual != sha1Hex(sha1Hex("changed")) {
+		t.Errorf("expected the verification code of config to not match, got: %+v", report.Packages[1])
+	}
+}